@@ -0,0 +1,170 @@
+// Command shop-backfill names the shops created by the shops migration after the
+// shop snapshots stored with their products, and creates the shops that only
+// existed through their products. It reads the product database and writes the
+// user database, so it runs once per deployment rather than as a migration.
+//
+// Only shops still carrying the migration's placeholder name are renamed, so it
+// is safe to run again and never overwrites a name chosen by a seller.
+//
+//	go run ./cmd/backfill/shops -product-dsn 'root:secret@tcp(localhost:3306)/edot_product' -user-dsn 'root:secret@tcp(localhost:3306)/edot_user'
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	productModel "github.com/Christyan39/test-eDot/internal/models/product"
+	"github.com/Christyan39/test-eDot/pkg/database"
+)
+
+// maxShopNameLength matches shops.name
+const maxShopNameLength = 100
+
+func main() {
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	productDSN := flag.String("product-dsn", "", "DSN of the product service database")
+	userDSN := flag.String("user-dsn", "", "DSN of the user service database")
+	dryRun := flag.Bool("dry-run", false, "print the shop names without writing them")
+	flag.Parse()
+
+	if *productDSN == "" || *userDSN == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	productDB, err := database.MySQL(*productDSN)
+	if err != nil {
+		log.Fatalf("Failed to connect to product database: %v", err)
+	}
+	defer productDB.Close()
+
+	userDB, err := database.MySQL(*userDSN)
+	if err != nil {
+		log.Fatalf("Failed to connect to user database: %v", err)
+	}
+	defer userDB.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	names, err := snapshotShopNames(ctx, productDB)
+	if err != nil {
+		log.Fatalf("Failed to read shop snapshots: %v", err)
+	}
+
+	if *dryRun {
+		for shopID, name := range names {
+			fmt.Printf("%d\t%s\n", shopID, name)
+		}
+		log.Printf("[ShopBackfill] Found names for %d shops, nothing written (dry run)", len(names))
+		return
+	}
+
+	updated, err := backfillShopNames(ctx, userDB, names)
+	if err != nil {
+		log.Fatalf("Failed to backfill shops: %v", err)
+	}
+
+	log.Printf("[ShopBackfill] Found names for %d shops, created or renamed %d", len(names), updated)
+}
+
+// snapshotShopNames returns the shop name of the latest product snapshot of
+// every shop with products. Shops whose snapshots carry no name are left out.
+func snapshotShopNames(ctx context.Context, db *sql.DB) (map[int]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT shop_id, shop_metadata
+		FROM products
+		WHERE shop_metadata IS NOT NULL
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[int]string)
+	for rows.Next() {
+		var shopID int
+		var metadataJSON []byte
+		if err := rows.Scan(&shopID, &metadataJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+
+		var metadata productModel.ShopMetadata
+		if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
+			log.Printf("[ShopBackfill] Skipping unreadable shop metadata of shop %d: %v", shopID, err)
+			continue
+		}
+
+		// Later products overwrite earlier ones, so the latest snapshot wins
+		name := strings.TrimSpace(metadata.ShopName)
+		if name == "" {
+			continue
+		}
+		if runes := []rune(name); len(runes) > maxShopNameLength {
+			name = string(runes[:maxShopNameLength])
+		}
+		names[shopID] = name
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return names, nil
+}
+
+// backfillShopNames creates the missing shops and renames the ones still named
+// by the migration, returning how many rows changed
+func backfillShopNames(ctx context.Context, db *sql.DB, names map[int]string) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	query := `
+		INSERT INTO shops (id, name)
+		VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE name = IF(name = CONCAT('Shop ', id), VALUES(name), name)
+	`
+
+	var updated int64
+	for shopID, name := range names {
+		var result sql.Result
+		result, err = tx.ExecContext(ctx, query, shopID, name)
+		if err != nil {
+			return updated, fmt.Errorf("failed to backfill shop %d: %w", shopID, err)
+		}
+
+		var rowsAffected int64
+		rowsAffected, err = result.RowsAffected()
+		if err != nil {
+			return updated, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected > 0 {
+			updated++
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return updated, nil
+}
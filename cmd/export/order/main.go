@@ -0,0 +1,70 @@
+// Command order-export writes the order export used by finance for
+// reconciliation to a file, in the same format as GET /orders/export.
+//
+//	go run ./cmd/export/order -from 2024-01-01 -to 2024-01-31 -format csv -out orders.csv
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	orderRepositories "github.com/Christyan39/test-eDot/internal/repositories/order"
+	orderUsecases "github.com/Christyan39/test-eDot/internal/usecases/order"
+	"github.com/Christyan39/test-eDot/pkg/config"
+	"github.com/Christyan39/test-eDot/pkg/database"
+)
+
+func main() {
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	req := orderModel.OrderExportRequest{}
+	flag.StringVar(&req.From, "from", "", "export orders created on or after this date (YYYY-MM-DD)")
+	flag.StringVar(&req.To, "to", "", "export orders created on or before this date (YYYY-MM-DD)")
+	flag.StringVar(&req.Format, "format", orderModel.ExportFormatCSV, "export format, csv or ndjson")
+	out := flag.String("out", "", "file to write the export to (default: orders_<from>_<to>.<format>)")
+	flag.Parse()
+
+	if *out == "" {
+		*out = fmt.Sprintf("orders_%s_%s.%s", req.From, req.To, req.Format)
+	}
+
+	config.LoadEnvFile("order")
+
+	db, err := database.InitMySQL("order")
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Write to a temporary file so a failed export never leaves a partial file behind
+	file, err := os.Create(*out + ".tmp")
+	if err != nil {
+		log.Fatalf("Failed to create export file: %v", err)
+	}
+
+	orderRepo := orderRepositories.NewOrderRepository(db)
+	rows, err := orderUsecases.WriteOrderExport(ctx, orderRepo, &req, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		log.Fatalf("Failed to export orders: %v", err)
+	}
+
+	if err := os.Rename(file.Name(), *out); err != nil {
+		os.Remove(file.Name())
+		log.Fatalf("Failed to write export file: %v", err)
+	}
+
+	log.Printf("[OrderExport] Wrote %d rows to %s", rows, *out)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	orderUsecases "github.com/Christyan39/test-eDot/internal/usecases/order"
 	"github.com/Christyan39/test-eDot/pkg/config"
 	"github.com/Christyan39/test-eDot/pkg/database"
+	"github.com/Christyan39/test-eDot/pkg/money"
 	appnsq "github.com/Christyan39/test-eDot/pkg/nsq"
 	nsqio "github.com/nsqio/go-nsq"
 
@@ -54,11 +56,65 @@ func main() {
 	}
 	defer db.Close()
 
+	// Load the exchange rates used to price orders in the buyer's currency
+	rates := money.NewRateTable(money.DefaultCurrency())
+	if ratesFile := config.GetEnv("EXCHANGE_RATES_FILE", ""); ratesFile != "" {
+		if err := rates.LoadFile(ratesFile); err != nil {
+			log.Fatalf("Failed to load exchange rates: %v", err)
+		}
+		log.Printf("[STARTUP] Loaded exchange rates from %s", ratesFile)
+	} else {
+		log.Println("[STARTUP] EXCHANGE_RATES_FILE not set, orders can only be placed in the shop's currency until rates are loaded")
+	}
+
+	// Load the tax rules; without them orders are not taxed
+	taxRules := []orderUsecases.TaxRule{}
+	if taxRulesFile := config.GetEnv("TAX_RULES_FILE", ""); taxRulesFile != "" {
+		taxRules, err = orderUsecases.LoadTaxRulesFile(taxRulesFile)
+		if err != nil {
+			log.Fatalf("Failed to load tax rules: %v", err)
+		}
+		log.Printf("[STARTUP] Loaded %d tax rules from %s", len(taxRules), taxRulesFile)
+	} else {
+		log.Println("[STARTUP] TAX_RULES_FILE not set, orders are not taxed")
+	}
+	taxCalculator, err := orderUsecases.NewRuleTaxCalculator(taxRules)
+	if err != nil {
+		log.Fatalf("Failed to load tax rules: %v", err)
+	}
+
+	// Load the shipping zones; without them orders can only be placed without an address
+	shippingZones := []orderUsecases.ShippingZone{}
+	if shippingZonesFile := config.GetEnv("SHIPPING_ZONES_FILE", ""); shippingZonesFile != "" {
+		shippingZones, err = orderUsecases.LoadShippingZonesFile(shippingZonesFile)
+		if err != nil {
+			log.Fatalf("Failed to load shipping zones: %v", err)
+		}
+		log.Printf("[STARTUP] Loaded %d shipping zones from %s", len(shippingZones), shippingZonesFile)
+	} else {
+		log.Println("[STARTUP] SHIPPING_ZONES_FILE not set, orders with a shipping address will be rejected")
+	}
+	shippingCalculator, err := orderUsecases.NewZoneShippingCalculator(shippingZones)
+	if err != nil {
+		log.Fatalf("Failed to load shipping zones: %v", err)
+	}
+
 	// Initialize layers
 	orderRepo := orderRepositories.NewOrderRepository(db)
-	orderUsecase := orderUsecases.NewOrderUsecase(orderRepo)
+	orderUsecase := orderUsecases.NewOrderUsecase(orderRepo, rates, taxCalculator, shippingCalculator)
 	orderHandler := orderHandlers.NewOrderHandler(orderUsecase)
 
+	// Relay order events from the outbox to NSQ
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go orderUsecases.StartOutboxRelay(workerCtx, orderUsecase)
+
+	// Retry compensations of failed order creations so held stock is never leaked
+	go orderUsecases.StartCompensationWorker(workerCtx, orderUsecase)
+
+	// Expire overdue orders whose deferred NSQ message never arrived
+	go orderUsecases.StartExpirySweeper(workerCtx, orderUsecase)
+
 	// Initialize NSQ consumer for order events
 	nsqConfig := nsqio.NewConfig()
 	nsqdAddr := config.GetEnv("NSQD_TCP_HOST", "localhost:4150")
@@ -119,6 +175,33 @@ func main() {
 	// Order routes
 	orders := e.Group("/orders")
 	orders.POST("", orderHandler.CreateOrder, auth.JWTAuthMiddleware)
+	orders.GET("", orderHandler.ListOrders, auth.JWTAuthMiddleware)
+	orders.GET("/export", orderHandler.ExportOrders, auth.JWTAuthMiddleware, auth.AdminAuthMiddleware)
+	orders.POST("/checkout", orderHandler.Checkout, auth.JWTAuthMiddleware)
+	orders.GET("/:id", orderHandler.GetOrder, auth.JWTAuthMiddleware)
+	orders.GET("/:id/history", orderHandler.GetOrderHistory, auth.JWTAuthMiddleware)
+	orders.POST("/:id/pay", orderHandler.PayOrder, auth.JWTAuthMiddleware)
+	orders.POST("/:id/cancel", orderHandler.CancelOrder, auth.JWTAuthMiddleware)
+	orders.POST("/:id/returns", orderHandler.RequestReturn, auth.JWTAuthMiddleware)
+
+	// Shop order routes, for members of the shop
+	shops := e.Group("/shops/:shop_id/orders", auth.JWTAuthMiddleware)
+	shops.GET("", orderHandler.ListShopOrders)
+	shops.POST("/:id/accept", orderHandler.AcceptShopOrder)
+	shops.POST("/:id/reject", orderHandler.RejectShopOrder)
+	shops.POST("/:id/shipments", orderHandler.CreateShipment)
+	shops.POST("/:id/shipments/:shipment_id/deliver", orderHandler.DeliverShipment)
+	shops.POST("/:id/returns/:return_id/approve", orderHandler.ApproveReturn)
+	shops.POST("/:id/returns/:return_id/reject", orderHandler.RejectReturn)
+
+	// Exchange rate routes, updates are internal (X-API-Key)
+	exchangeRates := e.Group("/exchange-rates")
+	exchangeRates.GET("", orderHandler.GetExchangeRates, auth.JWTAuthMiddleware)
+	exchangeRates.PUT("", orderHandler.UpdateExchangeRates, auth.ServiceAuthMiddleware)
+
+	// Coupon routes, internal (X-API-Key)
+	coupons := e.Group("/coupons")
+	coupons.POST("", orderHandler.CreateCoupon, auth.ServiceAuthMiddleware)
 
 	log.Println("[STARTUP] Routes configured successfully")
 
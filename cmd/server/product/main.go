@@ -93,12 +93,26 @@ func main() {
 
 	// Product routes
 	products := e.Group("/products")
-	products.POST("", productHandler.CreateProduct)
+	products.POST("", productHandler.CreateProduct, auth.JWTAuthMiddleware)
 	products.GET("", productHandler.ListProducts)
+	products.GET("/:id", productHandler.GetProduct, auth.JWTAuthMiddleware)
+	products.PATCH("/:id", productHandler.UpdateProduct, auth.JWTAuthMiddleware)
+	products.DELETE("/:id", productHandler.DeleteProduct, auth.JWTAuthMiddleware)
+	products.POST("/:id/variants", productHandler.CreateVariant, auth.JWTAuthMiddleware)
+	products.PATCH("/:id/variants/:variant_id", productHandler.UpdateVariant, auth.JWTAuthMiddleware)
 
 	// Internal service endpoint with service authentication
 	products.PATCH("/hold-stock", productHandler.HoldStockInBulk, auth.ServiceAuthMiddleware)
+	products.PATCH("/hold-stock/batch", productHandler.HoldStockForOrders, auth.ServiceAuthMiddleware)
 	products.PATCH("/release-held-stock", productHandler.ReleaseHeldStock, auth.ServiceAuthMiddleware)
+	products.PATCH("/commit-held-stock", productHandler.CommitHeldStock, auth.ServiceAuthMiddleware)
+	products.PATCH("/restock", productHandler.Restock, auth.ServiceAuthMiddleware)
+
+	// Category taxonomy, maintained by admins
+	categories := e.Group("/categories")
+	categories.GET("", productHandler.ListCategories)
+	categories.POST("", productHandler.CreateCategory, auth.JWTAuthMiddleware, auth.AdminAuthMiddleware)
+	categories.PATCH("/:id", productHandler.UpdateCategory, auth.JWTAuthMiddleware, auth.AdminAuthMiddleware)
 
 	log.Println("[STARTUP] Routes configured successfully")
 
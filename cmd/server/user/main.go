@@ -9,6 +9,7 @@ import (
 	handlers "github.com/Christyan39/test-eDot/internal/handlers/user"
 	repositories "github.com/Christyan39/test-eDot/internal/repositories/user"
 	usecases "github.com/Christyan39/test-eDot/internal/usecases/user"
+	"github.com/Christyan39/test-eDot/pkg/auth"
 	"github.com/Christyan39/test-eDot/pkg/config"
 	"github.com/Christyan39/test-eDot/pkg/database"
 	"github.com/labstack/echo/v4"
@@ -86,14 +87,39 @@ func main() {
 	api := e.Group("/api/v1")
 
 	// Auth routes
-	auth := api.Group("/auth")
-	auth.POST("/login", userHandler.HandleDirectLogin)
-	auth.POST("/secure-login", userHandler.HandleEnvelopeLogin)
-	auth.POST("/create-envelope", userHandler.CreateEnvelope)
+	authRoutes := api.Group("/auth")
+	authRoutes.POST("/login", userHandler.HandleDirectLogin)
+	authRoutes.POST("/secure-login", userHandler.HandleEnvelopeLogin)
+	authRoutes.POST("/create-envelope", userHandler.CreateEnvelope)
 
 	// User routes
 	api.POST("/users", userHandler.CreateUser)
 
+	// Address book routes
+	addresses := api.Group("/users/me/addresses", auth.JWTAuthMiddleware)
+	addresses.GET("", userHandler.ListAddresses)
+	addresses.POST("", userHandler.CreateAddress)
+	addresses.GET("/:id", userHandler.GetAddress)
+	addresses.PUT("/:id", userHandler.UpdateAddress)
+	addresses.DELETE("/:id", userHandler.DeleteAddress)
+
+	// Shop routes
+	shops := api.Group("/shops")
+	shops.POST("", userHandler.CreateShop, auth.JWTAuthMiddleware)
+	shops.GET("/:shop_id", userHandler.GetShop)
+	shops.PUT("/:shop_id", userHandler.UpdateShop, auth.JWTAuthMiddleware)
+	shops.GET("/:shop_id/members", userHandler.ListShopMembers, auth.JWTAuthMiddleware)
+	shops.PUT("/:shop_id/members", userHandler.SetShopMember, auth.JWTAuthMiddleware)
+	shops.DELETE("/:shop_id/members/:user_id", userHandler.RemoveShopMember, auth.JWTAuthMiddleware)
+
+	// Internal routes for other services
+	internal := api.Group("/internal", auth.ServiceAuthMiddleware)
+	internal.GET("/users/:user_id/addresses/:id", userHandler.GetUserAddress)
+	internal.GET("/users/:user_id/shops", userHandler.ListUserShops)
+	internal.GET("/shops", userHandler.ListShops)
+	internal.PUT("/shops/:shop_id/members", userHandler.AddShopMember)
+	internal.GET("/shops/:shop_id/members/:user_id", userHandler.GetShopMember)
+
 	// Start server
 	port := config.GetEnv("PORT", "8080")
 	log.Printf("[STARTUP] ========================")
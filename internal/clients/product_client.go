@@ -34,7 +34,10 @@ type ProductServiceClientInterface interface {
 	GetProductByIDs(productIDs []int64) ([]productModels.Product, error)
 	UpdateProductStock(productID int64, req *productModels.UpdateProductRequest) error
 	HoldStockInBulk(ctx context.Context, req *productModels.HoldStockRequest) error
+	HoldStockForOrders(ctx context.Context, req *productModels.HoldStockBatchRequest) error
 	ReleaseHeldStockInBulk(ctx context.Context, req *productModels.ReleaseHeldStockRequest) error
+	CommitHeldStockInBulk(ctx context.Context, req *productModels.CommitHeldStockRequest) error
+	Restock(ctx context.Context, req *productModels.RestockRequest) error
 }
 
 // GetProductByID makes HTTP call to product service to get product details
@@ -142,6 +145,37 @@ func (p *ProductServiceClient) HoldStockInBulk(ctx context.Context, req *product
 	return nil
 }
 
+// HoldStockForOrders makes HTTP call to product service to hold stock for several orders atomically
+func (p *ProductServiceClient) HoldStockForOrders(ctx context.Context, req *productModels.HoldStockBatchRequest) error {
+	url := fmt.Sprintf("%s/products/hold-stock/batch", p.BaseURL)
+
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-API-Key", p.APIKey)
+
+	resp, err := p.HTTPClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("product service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
 func (p *ProductServiceClient) ReleaseHeldStockInBulk(ctx context.Context, req *productModels.ReleaseHeldStockRequest) error {
 	url := fmt.Sprintf("%s/products/release-held-stock", p.BaseURL)
 
@@ -171,3 +205,65 @@ func (p *ProductServiceClient) ReleaseHeldStockInBulk(ctx context.Context, req *
 
 	return nil
 }
+
+// CommitHeldStockInBulk makes HTTP call to product service to turn an order's held stock into a sale
+func (p *ProductServiceClient) CommitHeldStockInBulk(ctx context.Context, req *productModels.CommitHeldStockRequest) error {
+	url := fmt.Sprintf("%s/products/commit-held-stock", p.BaseURL)
+
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-API-Key", p.APIKey)
+
+	resp, err := p.HTTPClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("product service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// Restock makes HTTP call to product service to put stock back on sale
+func (p *ProductServiceClient) Restock(ctx context.Context, req *productModels.RestockRequest) error {
+	url := fmt.Sprintf("%s/products/restock", p.BaseURL)
+
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-API-Key", p.APIKey)
+
+	resp, err := p.HTTPClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("product service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
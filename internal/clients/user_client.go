@@ -0,0 +1,189 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	userModels "github.com/Christyan39/test-eDot/internal/models/user"
+)
+
+type UserServiceClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	APIKey     string
+}
+
+// NewUserServiceClient creates a new user service HTTP client
+func NewUserServiceClient(baseURL, apiKey string) UserServiceClientInterface {
+	return &UserServiceClient{
+		BaseURL: baseURL,
+		HTTPClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		APIKey: apiKey,
+	}
+}
+
+type UserServiceClientInterface interface {
+	GetUserAddress(ctx context.Context, userID int, addressID int64) (*userModels.Address, error)
+	GetShopMember(ctx context.Context, shopID, userID int) (*userModels.ShopMember, error)
+	ListUserShops(ctx context.Context, userID int) ([]userModels.ShopMember, error)
+	ListShops(ctx context.Context, shopIDs []int) ([]userModels.Shop, error)
+}
+
+// GetUserAddress makes HTTP call to user service to get one of a user's addresses
+func (u *UserServiceClient) GetUserAddress(ctx context.Context, userID int, addressID int64) (*userModels.Address, error) {
+	url := fmt.Sprintf("%s/api/v1/internal/users/%d/addresses/%d", u.BaseURL, userID, addressID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", u.APIKey)
+
+	resp, err := u.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("address %d not found", addressID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var address userModels.Address
+	if err := json.Unmarshal(body, &address); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal address: %w", err)
+	}
+
+	return &address, nil
+}
+
+// GetShopMember makes HTTP call to user service to get a user's membership of a shop
+func (u *UserServiceClient) GetShopMember(ctx context.Context, shopID, userID int) (*userModels.ShopMember, error) {
+	url := fmt.Sprintf("%s/api/v1/internal/shops/%d/members/%d", u.BaseURL, shopID, userID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", u.APIKey)
+
+	resp, err := u.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("user %d is not a member of shop %d", userID, shopID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var member userModels.ShopMember
+	if err := json.Unmarshal(body, &member); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal shop member: %w", err)
+	}
+
+	return &member, nil
+}
+
+// ListUserShops makes HTTP call to user service to get every shop membership of a user
+func (u *UserServiceClient) ListUserShops(ctx context.Context, userID int) ([]userModels.ShopMember, error) {
+	url := fmt.Sprintf("%s/api/v1/internal/users/%d/shops", u.BaseURL, userID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", u.APIKey)
+
+	resp, err := u.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var members []userModels.ShopMember
+	if err := json.Unmarshal(body, &members); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal shop members: %w", err)
+	}
+
+	return members, nil
+}
+
+// ListShops makes HTTP call to user service to get the shops with the given IDs.
+// Unknown shops are left out of the result.
+func (u *UserServiceClient) ListShops(ctx context.Context, shopIDs []int) ([]userModels.Shop, error) {
+	shopIDsStr := make([]string, len(shopIDs))
+	for i, id := range shopIDs {
+		shopIDsStr[i] = strconv.Itoa(id)
+	}
+	url := fmt.Sprintf("%s/api/v1/internal/shops?ids=%s", u.BaseURL, strings.Join(shopIDsStr, ","))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", u.APIKey)
+
+	resp, err := u.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var shops []userModels.Shop
+	if err := json.Unmarshal(body, &shops); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal shops: %w", err)
+	}
+
+	return shops, nil
+}
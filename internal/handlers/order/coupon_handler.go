@@ -0,0 +1,56 @@
+package order
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// CreateCoupon creates a new coupon (internal endpoint)
+// @Summary Create a coupon
+// @Description Create a percentage or fixed-amount coupon, optionally scoped to a shop or product, with min-spend, usage limits and a validity window
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Param coupon body orderModel.CreateCouponRequest true "Coupon data"
+// @Success 201 {object} orderModel.Coupon "Coupon created successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid input"
+// @Failure 401 {object} map[string]string "API key required"
+// @Failure 403 {object} map[string]string "Invalid API key"
+// @Failure 409 {object} map[string]string "Coupon code already exists"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /coupons [post]
+func (h *orderHandler) CreateCoupon(c echo.Context) error {
+	var req orderModel.CreateCouponRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[CreateCoupon] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	coupon, err := h.orderUsecase.CreateCoupon(c.Request().Context(), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be") {
+			log.Printf("[CreateCoupon] Validation error: %v", err)
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("[CreateCoupon] Usecase error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create coupon",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, coupon)
+}
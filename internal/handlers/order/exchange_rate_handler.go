@@ -0,0 +1,54 @@
+package order
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// GetExchangeRates returns the current exchange-rate table
+// @Summary Get exchange rates
+// @Description Get the exchange rates used to price orders in a currency other than the shop's
+// @Tags exchange-rates
+// @Produce json
+// @Success 200 {object} money.ExchangeRates "Current exchange rates"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /exchange-rates [get]
+// @Security BearerAuth
+func (h *orderHandler) GetExchangeRates(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.orderUsecase.GetExchangeRates(c.Request().Context()))
+}
+
+// UpdateExchangeRates replaces the exchange-rate table (internal endpoint)
+// @Summary Replace exchange rates
+// @Description Replace the exchange rates used for new orders. Rates are units of each currency per one unit of the base currency. Existing orders keep the rate they were placed with.
+// @Tags exchange-rates
+// @Accept json
+// @Produce json
+// @Param rates body money.ExchangeRates true "Exchange rates"
+// @Success 200 {object} money.ExchangeRates "Exchange rates updated"
+// @Failure 400 {object} map[string]string "Bad request - invalid rates"
+// @Failure 401 {object} map[string]string "API key required"
+// @Failure 403 {object} map[string]string "Invalid API key"
+// @Router /exchange-rates [put]
+func (h *orderHandler) UpdateExchangeRates(c echo.Context) error {
+	var req money.ExchangeRates
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[UpdateExchangeRates] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	if err := h.orderUsecase.UpdateExchangeRates(c.Request().Context(), &req); err != nil {
+		log.Printf("[UpdateExchangeRates] Validation error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, h.orderUsecase.GetExchangeRates(c.Request().Context()))
+}
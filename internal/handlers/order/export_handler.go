@@ -0,0 +1,92 @@
+package order
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// ExportOrders streams the items of the orders created in a date range (admin endpoint)
+// @Summary Export orders
+// @Description Stream one row per order item, joined with its order, for the orders created in the date range. Used by finance for reconciliation.
+// @Tags orders
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param from query string true "Created on or after this date (YYYY-MM-DD)"
+// @Param to query string true "Created on or before this date (YYYY-MM-DD)"
+// @Param format query string false "Export format (default: csv)" Enums(csv,ndjson)
+// @Success 200 {string} string "Order export"
+// @Failure 400 {object} map[string]string "Bad request - invalid date range or format"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Admin access required"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /orders/export [get]
+// @Security BearerAuth
+func (h *orderHandler) ExportOrders(c echo.Context) error {
+	var req orderModel.OrderExportRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[ExportOrders] Failed to bind parameters: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request parameters",
+		})
+	}
+
+	stream := &exportStream{c: c, req: &req}
+	rows, err := h.orderUsecase.ExportOrders(c.Request().Context(), &req, stream)
+	if err != nil {
+		if !stream.started {
+			if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be") {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": err.Error(),
+				})
+			}
+			log.Printf("[ExportOrders] Usecase error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to export orders",
+			})
+		}
+
+		// The status line is already sent, so abort the connection rather than
+		// let the client mistake a truncated export for a complete one
+		log.Printf("[ExportOrders] Export aborted after %d rows: %v", rows, err)
+		panic(http.ErrAbortHandler)
+	}
+
+	return nil
+}
+
+// exportStream sends the response headers on the first write and flushes every
+// write to the client, so errors found before any data can still be reported
+// as JSON
+type exportStream struct {
+	c       echo.Context
+	req     *orderModel.OrderExportRequest
+	started bool
+}
+
+func (s *exportStream) Write(p []byte) (int, error) {
+	if !s.started {
+		s.started = true
+
+		contentType := "text/csv; charset=utf-8"
+		if s.req.Format == orderModel.ExportFormatNDJSON {
+			contentType = "application/x-ndjson"
+		}
+		header := s.c.Response().Header()
+		header.Set(echo.HeaderContentType, contentType)
+		header.Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("orders_%s_%s.%s", s.req.From, s.req.To, s.req.Format)))
+		s.c.Response().WriteHeader(http.StatusOK)
+	}
+
+	n, err := s.c.Response().Write(p)
+	if err != nil {
+		return n, err
+	}
+	s.c.Response().Flush()
+	return n, nil
+}
@@ -0,0 +1,31 @@
+package order
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/labstack/echo/v4"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// validateOrderDataBody checks the order_data of a JSON request body against
+// its schema before the body is bound. Binding ignores unknown fields, so the
+// raw payload has to be validated first. The body is restored for Bind.
+func validateOrderDataBody(c echo.Context) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return err
+	}
+	c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+	var payload struct {
+		OrderData json.RawMessage `json:"order_data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil // malformed bodies are reported by Bind
+	}
+
+	return orderModel.ValidateOrderData(payload.OrderData)
+}
@@ -1,8 +1,13 @@
 package order
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -12,9 +17,32 @@ import (
 	"github.com/Christyan39/test-eDot/pkg/auth"
 )
 
+const (
+	headerIdempotencyKey     = "Idempotency-Key"
+	headerIdempotentReplayed = "Idempotent-Replayed"
+)
+
 // OrderHandler defines the order HTTP handler interface
 type OrderHandler interface {
 	CreateOrder(c echo.Context) error
+	GetOrder(c echo.Context) error
+	ListOrders(c echo.Context) error
+	GetOrderHistory(c echo.Context) error
+	PayOrder(c echo.Context) error
+	CancelOrder(c echo.Context) error
+	Checkout(c echo.Context) error
+	GetExchangeRates(c echo.Context) error
+	UpdateExchangeRates(c echo.Context) error
+	CreateCoupon(c echo.Context) error
+	CreateShipment(c echo.Context) error
+	DeliverShipment(c echo.Context) error
+	RequestReturn(c echo.Context) error
+	ApproveReturn(c echo.Context) error
+	RejectReturn(c echo.Context) error
+	ListShopOrders(c echo.Context) error
+	AcceptShopOrder(c echo.Context) error
+	RejectShopOrder(c echo.Context) error
+	ExportOrders(c echo.Context) error
 }
 
 // orderHandler implements OrderHandler
@@ -35,15 +63,23 @@ func NewOrderHandler(orderUsecase orderUsecase.OrderUsecase) OrderHandler {
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Unique key that makes retries of this request safe"
 // @Param order body orderModel.CreateOrderRequest true "Order creation data"
 // @Success 201 {object} orderModel.CreateOrderResponse "Order created successfully"
 // @Failure 400 {object} map[string]string "Bad request - invalid input or validation failed"
-// @Failure 404 {object} map[string]string "Product not found"
-// @Failure 409 {object} map[string]string "Insufficient stock"
+// @Failure 404 {object} map[string]string "Product or address not found"
+// @Failure 409 {object} map[string]string "Insufficient stock or request with the same Idempotency-Key in progress"
+// @Failure 422 {object} map[string]string "Idempotency-Key reused with a different request body"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /orders [post]
 // @Security BearerAuth
 func (h *orderHandler) CreateOrder(c echo.Context) error {
+	if err := validateOrderDataBody(c); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
 	// Bind request data
 	var req orderModel.CreateOrderRequest
 	if err := c.Bind(&req); err != nil {
@@ -72,10 +108,63 @@ func (h *orderHandler) CreateOrder(c echo.Context) error {
 	}
 
 	req.UserID = user.ID
-	err = h.orderUsecase.CreateOrder(c.Request().Context(), &req)
+
+	// Replay the stored response when the client retries with the same Idempotency-Key
+	idempotencyKey := strings.TrimSpace(c.Request().Header.Get(headerIdempotencyKey))
+	if idempotencyKey != "" {
+		if len(idempotencyKey) > 255 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Idempotency-Key must be at most 255 characters",
+			})
+		}
+
+		requestHash, err := hashRequest(&req)
+		if err != nil {
+			log.Printf("[CreateOrder] Failed to hash request: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to create order",
+			})
+		}
+
+		stored, reserved, err := h.orderUsecase.ReserveIdempotencyKey(c.Request().Context(), user.ID, idempotencyKey, requestHash)
+		if err != nil {
+			if strings.Contains(err.Error(), "different request body") {
+				return c.JSON(http.StatusUnprocessableEntity, map[string]string{
+					"error": err.Error(),
+				})
+			}
+			if strings.Contains(err.Error(), "still being processed") {
+				return c.JSON(http.StatusConflict, map[string]string{
+					"error": err.Error(),
+				})
+			}
+			log.Printf("[CreateOrder] Idempotency error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to create order",
+			})
+		}
+		if !reserved {
+			return h.replayCreateOrder(c, user.ID, idempotencyKey, stored)
+		}
+		req.IdempotencyKeyID = stored.ID
+	}
+
+	order, err := h.orderUsecase.CreateOrder(c.Request().Context(), &req)
 	if err != nil {
+		if idempotencyKey != "" {
+			// Free the key so the client can retry after fixing the request
+			if releaseErr := h.orderUsecase.ReleaseIdempotencyKey(c.Request().Context(), user.ID, req.IdempotencyKeyID); releaseErr != nil {
+				log.Printf("[CreateOrder] Failed to release idempotency key, retries are rejected until it expires: %v", releaseErr)
+			}
+		}
+
+		if strings.Contains(err.Error(), "still being processed") {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": err.Error(),
+			})
+		}
 		if strings.Contains(err.Error(), "not found") {
-			log.Printf("[CreateOrder] Product not found: %v", err)
+			log.Printf("[CreateOrder] Product or address not found: %v", err)
 			return c.JSON(http.StatusNotFound, map[string]string{
 				"error": err.Error(),
 			})
@@ -86,7 +175,7 @@ func (h *orderHandler) CreateOrder(c echo.Context) error {
 				"error": err.Error(),
 			})
 		}
-		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be") {
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be") || strings.Contains(err.Error(), "mismatch") {
 			log.Printf("[CreateOrder] Validation error: %v", err)
 			return c.JSON(http.StatusBadRequest, map[string]string{
 				"error": err.Error(),
@@ -98,7 +187,417 @@ func (h *orderHandler) CreateOrder(c echo.Context) error {
 		})
 	}
 
-	return c.JSON(http.StatusCreated, map[string]string{
-		"message": "Order created successfully",
+	return h.createdOrderResponse(c, user.ID, idempotencyKey, order, false)
+}
+
+// replayCreateOrder answers a retry of an order creation with the response
+// stored for its Idempotency-Key. When only the order was stored, the response
+// is rebuilt from it.
+func (h *orderHandler) replayCreateOrder(c echo.Context, userID int, idempotencyKey string, stored *orderModel.IdempotencyKey) error {
+	if stored.ResponseStatus != 0 {
+		log.Printf("[CreateOrder] Replaying response for order %d", stored.OrderID)
+		if stored.OrderID > 0 {
+			c.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/orders/%d", stored.OrderID))
+		}
+		c.Response().Header().Set(headerIdempotentReplayed, "true")
+		return c.JSONBlob(stored.ResponseStatus, stored.ResponseBody)
+	}
+
+	log.Printf("[CreateOrder] Rebuilding response for order %d", stored.OrderID)
+	order, err := h.orderUsecase.GetOrder(c.Request().Context(), userID, stored.OrderID)
+	if err != nil {
+		log.Printf("[CreateOrder] Failed to get order %d to replay: %v", stored.OrderID, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create order",
+		})
+	}
+
+	return h.createdOrderResponse(c, userID, idempotencyKey, order, true)
+}
+
+// createdOrderResponse writes the response of a created order and stores it
+// for retries with the same Idempotency-Key
+func (h *orderHandler) createdOrderResponse(c echo.Context, userID int, idempotencyKey string, order *orderModel.Order, replayed bool) error {
+	totalItems := 0
+	for _, item := range order.Items {
+		totalItems += item.Quantity
+	}
+
+	response, err := json.Marshal(orderModel.CreateOrderResponse{
+		Order:      *order,
+		TotalItems: totalItems,
+		Message:    "Order created successfully",
 	})
+	if err != nil {
+		log.Printf("[CreateOrder] Failed to marshal response: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create order",
+		})
+	}
+
+	if idempotencyKey != "" {
+		// The key is bound to the order already, so retries get the order even
+		// if the response cannot be stored
+		err = h.orderUsecase.CompleteIdempotencyKey(c.Request().Context(), userID, idempotencyKey, order.ID, http.StatusCreated, response)
+		if err != nil {
+			log.Printf("[CreateOrder] Failed to store response for order %d, retries rebuild it from the order: %v", order.ID, err)
+		}
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/orders/%d", order.ID))
+	if replayed {
+		c.Response().Header().Set(headerIdempotentReplayed, "true")
+	}
+	return c.JSONBlob(http.StatusCreated, response)
+}
+
+// hashRequest returns a hex-encoded SHA-256 digest of the request's JSON form
+func hashRequest(req interface{}) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// GetOrder retrieves a single order of the authenticated user
+// @Summary Get an order by ID
+// @Description Get an order of the authenticated user including its items, discounts and shipments
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} orderModel.Order "Successfully retrieved order"
+// @Failure 400 {object} map[string]string "Bad request - invalid order ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Order not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /orders/{id} [get]
+// @Security BearerAuth
+func (h *orderHandler) GetOrder(c echo.Context) error {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid order ID",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	order, err := h.orderUsecase.GetOrder(c.Request().Context(), user.ID, orderID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "order not found",
+			})
+		}
+		log.Printf("[GetOrder] Usecase error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get order",
+		})
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// ListOrders retrieves the authenticated user's orders with filtering and pagination
+// @Summary List orders with filters and pagination
+// @Description Get a paginated list of the authenticated user's orders with optional filtering by status, shop and date range
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)" minimum(1)
+// @Param limit query int false "Items per page (default: 10, max: 100)" minimum(1) maximum(100)
+// @Param status query string false "Filter by status" Enums(pending,confirmed,partially_shipped,shipped,delivered,cancelled,expired)
+// @Param shop_id query int false "Filter by shop ID" minimum(1)
+// @Param start_date query string false "Created on or after this date (YYYY-MM-DD)"
+// @Param end_date query string false "Created on or before this date (YYYY-MM-DD)"
+// @Success 200 {object} orderModel.OrderListResponse "Successfully retrieved orders"
+// @Failure 400 {object} map[string]string "Bad request - invalid parameters"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /orders [get]
+// @Security BearerAuth
+func (h *orderHandler) ListOrders(c echo.Context) error {
+	var req orderModel.OrderListRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[ListOrders] Failed to bind parameters: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request parameters",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	req.UserID = user.ID
+	response, err := h.orderUsecase.ListOrders(c.Request().Context(), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be") {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("[ListOrders] Usecase error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to list orders",
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetOrderHistory retrieves the status history of an order of the authenticated user
+// @Summary Get order status history
+// @Description Get every status change of an order, oldest first, including who made it
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} orderModel.OrderStatusHistory "Successfully retrieved order status history"
+// @Failure 400 {object} map[string]string "Bad request - invalid order ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Order not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /orders/{id}/history [get]
+// @Security BearerAuth
+func (h *orderHandler) GetOrderHistory(c echo.Context) error {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid order ID",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	history, err := h.orderUsecase.GetOrderHistory(c.Request().Context(), user.ID, orderID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "order not found",
+			})
+		}
+		log.Printf("[GetOrderHistory] Usecase error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get order history",
+		})
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
+
+// PayOrder confirms payment of a pending order of the authenticated user
+// @Summary Pay for an order
+// @Description Confirm payment of a pending order, moving it to confirmed and committing its held stock
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param payment body orderModel.PayOrderRequest false "Payment details"
+// @Success 200 {object} orderModel.Order "Order paid successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid order ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Order not found"
+// @Failure 409 {object} map[string]string "Order can no longer be paid"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /orders/{id}/pay [post]
+// @Security BearerAuth
+func (h *orderHandler) PayOrder(c echo.Context) error {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid order ID",
+		})
+	}
+
+	var req orderModel.PayOrderRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[PayOrder] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	order, err := h.orderUsecase.PayOrder(c.Request().Context(), user.ID, orderID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "order not found") {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "order not found",
+			})
+		}
+		if strings.Contains(err.Error(), "status transition") || strings.Contains(err.Error(), "expired") {
+			log.Printf("[PayOrder] Conflict: %v", err)
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("[PayOrder] Usecase error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to pay order",
+		})
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// CancelOrder cancels a pending order of the authenticated user
+// @Summary Cancel an order
+// @Description Cancel a pending order before it expires and release its held stock
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param cancellation body orderModel.CancelOrderRequest false "Cancellation details"
+// @Success 200 {object} orderModel.Order "Order cancelled successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid order ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Order not found"
+// @Failure 409 {object} map[string]string "Order is not pending"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /orders/{id}/cancel [post]
+// @Security BearerAuth
+func (h *orderHandler) CancelOrder(c echo.Context) error {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid order ID",
+		})
+	}
+
+	var req orderModel.CancelOrderRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[CancelOrder] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+	if len(req.Reason) > 255 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "reason must be at most 255 characters",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	order, err := h.orderUsecase.CancelOrder(c.Request().Context(), user.ID, orderID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "order not found") {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "order not found",
+			})
+		}
+		if strings.Contains(err.Error(), "only pending orders") {
+			log.Printf("[CancelOrder] Conflict: %v", err)
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("[CancelOrder] Usecase error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to cancel order",
+		})
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// Checkout creates one order per shop for a multi-shop cart
+// @Summary Checkout a multi-shop cart
+// @Description Split a cart with items from several shops into one order per shop under a shared checkout ID, holding stock for all of them atomically
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param checkout body orderModel.CheckoutRequest true "Cart items"
+// @Success 201 {object} orderModel.OrderGroup "Orders created successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid input, validation failed or a coupon code was given"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Product not found"
+// @Failure 409 {object} map[string]string "Insufficient stock"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /orders/checkout [post]
+// @Security BearerAuth
+func (h *orderHandler) Checkout(c echo.Context) error {
+	if err := validateOrderDataBody(c); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	var req orderModel.CheckoutRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Checkout] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	if len(req.Items) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "at least one item is required",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	req.UserID = user.ID
+	group, err := h.orderUsecase.Checkout(c.Request().Context(), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			log.Printf("[Checkout] Product not found: %v", err)
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "insufficient stock") {
+			log.Printf("[Checkout] Insufficient stock: %v", err)
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be") || strings.Contains(err.Error(), "mismatch") {
+			log.Printf("[Checkout] Validation error: %v", err)
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("[Checkout] Usecase error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to checkout",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, group)
 }
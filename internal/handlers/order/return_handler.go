@@ -0,0 +1,218 @@
+package order
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	"github.com/Christyan39/test-eDot/pkg/auth"
+)
+
+// RequestReturn returns items of the user's delivered order
+// @Summary Request a return
+// @Description Request to return some of the items of a delivered order. The seller approves or rejects the request.
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param return body orderModel.CreateReturnRequest true "Items to return and why"
+// @Success 201 {object} orderModel.OrderReturn "Return requested successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid input"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Order not found"
+// @Failure 409 {object} map[string]string "Order is not delivered"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /orders/{id}/returns [post]
+// @Security BearerAuth
+func (h *orderHandler) RequestReturn(c echo.Context) error {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid order ID",
+		})
+	}
+
+	var req orderModel.CreateReturnRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[RequestReturn] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+	if len(req.Reason) > 500 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "reason must be at most 500 characters",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	orderReturn, err := h.orderUsecase.RequestReturn(c.Request().Context(), user.ID, orderID, &req)
+	if err != nil {
+		return returnErrorResponse(c, "RequestReturn", err)
+	}
+
+	return c.JSON(http.StatusCreated, orderReturn)
+}
+
+// ApproveReturn accepts a requested return of an order placed with a shop the user belongs to
+// @Summary Approve a return
+// @Description Approve a requested return, for members of the shop the order was placed with. Its items are put back in stock and a pending refund is recorded for what the customer paid for them, shipping excluded.
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Param shop_id path int true "Shop ID"
+// @Param id path int true "Order ID"
+// @Param return_id path int true "Return ID"
+// @Param resolution body orderModel.ResolveReturnRequest false "Note for the customer"
+// @Success 200 {object} orderModel.OrderReturn "Return approved successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid shop, order or return ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 404 {object} map[string]string "Order or return not found"
+// @Failure 409 {object} map[string]string "Return already resolved"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops/{shop_id}/orders/{id}/returns/{return_id}/approve [post]
+// @Security BearerAuth
+func (h *orderHandler) ApproveReturn(c echo.Context) error {
+	shopID, orderID, returnID, req, ok := bindResolveReturn(c, "ApproveReturn")
+	if !ok {
+		return nil
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	orderReturn, err := h.orderUsecase.ApproveReturn(c.Request().Context(), user.ID, shopID, orderID, returnID, req)
+	if err != nil {
+		return returnErrorResponse(c, "ApproveReturn", err)
+	}
+
+	return c.JSON(http.StatusOK, orderReturn)
+}
+
+// RejectReturn declines a requested return of an order placed with a shop the user belongs to
+// @Summary Reject a return
+// @Description Reject a requested return, for members of the shop the order was placed with. Its items may be requested again.
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Param shop_id path int true "Shop ID"
+// @Param id path int true "Order ID"
+// @Param return_id path int true "Return ID"
+// @Param resolution body orderModel.ResolveReturnRequest false "Note for the customer"
+// @Success 200 {object} orderModel.OrderReturn "Return rejected successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid shop, order or return ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 404 {object} map[string]string "Order or return not found"
+// @Failure 409 {object} map[string]string "Return already resolved"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops/{shop_id}/orders/{id}/returns/{return_id}/reject [post]
+// @Security BearerAuth
+func (h *orderHandler) RejectReturn(c echo.Context) error {
+	shopID, orderID, returnID, req, ok := bindResolveReturn(c, "RejectReturn")
+	if !ok {
+		return nil
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	orderReturn, err := h.orderUsecase.RejectReturn(c.Request().Context(), user.ID, shopID, orderID, returnID, req)
+	if err != nil {
+		return returnErrorResponse(c, "RejectReturn", err)
+	}
+
+	return c.JSON(http.StatusOK, orderReturn)
+}
+
+// bindResolveReturn reads the shop, order and return IDs and the seller's note.
+// When it returns false the error response has already been written.
+func bindResolveReturn(c echo.Context, handler string) (int, int64, int64, *orderModel.ResolveReturnRequest, bool) {
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+		return 0, 0, 0, nil, false
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid order ID",
+		})
+		return 0, 0, 0, nil, false
+	}
+
+	returnID, err := strconv.ParseInt(c.Param("return_id"), 10, 64)
+	if err != nil || returnID <= 0 {
+		c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid return ID",
+		})
+		return 0, 0, 0, nil, false
+	}
+
+	var req orderModel.ResolveReturnRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[%s] Failed to bind request: %v", handler, err)
+		c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+		return 0, 0, 0, nil, false
+	}
+	if len(req.Note) > 500 {
+		c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "note must be at most 500 characters",
+		})
+		return 0, 0, 0, nil, false
+	}
+
+	return shopID, orderID, returnID, &req, true
+}
+
+// returnErrorResponse maps a return usecase error to its HTTP response
+func returnErrorResponse(c echo.Context, handler string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "forbidden"):
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "you are not a member of this shop",
+		})
+	case strings.Contains(err.Error(), "not found"):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	case strings.Contains(err.Error(), "cannot return") || strings.Contains(err.Error(), "is already"):
+		log.Printf("[%s] Conflict: %v", handler, err)
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be"):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("[%s] Usecase error: %v", handler, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "Failed to process return",
+	})
+}
@@ -0,0 +1,151 @@
+package order
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	"github.com/Christyan39/test-eDot/pkg/auth"
+)
+
+// CreateShipment ships items of a confirmed order placed with a shop the user belongs to
+// @Summary Create a shipment
+// @Description Ship some or all of the remaining items of a confirmed order, for members of the shop it was placed with. Without items, every item not shipped yet is included. The order becomes partially_shipped or shipped.
+// @Tags shipments
+// @Accept json
+// @Produce json
+// @Param shop_id path int true "Shop ID"
+// @Param id path int true "Order ID"
+// @Param shipment body orderModel.CreateShipmentRequest true "Shipment data"
+// @Success 201 {object} orderModel.Shipment "Shipment created successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid input"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 404 {object} map[string]string "Order not found"
+// @Failure 409 {object} map[string]string "Order cannot be shipped in its current status"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops/{shop_id}/orders/{id}/shipments [post]
+// @Security BearerAuth
+func (h *orderHandler) CreateShipment(c echo.Context) error {
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid order ID",
+		})
+	}
+
+	var req orderModel.CreateShipmentRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[CreateShipment] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	shipment, err := h.orderUsecase.CreateShipment(c.Request().Context(), user.ID, shopID, orderID, &req)
+	if err != nil {
+		return shipmentErrorResponse(c, "CreateShipment", err)
+	}
+
+	return c.JSON(http.StatusCreated, shipment)
+}
+
+// DeliverShipment marks a shipment of an order placed with a shop the user belongs to as delivered
+// @Summary Mark a shipment delivered
+// @Description Mark a shipment of an order as delivered, for members of the shop it was placed with. The order becomes delivered once all its items are shipped and every shipment has arrived.
+// @Tags shipments
+// @Produce json
+// @Param shop_id path int true "Shop ID"
+// @Param id path int true "Order ID"
+// @Param shipment_id path int true "Shipment ID"
+// @Success 200 {object} orderModel.Shipment "Shipment delivered successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid shop, order or shipment ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 404 {object} map[string]string "Order or shipment not found"
+// @Failure 409 {object} map[string]string "Shipment already delivered"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops/{shop_id}/orders/{id}/shipments/{shipment_id}/deliver [post]
+// @Security BearerAuth
+func (h *orderHandler) DeliverShipment(c echo.Context) error {
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid order ID",
+		})
+	}
+
+	shipmentID, err := strconv.ParseInt(c.Param("shipment_id"), 10, 64)
+	if err != nil || shipmentID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shipment ID",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	shipment, err := h.orderUsecase.DeliverShipment(c.Request().Context(), user.ID, shopID, orderID, shipmentID)
+	if err != nil {
+		return shipmentErrorResponse(c, "DeliverShipment", err)
+	}
+
+	return c.JSON(http.StatusOK, shipment)
+}
+
+// shipmentErrorResponse maps a shipment usecase error to its HTTP response
+func shipmentErrorResponse(c echo.Context, handler string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "forbidden"):
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "you are not a member of this shop",
+		})
+	case strings.Contains(err.Error(), "not found"):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	case strings.Contains(err.Error(), "cannot ship") || strings.Contains(err.Error(), "already delivered") || strings.Contains(err.Error(), "status transition"):
+		log.Printf("[%s] Conflict: %v", handler, err)
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be"):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("[%s] Usecase error: %v", handler, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "Failed to process shipment",
+	})
+}
@@ -0,0 +1,198 @@
+package order
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	"github.com/Christyan39/test-eDot/pkg/auth"
+)
+
+// ListShopOrders retrieves the orders placed with a shop the user belongs to
+// @Summary List a shop's orders
+// @Description Get a paginated list of the orders placed with a shop, for its members, with optional filtering by status and date range
+// @Tags shop orders
+// @Accept json
+// @Produce json
+// @Param shop_id path int true "Shop ID"
+// @Param page query int false "Page number (default: 1)" minimum(1)
+// @Param limit query int false "Items per page (default: 10, max: 100)" minimum(1) maximum(100)
+// @Param status query string false "Filter by status" Enums(pending,confirmed,partially_shipped,shipped,delivered,cancelled,expired)
+// @Param start_date query string false "Created on or after this date (YYYY-MM-DD)"
+// @Param end_date query string false "Created on or before this date (YYYY-MM-DD)"
+// @Success 200 {object} orderModel.OrderListResponse "Successfully retrieved orders"
+// @Failure 400 {object} map[string]string "Bad request - invalid parameters"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops/{shop_id}/orders [get]
+// @Security BearerAuth
+func (h *orderHandler) ListShopOrders(c echo.Context) error {
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+	}
+
+	var req orderModel.OrderListRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[ListShopOrders] Failed to bind parameters: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request parameters",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	response, err := h.orderUsecase.ListShopOrders(c.Request().Context(), user.ID, shopID, &req)
+	if err != nil {
+		return shopOrderErrorResponse(c, "ListShopOrders", err)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// AcceptShopOrder accepts an order placed with a shop the user belongs to
+// @Summary Accept a shop order
+// @Description Record that the shop will fulfill a pending or confirmed order
+// @Tags shop orders
+// @Produce json
+// @Param shop_id path int true "Shop ID"
+// @Param id path int true "Order ID"
+// @Success 200 {object} orderModel.Order "Order accepted successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid shop or order ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 404 {object} map[string]string "Order not found"
+// @Failure 409 {object} map[string]string "Order already accepted or cannot be accepted in its current status"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops/{shop_id}/orders/{id}/accept [post]
+// @Security BearerAuth
+func (h *orderHandler) AcceptShopOrder(c echo.Context) error {
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid order ID",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	order, err := h.orderUsecase.AcceptShopOrder(c.Request().Context(), user.ID, shopID, orderID)
+	if err != nil {
+		return shopOrderErrorResponse(c, "AcceptShopOrder", err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// RejectShopOrder rejects an order placed with a shop the user belongs to
+// @Summary Reject a shop order
+// @Description Cancel a pending or confirmed order on behalf of the shop. Held stock is released; paid orders are restocked and refunded in full.
+// @Tags shop orders
+// @Accept json
+// @Produce json
+// @Param shop_id path int true "Shop ID"
+// @Param id path int true "Order ID"
+// @Param rejection body orderModel.RejectOrderRequest false "Rejection reason"
+// @Success 200 {object} orderModel.Order "Order rejected successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid shop or order ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 404 {object} map[string]string "Order not found"
+// @Failure 409 {object} map[string]string "Order cannot be rejected in its current status"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops/{shop_id}/orders/{id}/reject [post]
+// @Security BearerAuth
+func (h *orderHandler) RejectShopOrder(c echo.Context) error {
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid order ID",
+		})
+	}
+
+	var req orderModel.RejectOrderRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[RejectShopOrder] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+	if len(req.Reason) > 255 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "reason must be at most 255 characters",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	order, err := h.orderUsecase.RejectShopOrder(c.Request().Context(), user.ID, shopID, orderID, &req)
+	if err != nil {
+		return shopOrderErrorResponse(c, "RejectShopOrder", err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// shopOrderErrorResponse maps a shop order usecase error to its HTTP response
+func shopOrderErrorResponse(c echo.Context, handler string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "forbidden"):
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "you are not a member of this shop",
+		})
+	case strings.Contains(err.Error(), "order not found"):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "order not found",
+		})
+	case strings.Contains(err.Error(), "cannot accept") || strings.Contains(err.Error(), "cannot reject") || strings.Contains(err.Error(), "already accepted"):
+		log.Printf("[%s] Conflict: %v", handler, err)
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be"):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("[%s] Usecase error: %v", handler, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "Failed to process shop order",
+	})
+}
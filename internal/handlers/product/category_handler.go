@@ -0,0 +1,120 @@
+package product
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	productModel "github.com/Christyan39/test-eDot/internal/models/product"
+)
+
+// ListCategories retrieves the category taxonomy
+// @Summary List categories
+// @Description Get the product category tree, each category with its subcategories
+// @Tags categories
+// @Produce json
+// @Success 200 {array} productModel.Category "Successfully retrieved categories"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /categories [get]
+func (h *productHandler) ListCategories(c echo.Context) error {
+	categories, err := h.productUsecase.ListCategories(c.Request().Context())
+	if err != nil {
+		return categoryErrorResponse(c, "ListCategories", err)
+	}
+
+	return c.JSON(http.StatusOK, categories)
+}
+
+// CreateCategory adds a category to the taxonomy
+// @Summary Create a category
+// @Description Add a category, at the root or under a parent category. Admin only.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category body productModel.CreateCategoryRequest true "Category data"
+// @Success 201 {object} productModel.Category "Category created successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid input"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Admin access required"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /categories [post]
+// @Security BearerAuth
+func (h *productHandler) CreateCategory(c echo.Context) error {
+	var req productModel.CreateCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[CreateCategory] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	category, err := h.productUsecase.CreateCategory(c.Request().Context(), &req)
+	if err != nil {
+		return categoryErrorResponse(c, "CreateCategory", err)
+	}
+
+	return c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory renames a category or moves it under another parent
+// @Summary Update a category
+// @Description Rename a category or move it, with its subcategories, under another parent. A parent_id of 0 moves it to the root. Admin only.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param category body productModel.UpdateCategoryRequest true "Fields to update"
+// @Success 200 {object} productModel.Category "Category updated successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid input"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Admin access required"
+// @Failure 404 {object} map[string]string "Category not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /categories/{id} [patch]
+// @Security BearerAuth
+func (h *productHandler) UpdateCategory(c echo.Context) error {
+	categoryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || categoryID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid category ID",
+		})
+	}
+
+	var req productModel.UpdateCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[UpdateCategory] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+	req.ID = categoryID
+
+	category, err := h.productUsecase.UpdateCategory(c.Request().Context(), &req)
+	if err != nil {
+		return categoryErrorResponse(c, "UpdateCategory", err)
+	}
+
+	return c.JSON(http.StatusOK, category)
+}
+
+// categoryErrorResponse maps a category usecase error to its HTTP response
+func categoryErrorResponse(c echo.Context, handler string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "category not found"):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "category not found",
+		})
+	case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "no fields provided"):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("[%s] Usecase error: %v", handler, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "Failed to process category",
+	})
+}
@@ -3,19 +3,32 @@ package product
 import (
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 
 	productModel "github.com/Christyan39/test-eDot/internal/models/product"
 	productUsecase "github.com/Christyan39/test-eDot/internal/usecases/product"
+	"github.com/Christyan39/test-eDot/pkg/auth"
 )
 
 // ProductHandler defines the product HTTP handler interface
 type ProductHandler interface {
 	CreateProduct(c echo.Context) error
 	ListProducts(c echo.Context) error
+	GetProduct(c echo.Context) error
+	UpdateProduct(c echo.Context) error
+	DeleteProduct(c echo.Context) error
+	CreateVariant(c echo.Context) error
+	UpdateVariant(c echo.Context) error
+	ListCategories(c echo.Context) error
+	CreateCategory(c echo.Context) error
+	UpdateCategory(c echo.Context) error
 	HoldStockInBulk(c echo.Context) error
+	HoldStockForOrders(c echo.Context) error
 	ReleaseHeldStock(c echo.Context) error
+	CommitHeldStock(c echo.Context) error
+	Restock(c echo.Context) error
 }
 
 // productHandler implements ProductHandler
@@ -32,13 +45,15 @@ func NewProductHandler(productUsecase productUsecase.ProductUsecase) ProductHand
 
 // CreateProduct creates a new product
 // @Summary Create a new product
-// @Description Create a new product in the marketplace with shop information
+// @Description Create a new product in a shop the user belongs to. The shop is taken from the user's membership; shop_id only picks one when the user belongs to several shops.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param product body productModel.CreateProductRequest true "Product creation data"
 // @Success 201 {object} map[string]string "Product created successfully"
 // @Failure 400 {object} map[string]string "Bad request - invalid input"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /products [post]
 // @Security BearerAuth
@@ -51,8 +66,26 @@ func (h *productHandler) CreateProduct(c echo.Context) error {
 		})
 	}
 
-	err := h.productUsecase.CreateProduct(c.Request().Context(), &req)
+	user, err := auth.GetUserFromContext(c.Request().Context())
 	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	err = h.productUsecase.CreateProduct(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "forbidden") {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "you are not a member of this shop",
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "required") {
+			log.Printf("[CreateProduct] Validation error: %v", err)
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
 		log.Printf("[CreateProduct] Usecase error: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to create product",
@@ -67,17 +100,20 @@ func (h *productHandler) CreateProduct(c echo.Context) error {
 
 // ListProducts retrieves products with filtering and pagination
 // @Summary List products with filters and pagination
-// @Description Get a paginated list of products with optional filtering by shop, price, status, and search
+// @Description Get a paginated list of products with optional filtering by shop, category, price, status, and search. With facets=true the response also counts the matching products per category, price range and shop; each facet ignores its own filter.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number (default: 1)" minimum(1)
 // @Param limit query int false "Items per page (default: 10, max: 100)" minimum(1) maximum(100)
 // @Param shop_id query int false "Filter by shop ID" minimum(1)
-// @Param min_price query number false "Minimum price filter" minimum(0)
-// @Param max_price query number false "Maximum price filter" minimum(0)
+// @Param currency query string false "Filter by ISO 4217 currency code, e.g. USD"
+// @Param min_price query number false "Minimum price filter, in the filtered currency" minimum(0)
+// @Param max_price query number false "Maximum price filter, in the filtered currency" minimum(0)
 // @Param status query string false "Filter by status" Enums(active,inactive,discontinued)
 // @Param search query string false "Search in product name and description" maxlength(100)
+// @Param category_id query int false "Filter by category, including its subcategories" minimum(1)
+// @Param facets query bool false "Include facet counts"
 // @Success 200 {object} productModel.ProductListResponse "Successfully retrieved products"
 // @Failure 400 {object} map[string]string "Bad request - invalid parameters"
 // @Failure 500 {object} map[string]string "Internal server error"
@@ -95,6 +131,12 @@ func (h *productHandler) ListProducts(c echo.Context) error {
 
 	response, err := h.productUsecase.ListProducts(c.Request().Context(), &req)
 	if err != nil {
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "cannot be greater") {
+			log.Printf("[ListProducts] Validation error: %v", err)
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
 		log.Printf("[ListProducts] Usecase error: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to list products",
@@ -140,6 +182,44 @@ func (h *productHandler) HoldStockInBulk(c echo.Context) error {
 	})
 }
 
+// HoldStockForOrders holds stock for several orders atomically
+// @Summary Hold stock for several orders atomically
+// @Description Hold stock for every order in the request within a single transaction, or for none of them
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param request body productModel.HoldStockBatchRequest true "Orders and the products to hold for each"
+// @Success 200 {object} map[string]string "Successfully held stock for all orders"
+// @Failure 400 {object} map[string]string "Bad request - invalid parameters"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /products/hold-stock/batch [patch]
+func (h *productHandler) HoldStockForOrders(c echo.Context) error {
+	var req productModel.HoldStockBatchRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[HoldStockForOrders] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	err := h.productUsecase.HoldStockForOrders(c.Request().Context(), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("[HoldStockForOrders] Usecase error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to hold stock for orders",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Stock held successfully for all orders",
+	})
+}
+
 func (h *productHandler) ReleaseHeldStock(c echo.Context) error {
 	var req productModel.ReleaseHeldStockRequest
 	if err := c.Bind(&req); err != nil {
@@ -161,3 +241,92 @@ func (h *productHandler) ReleaseHeldStock(c echo.Context) error {
 		"message": "Held stock released successfully",
 	})
 }
+
+// CommitHeldStock converts the stock held for an order into a sale
+// @Summary Commit held stock for a paid order
+// @Description Permanently remove the stock held for an order from on-hold stock and mark its hold audits as successful
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param request body productModel.CommitHeldStockRequest true "Order whose held stock should be committed"
+// @Success 200 {object} map[string]string "Held stock committed successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid parameters"
+// @Failure 409 {object} map[string]string "No held stock to commit"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /products/commit-held-stock [patch]
+func (h *productHandler) CommitHeldStock(c echo.Context) error {
+	var req productModel.CommitHeldStockRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[CommitHeldStock] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	err := h.productUsecase.CommitHeldStock(c.Request().Context(), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "no held stock") || strings.Contains(err.Error(), "exceeds") {
+			log.Printf("[CommitHeldStock] Conflict: %v", err)
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("[CommitHeldStock] Usecase error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to commit held stock",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Held stock committed successfully",
+	})
+}
+
+// Restock puts stock back on sale
+// @Summary Restock products
+// @Description Add stock back to products, e.g. for approved returns, and audit each increment. Retrying a reference that was already restocked has no effect.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param request body productModel.RestockRequest true "Products and quantities to restock"
+// @Success 200 {object} map[string]string "Products restocked successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid parameters"
+// @Failure 404 {object} map[string]string "Product not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /products/restock [patch]
+func (h *productHandler) Restock(c echo.Context) error {
+	var req productModel.RestockRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Restock] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	err := h.productUsecase.Restock(c.Request().Context(), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("[Restock] Usecase error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to restock products",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Products restocked successfully",
+	})
+}
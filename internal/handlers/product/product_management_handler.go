@@ -0,0 +1,165 @@
+package product
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	productModel "github.com/Christyan39/test-eDot/internal/models/product"
+	"github.com/Christyan39/test-eDot/pkg/auth"
+)
+
+// GetProduct retrieves a product of a shop the user belongs to
+// @Summary Get a product
+// @Description Get a single product, for members of the shop owning it
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} productModel.Product "Successfully retrieved product"
+// @Failure 400 {object} map[string]string "Bad request - invalid product ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 404 {object} map[string]string "Product not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /products/{id} [get]
+// @Security BearerAuth
+func (h *productHandler) GetProduct(c echo.Context) error {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || productID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid product ID",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	product, err := h.productUsecase.GetProduct(c.Request().Context(), user.ID, productID)
+	if err != nil {
+		return productErrorResponse(c, "GetProduct", err)
+	}
+
+	return c.JSON(http.StatusOK, product)
+}
+
+// UpdateProduct partially updates a product of a shop the user belongs to
+// @Summary Update a product
+// @Description Update the given fields of a product, for members of the shop owning it. On-hold stock is managed by orders and products cannot move to another shop. A price without a currency is in the product's current currency.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param product body productModel.UpdateProductRequest true "Fields to update"
+// @Success 200 {object} productModel.Product "Product updated successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid input"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 404 {object} map[string]string "Product not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /products/{id} [patch]
+// @Security BearerAuth
+func (h *productHandler) UpdateProduct(c echo.Context) error {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || productID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid product ID",
+		})
+	}
+
+	var req productModel.UpdateProductRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[UpdateProduct] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+	// The path is authoritative for which product is updated
+	req.ID = productID
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	product, err := h.productUsecase.UpdateProduct(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		return productErrorResponse(c, "UpdateProduct", err)
+	}
+
+	return c.JSON(http.StatusOK, product)
+}
+
+// DeleteProduct discontinues a product of a shop the user belongs to
+// @Summary Delete a product
+// @Description Soft-delete a product by setting its status to discontinued, for members of the shop owning it. The product stays readable for existing orders but can no longer be ordered.
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} map[string]string "Product deleted successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid product ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 404 {object} map[string]string "Product not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /products/{id} [delete]
+// @Security BearerAuth
+func (h *productHandler) DeleteProduct(c echo.Context) error {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || productID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid product ID",
+		})
+	}
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	err = h.productUsecase.DeleteProduct(c.Request().Context(), user.ID, productID)
+	if err != nil {
+		return productErrorResponse(c, "DeleteProduct", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Product deleted successfully",
+	})
+}
+
+// productErrorResponse maps a product management usecase error to its HTTP response
+func productErrorResponse(c echo.Context, handler string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "forbidden"):
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "you are not a member of this shop",
+		})
+	case strings.Contains(err.Error(), "product not found"):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "product not found",
+		})
+	case strings.Contains(err.Error(), "variant not found"):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "variant not found",
+		})
+	case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "no fields provided"):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("[%s] Usecase error: %v", handler, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "Failed to process product",
+	})
+}
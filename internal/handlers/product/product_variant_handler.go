@@ -0,0 +1,117 @@
+package product
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	productModel "github.com/Christyan39/test-eDot/internal/models/product"
+	"github.com/Christyan39/test-eDot/pkg/auth"
+)
+
+// CreateVariant adds a variant to a product of a shop the user belongs to
+// @Summary Add a product variant
+// @Description Add an orderable variant (SKU) with its own option values and stock to a product, for members of the shop owning it. Products with variants are ordered per variant.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variant body productModel.CreateVariantRequest true "Variant data"
+// @Success 201 {object} productModel.ProductVariant "Variant created successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid input"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 404 {object} map[string]string "Product not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /products/{id}/variants [post]
+// @Security BearerAuth
+func (h *productHandler) CreateVariant(c echo.Context) error {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || productID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid product ID",
+		})
+	}
+
+	var req productModel.CreateVariantRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[CreateVariant] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+	req.ProductID = productID
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	variant, err := h.productUsecase.CreateVariant(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		return productErrorResponse(c, "CreateVariant", err)
+	}
+
+	return c.JSON(http.StatusCreated, variant)
+}
+
+// UpdateVariant partially updates a variant of a product of a shop the user belongs to
+// @Summary Update a product variant
+// @Description Update the given fields of a variant, for members of the shop owning its product. On-hold stock is managed by orders.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variant_id path int true "Variant ID"
+// @Param variant body productModel.UpdateVariantRequest true "Fields to update"
+// @Success 200 {object} productModel.ProductVariant "Variant updated successfully"
+// @Failure 400 {object} map[string]string "Bad request - invalid input"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 404 {object} map[string]string "Product or variant not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /products/{id}/variants/{variant_id} [patch]
+// @Security BearerAuth
+func (h *productHandler) UpdateVariant(c echo.Context) error {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || productID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid product ID",
+		})
+	}
+
+	variantID, err := strconv.ParseInt(c.Param("variant_id"), 10, 64)
+	if err != nil || variantID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid variant ID",
+		})
+	}
+
+	var req productModel.UpdateVariantRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[UpdateVariant] Failed to bind request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+	req.ProductID = productID
+	req.ID = variantID
+
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	variant, err := h.productUsecase.UpdateVariant(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		return productErrorResponse(c, "UpdateVariant", err)
+	}
+
+	return c.JSON(http.StatusOK, variant)
+}
@@ -0,0 +1,248 @@
+package user
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	models "github.com/Christyan39/test-eDot/internal/models/user"
+	"github.com/Christyan39/test-eDot/pkg/auth"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateAddress handles POST /users/me/addresses
+// @Summary Add an address
+// @Description Add a shipping address to the authenticated user's address book. The first address becomes the default.
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param address body user.CreateAddressRequest true "Address data"
+// @Success 201 {object} user.Address "Address created successfully"
+// @Failure 400 {object} map[string]string "Invalid request body or validation error"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /users/me/addresses [post]
+func (h *UserHandler) CreateAddress(c echo.Context) error {
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req models.CreateAddressRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	address, err := h.userUsecase.CreateAddress(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		return addressErrorResponse(c, "CreateAddress", err)
+	}
+
+	return c.JSON(http.StatusCreated, address)
+}
+
+// ListAddresses handles GET /users/me/addresses
+// @Summary List addresses
+// @Description Get the authenticated user's address book, default address first
+// @Tags addresses
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} user.Address "Addresses retrieved successfully"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /users/me/addresses [get]
+func (h *UserHandler) ListAddresses(c echo.Context) error {
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	addresses, err := h.userUsecase.ListAddresses(c.Request().Context(), user.ID)
+	if err != nil {
+		return addressErrorResponse(c, "ListAddresses", err)
+	}
+
+	return c.JSON(http.StatusOK, addresses)
+}
+
+// GetAddress handles GET /users/me/addresses/:id
+// @Summary Get an address
+// @Description Get one address from the authenticated user's address book
+// @Tags addresses
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Address ID"
+// @Success 200 {object} user.Address "Address retrieved successfully"
+// @Failure 400 {object} map[string]string "Invalid address ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Address not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /users/me/addresses/{id} [get]
+func (h *UserHandler) GetAddress(c echo.Context) error {
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	addressID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || addressID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid address ID",
+		})
+	}
+
+	address, err := h.userUsecase.GetAddress(c.Request().Context(), user.ID, addressID)
+	if err != nil {
+		return addressErrorResponse(c, "GetAddress", err)
+	}
+
+	return c.JSON(http.StatusOK, address)
+}
+
+// UpdateAddress handles PUT /users/me/addresses/:id
+// @Summary Update an address
+// @Description Replace an address in the authenticated user's address book
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Address ID"
+// @Param address body user.UpdateAddressRequest true "Address data"
+// @Success 200 {object} user.Address "Address updated successfully"
+// @Failure 400 {object} map[string]string "Invalid request body or validation error"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Address not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /users/me/addresses/{id} [put]
+func (h *UserHandler) UpdateAddress(c echo.Context) error {
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	addressID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || addressID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid address ID",
+		})
+	}
+
+	var req models.UpdateAddressRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	address, err := h.userUsecase.UpdateAddress(c.Request().Context(), user.ID, addressID, &req)
+	if err != nil {
+		return addressErrorResponse(c, "UpdateAddress", err)
+	}
+
+	return c.JSON(http.StatusOK, address)
+}
+
+// DeleteAddress handles DELETE /users/me/addresses/:id
+// @Summary Delete an address
+// @Description Remove an address from the authenticated user's address book. Orders keep their own copy of the address.
+// @Tags addresses
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Address ID"
+// @Success 200 {object} map[string]interface{} "Address deleted successfully"
+// @Failure 400 {object} map[string]string "Invalid address ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Address not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /users/me/addresses/{id} [delete]
+func (h *UserHandler) DeleteAddress(c echo.Context) error {
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	addressID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || addressID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid address ID",
+		})
+	}
+
+	if err := h.userUsecase.DeleteAddress(c.Request().Context(), user.ID, addressID); err != nil {
+		return addressErrorResponse(c, "DeleteAddress", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Address deleted successfully",
+	})
+}
+
+// GetUserAddress handles GET /internal/users/:user_id/addresses/:id (internal endpoint)
+// @Summary Get a user's address
+// @Description Get one address of a user, for services that ship to it
+// @Tags addresses
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param id path int true "Address ID"
+// @Success 200 {object} user.Address "Address retrieved successfully"
+// @Failure 400 {object} map[string]string "Invalid user or address ID"
+// @Failure 401 {object} map[string]string "API key required"
+// @Failure 403 {object} map[string]string "Invalid API key"
+// @Failure 404 {object} map[string]string "Address not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/users/{user_id}/addresses/{id} [get]
+func (h *UserHandler) GetUserAddress(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil || userID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid user ID",
+		})
+	}
+
+	addressID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || addressID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid address ID",
+		})
+	}
+
+	address, err := h.userUsecase.GetAddress(c.Request().Context(), userID, addressID)
+	if err != nil {
+		return addressErrorResponse(c, "GetUserAddress", err)
+	}
+
+	return c.JSON(http.StatusOK, address)
+}
+
+// addressErrorResponse maps an address usecase error to its HTTP response
+func addressErrorResponse(c echo.Context, handler string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "address not found",
+		})
+	case strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "invalid"):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("[%s] Usecase error: %v", handler, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "Failed to process address",
+	})
+}
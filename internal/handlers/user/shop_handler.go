@@ -0,0 +1,309 @@
+package user
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	models "github.com/Christyan39/test-eDot/internal/models/user"
+	"github.com/Christyan39/test-eDot/pkg/auth"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateShop handles POST /shops
+// @Summary Open a shop
+// @Description Open a shop owned by the authenticated user
+// @Tags shops
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param shop body user.CreateShopRequest true "Shop data"
+// @Success 201 {object} user.Shop "Shop created successfully"
+// @Failure 400 {object} map[string]string "Invalid request body or validation error"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops [post]
+func (h *UserHandler) CreateShop(c echo.Context) error {
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req models.CreateShopRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	shop, err := h.userUsecase.CreateShop(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		return shopErrorResponse(c, "CreateShop", err)
+	}
+
+	return c.JSON(http.StatusCreated, shop)
+}
+
+// GetShop handles GET /shops/:shop_id
+// @Summary Get a shop
+// @Description Get a shop's public details
+// @Tags shops
+// @Produce json
+// @Param shop_id path int true "Shop ID"
+// @Success 200 {object} user.Shop "Shop retrieved successfully"
+// @Failure 400 {object} map[string]string "Invalid shop ID"
+// @Failure 404 {object} map[string]string "Shop not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops/{shop_id} [get]
+func (h *UserHandler) GetShop(c echo.Context) error {
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+	}
+
+	shop, err := h.userUsecase.GetShop(c.Request().Context(), shopID)
+	if err != nil {
+		return shopErrorResponse(c, "GetShop", err)
+	}
+
+	return c.JSON(http.StatusOK, shop)
+}
+
+// UpdateShop handles PUT /shops/:shop_id
+// @Summary Update a shop
+// @Description Replace a shop's details. Only owners of the shop may update it.
+// @Tags shops
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param shop_id path int true "Shop ID"
+// @Param shop body user.UpdateShopRequest true "Shop data"
+// @Success 200 {object} user.Shop "Shop updated successfully"
+// @Failure 400 {object} map[string]string "Invalid request body or validation error"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not an owner of the shop"
+// @Failure 404 {object} map[string]string "Shop not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops/{shop_id} [put]
+func (h *UserHandler) UpdateShop(c echo.Context) error {
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+	}
+
+	var req models.UpdateShopRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	shop, err := h.userUsecase.UpdateShop(c.Request().Context(), user.ID, shopID, &req)
+	if err != nil {
+		return shopErrorResponse(c, "UpdateShop", err)
+	}
+
+	return c.JSON(http.StatusOK, shop)
+}
+
+// ListShopMembers handles GET /shops/:shop_id/members
+// @Summary List a shop's members
+// @Description Get the members of a shop and their roles, owners first. Only members of the shop may list them.
+// @Tags shops
+// @Produce json
+// @Security BearerAuth
+// @Param shop_id path int true "Shop ID"
+// @Success 200 {array} user.ShopMember "Shop members retrieved successfully"
+// @Failure 400 {object} map[string]string "Invalid shop ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not a member of the shop"
+// @Failure 404 {object} map[string]string "Shop not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops/{shop_id}/members [get]
+func (h *UserHandler) ListShopMembers(c echo.Context) error {
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+	}
+
+	members, err := h.userUsecase.ListShopMembers(c.Request().Context(), user.ID, shopID)
+	if err != nil {
+		return shopErrorResponse(c, "ListShopMembers", err)
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+// SetShopMember handles PUT /shops/:shop_id/members
+// @Summary Set a shop member's role
+// @Description Give a user a role in a shop, replacing any role they had. Only owners of the shop may manage its members, and a shop always keeps at least one owner.
+// @Tags shops
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param shop_id path int true "Shop ID"
+// @Param member body user.AddShopMemberRequest true "User and role"
+// @Success 200 {object} user.ShopMember "Shop member saved successfully"
+// @Failure 400 {object} map[string]string "Invalid shop ID, user ID or role"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not an owner of the shop"
+// @Failure 404 {object} map[string]string "Shop or user not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops/{shop_id}/members [put]
+func (h *UserHandler) SetShopMember(c echo.Context) error {
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+	}
+
+	var req models.AddShopMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	member, err := h.userUsecase.SetShopMember(c.Request().Context(), user.ID, shopID, &req)
+	if err != nil {
+		return shopErrorResponse(c, "SetShopMember", err)
+	}
+
+	return c.JSON(http.StatusOK, member)
+}
+
+// RemoveShopMember handles DELETE /shops/:shop_id/members/:user_id
+// @Summary Remove a shop member
+// @Description Remove a user's access to a shop. Only owners of the shop may manage its members, and a shop always keeps at least one owner.
+// @Tags shops
+// @Produce json
+// @Security BearerAuth
+// @Param shop_id path int true "Shop ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} map[string]string "Shop member removed successfully"
+// @Failure 400 {object} map[string]string "Invalid shop or user ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "User is not an owner of the shop"
+// @Failure 404 {object} map[string]string "Shop or member not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shops/{shop_id}/members/{user_id} [delete]
+func (h *UserHandler) RemoveShopMember(c echo.Context) error {
+	user, err := auth.GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+	}
+
+	memberID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil || memberID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid user ID",
+		})
+	}
+
+	if err := h.userUsecase.RemoveShopMember(c.Request().Context(), user.ID, shopID, memberID); err != nil {
+		return shopErrorResponse(c, "RemoveShopMember", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Shop member removed successfully",
+	})
+}
+
+// ListShops handles GET /internal/shops
+// @Summary List shops by ID
+// @Description Get the shops with the given IDs, for services that show shop details. Unknown IDs are skipped.
+// @Tags shops
+// @Produce json
+// @Param ids query string true "Comma-separated shop IDs, at most 100"
+// @Success 200 {array} user.Shop "Shops retrieved successfully"
+// @Failure 400 {object} map[string]string "Invalid shop IDs"
+// @Failure 401 {object} map[string]string "API key required"
+// @Failure 403 {object} map[string]string "Invalid API key"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/shops [get]
+func (h *UserHandler) ListShops(c echo.Context) error {
+	shopIDs := []int{}
+	for _, value := range strings.Split(c.QueryParam("ids"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid shop ID " + value,
+			})
+		}
+		shopIDs = append(shopIDs, id)
+	}
+
+	shops, err := h.userUsecase.ListShops(c.Request().Context(), shopIDs)
+	if err != nil {
+		return shopErrorResponse(c, "ListShops", err)
+	}
+
+	return c.JSON(http.StatusOK, shops)
+}
+
+// shopErrorResponse maps a shop usecase error to its HTTP response
+func shopErrorResponse(c echo.Context, handler string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "forbidden"):
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": err.Error(),
+		})
+	case strings.Contains(err.Error(), "not found"):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	case strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "invalid"):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("[%s] Usecase error: %v", handler, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "Failed to process shop",
+	})
+}
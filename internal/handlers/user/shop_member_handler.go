@@ -0,0 +1,133 @@
+package user
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	models "github.com/Christyan39/test-eDot/internal/models/user"
+	"github.com/labstack/echo/v4"
+)
+
+// AddShopMember handles PUT /internal/shops/:shop_id/members
+// @Summary Add a shop member
+// @Description Give a user a role in a shop, replacing any role they had. Members may manage the shop's orders.
+// @Tags shops
+// @Accept json
+// @Produce json
+// @Param shop_id path int true "Shop ID"
+// @Param member body user.AddShopMemberRequest true "User and role"
+// @Success 200 {object} user.ShopMember "Shop member saved successfully"
+// @Failure 400 {object} map[string]string "Invalid shop ID, user ID or role"
+// @Failure 401 {object} map[string]string "API key required"
+// @Failure 403 {object} map[string]string "Invalid API key"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/shops/{shop_id}/members [put]
+func (h *UserHandler) AddShopMember(c echo.Context) error {
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+	}
+
+	var req models.AddShopMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	member, err := h.userUsecase.AddShopMember(c.Request().Context(), shopID, &req)
+	if err != nil {
+		return shopMemberErrorResponse(c, "AddShopMember", err)
+	}
+
+	return c.JSON(http.StatusOK, member)
+}
+
+// GetShopMember handles GET /internal/shops/:shop_id/members/:user_id
+// @Summary Get a shop member
+// @Description Get a user's membership of a shop, for services that authorize shop staff
+// @Tags shops
+// @Produce json
+// @Param shop_id path int true "Shop ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} user.ShopMember "Shop member retrieved successfully"
+// @Failure 400 {object} map[string]string "Invalid shop or user ID"
+// @Failure 401 {object} map[string]string "API key required"
+// @Failure 403 {object} map[string]string "Invalid API key"
+// @Failure 404 {object} map[string]string "User is not a member of the shop"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/shops/{shop_id}/members/{user_id} [get]
+func (h *UserHandler) GetShopMember(c echo.Context) error {
+	shopID, err := strconv.Atoi(c.Param("shop_id"))
+	if err != nil || shopID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid shop ID",
+		})
+	}
+
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil || userID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid user ID",
+		})
+	}
+
+	member, err := h.userUsecase.GetShopMember(c.Request().Context(), shopID, userID)
+	if err != nil {
+		return shopMemberErrorResponse(c, "GetShopMember", err)
+	}
+
+	return c.JSON(http.StatusOK, member)
+}
+
+// ListUserShops handles GET /internal/users/:user_id/shops
+// @Summary List a user's shops
+// @Description Get every shop membership of a user, for services that act on behalf of sellers
+// @Tags shops
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {array} user.ShopMember "Shop memberships retrieved successfully"
+// @Failure 400 {object} map[string]string "Invalid user ID"
+// @Failure 401 {object} map[string]string "API key required"
+// @Failure 403 {object} map[string]string "Invalid API key"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/users/{user_id}/shops [get]
+func (h *UserHandler) ListUserShops(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil || userID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid user ID",
+		})
+	}
+
+	members, err := h.userUsecase.ListUserShops(c.Request().Context(), userID)
+	if err != nil {
+		return shopMemberErrorResponse(c, "ListUserShops", err)
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+// shopMemberErrorResponse maps a shop member usecase error to its HTTP response
+func shopMemberErrorResponse(c echo.Context, handler string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	case strings.Contains(err.Error(), "invalid"):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("[%s] Usecase error: %v", handler, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "Failed to process shop member",
+	})
+}
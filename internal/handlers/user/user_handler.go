@@ -27,6 +27,22 @@ type UserHandlerInterface interface {
 	HandleEnvelopeLogin(c echo.Context) error
 	HandleDirectLogin(c echo.Context) error
 	CreateEnvelope(c echo.Context) error
+	CreateAddress(c echo.Context) error
+	ListAddresses(c echo.Context) error
+	GetAddress(c echo.Context) error
+	UpdateAddress(c echo.Context) error
+	DeleteAddress(c echo.Context) error
+	GetUserAddress(c echo.Context) error
+	AddShopMember(c echo.Context) error
+	GetShopMember(c echo.Context) error
+	ListUserShops(c echo.Context) error
+	CreateShop(c echo.Context) error
+	GetShop(c echo.Context) error
+	UpdateShop(c echo.Context) error
+	ListShopMembers(c echo.Context) error
+	SetShopMember(c echo.Context) error
+	RemoveShopMember(c echo.Context) error
+	ListShops(c echo.Context) error
 }
 
 // CreateUser handles POST /users
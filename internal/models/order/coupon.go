@@ -0,0 +1,88 @@
+package order
+
+import (
+	"time"
+
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// Coupon discount types
+const (
+	CouponDiscountPercentage = "percentage"
+	CouponDiscountFixed      = "fixed"
+)
+
+// Coupon scopes, deciding which order items a coupon discounts
+const (
+	CouponScopeAll     = "all"
+	CouponScopeShop    = "shop"
+	CouponScopeProduct = "product"
+)
+
+// Coupon statuses
+const (
+	CouponStatusActive   = "active"
+	CouponStatusInactive = "inactive"
+)
+
+// Coupon represents a promotion code granting a discount on eligible order items
+type Coupon struct {
+	ID           int64       `json:"id" db:"id"`
+	Code         string      `json:"code" db:"code"`
+	Description  string      `json:"description" db:"description"`
+	DiscountType string      `json:"discount_type" db:"discount_type"`       // percentage, fixed
+	PercentOff   string      `json:"percent_off,omitempty" db:"percent_off"` // percentage coupons, e.g. "12.5"
+	AmountOff    money.Money `json:"amount_off" db:"amount_off"`             // fixed coupons
+	MinSpend     money.Money `json:"min_spend" db:"min_spend"`               // on the eligible items, zero for none
+	Scope        string      `json:"scope" db:"scope"`                       // all, shop, product
+	ShopID       int         `json:"shop_id,omitempty" db:"shop_id"`
+	ProductID    int64       `json:"product_id,omitempty" db:"product_id"`
+	UsageLimit   int         `json:"usage_limit" db:"usage_limit"`       // total redemptions, 0 for unlimited
+	PerUserLimit int         `json:"per_user_limit" db:"per_user_limit"` // redemptions per user, 0 for unlimited
+	UsedCount    int         `json:"used_count" db:"used_count"`
+	Status       string      `json:"status" db:"status"` // active, inactive
+	StartsAt     time.Time   `json:"starts_at" db:"starts_at"`
+	EndsAt       *time.Time  `json:"ends_at,omitempty" db:"ends_at"`
+	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// CreateCouponRequest represents the request to create a coupon. Amounts are
+// decimal strings in Currency, which defaults to the service's default currency.
+type CreateCouponRequest struct {
+	Code         string     `json:"code" validate:"required,max=64"`
+	Description  string     `json:"description,omitempty"`
+	DiscountType string     `json:"discount_type" validate:"required,oneof=percentage fixed"`
+	PercentOff   string     `json:"percent_off,omitempty"`
+	AmountOff    string     `json:"amount_off,omitempty"`
+	MinSpend     string     `json:"min_spend,omitempty"`
+	Currency     string     `json:"currency,omitempty"`
+	Scope        string     `json:"scope,omitempty" validate:"omitempty,oneof=all shop product"`
+	ShopID       int        `json:"shop_id,omitempty"`
+	ProductID    int64      `json:"product_id,omitempty"`
+	UsageLimit   int        `json:"usage_limit,omitempty" validate:"min=0"`
+	PerUserLimit int        `json:"per_user_limit,omitempty" validate:"min=0"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+}
+
+// CouponRedemption records a coupon used by an order, counting towards its usage limits
+type CouponRedemption struct {
+	ID        int64       `json:"id" db:"id"`
+	CouponID  int64       `json:"coupon_id" db:"coupon_id"`
+	UserID    int         `json:"user_id" db:"user_id"`
+	OrderID   int64       `json:"order_id" db:"order_id"`
+	Amount    money.Money `json:"amount" db:"amount"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+// OrderDiscount represents one discount line applied to an order
+type OrderDiscount struct {
+	ID          int64       `json:"id,omitempty" db:"id"`
+	OrderID     int64       `json:"order_id" db:"order_id"`
+	CouponID    int64       `json:"coupon_id" db:"coupon_id"`
+	Code        string      `json:"code" db:"code"`
+	ProductID   int64       `json:"product_id,omitempty" db:"product_id"` // zero when the line applies to the whole order
+	Description string      `json:"description" db:"description"`
+	Amount      money.Money `json:"amount" db:"amount"`
+}
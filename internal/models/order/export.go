@@ -0,0 +1,48 @@
+package order
+
+import (
+	"time"
+
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// Order export formats
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// OrderExportRequest represents the date range and format of an order export
+type OrderExportRequest struct {
+	From   string `json:"from" query:"from"`     // YYYY-MM-DD, inclusive
+	To     string `json:"to" query:"to"`         // YYYY-MM-DD, inclusive
+	Format string `json:"format" query:"format"` // csv (default) or ndjson
+
+	// Parsed date range, populated by the usecase from From/To
+	FromTime time.Time `json:"-"`
+	ToTime   time.Time `json:"-"`
+}
+
+// OrderExportRow represents one order item together with its order. Every
+// amount is in the order's currency.
+type OrderExportRow struct {
+	OrderID        int64       `json:"order_id"`
+	CheckoutID     string      `json:"checkout_id,omitempty"`
+	UserID         int         `json:"user_id"`
+	ShopID         int         `json:"shop_id"`
+	Status         string      `json:"status"`
+	Currency       string      `json:"currency"`
+	OrderTotal     money.Money `json:"order_total"`
+	DiscountTotal  money.Money `json:"discount_total"`
+	TaxTotal       money.Money `json:"tax_total"`
+	ShippingFee    money.Money `json:"shipping_fee"`
+	CouponCode     string      `json:"coupon_code,omitempty"`
+	OrderCreatedAt time.Time   `json:"order_created_at"`
+	ItemID         int64       `json:"item_id"`
+	ProductID      int64       `json:"product_id"`
+	Quantity       int         `json:"quantity"`
+	UnitPrice      money.Money `json:"unit_price"`
+	TaxRate        string      `json:"tax_rate"` // percentage, e.g. "11"
+	TaxAmount      money.Money `json:"tax_amount"`
+	TaxInclusive   bool        `json:"tax_inclusive"`
+}
@@ -2,65 +2,234 @@ package order
 
 import (
 	"time"
+
+	"github.com/Christyan39/test-eDot/pkg/money"
 )
 
 // Order represents an order in the system
 type Order struct {
-	ID         int64                  `json:"id" db:"id"`
-	UserID     int                    `json:"user_id" db:"user_id"`
-	ShopID     int                    `json:"shop_id" db:"shop_id"`
-	TotalPrice float64                `json:"total_price" db:"total_price"`
-	Status     string                 `json:"status" db:"status"`
-	OrderData  map[string]interface{} `json:"order_data" db:"order_data"`
-	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time              `json:"updated_at" db:"updated_at"`
-	ExpiresAt  time.Time              `json:"expires_at" db:"expires_at"`
+	ID              int64            `json:"id" db:"id"`
+	UserID          int              `json:"user_id" db:"user_id"`
+	ShopID          int              `json:"shop_id" db:"shop_id"`
+	CheckoutID      string           `json:"checkout_id,omitempty" db:"checkout_id"`
+	TotalPrice      money.Money      `json:"total_price" db:"total_price"`     // in the buyer's currency
+	ShopCurrency    string           `json:"shop_currency" db:"shop_currency"` // currency the shop prices its products in
+	ExchangeRate    string           `json:"exchange_rate" db:"exchange_rate"` // shop currency to buyer's currency, at checkout
+	CouponCode      string           `json:"coupon_code,omitempty" db:"coupon_code"`
+	DiscountTotal   money.Money      `json:"discount_total" db:"discount_total"` // already deducted from TotalPrice
+	TaxTotal        money.Money      `json:"tax_total" db:"tax_total"`           // inclusive and exclusive tax of all items
+	AddressID       int64            `json:"address_id,omitempty" db:"address_id"`
+	ShippingAddress *ShippingAddress `json:"shipping_address,omitempty" db:"shipping_address"` // copy of the address when the order was placed
+	ShippingFee     money.Money      `json:"shipping_fee" db:"shipping_fee"`                   // included in TotalPrice
+	Status          string           `json:"status" db:"status"`
+	OrderData       *OrderData       `json:"order_data,omitempty" db:"order_data"`
+	CreatedAt       time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at" db:"updated_at"`
+	ExpiresAt       time.Time        `json:"expires_at" db:"expires_at"`
+	AcceptedAt      *time.Time       `json:"accepted_at,omitempty" db:"accepted_at"` // when the shop accepted the order
+	Items           []OrderItem      `json:"items,omitempty"`
+	Discounts       []OrderDiscount  `json:"discounts,omitempty"`
+	Shipments       []Shipment       `json:"shipments,omitempty"`
+	Returns         []OrderReturn    `json:"returns,omitempty"`
+	Refunds         []Refund         `json:"refunds,omitempty"`
 }
 
-// OrderGroup represents a group of related orders from the same request
+// OrderGroup represents the per-shop orders created by one checkout
 type OrderGroup struct {
-	Orders     []Order   `json:"orders"`
-	TotalPrice float64   `json:"total_price"`
-	TotalItems int       `json:"total_items"`
-	UserID     int       `json:"user_id"`
-	ShopID     int       `json:"shop_id"`
-	Status     string    `json:"status"`
-	CreatedAt  time.Time `json:"created_at"`
+	CheckoutID string      `json:"checkout_id"`
+	Orders     []Order     `json:"orders"`
+	TotalPrice money.Money `json:"total_price"`
+	TotalItems int         `json:"total_items"`
+	UserID     int         `json:"user_id"`
+	Status     string      `json:"status"`
+	CreatedAt  time.Time   `json:"created_at"`
 }
 
 // OrderItem represents a single item in an order
 type OrderItem struct {
-	OrderID   int64   `json:"order_id"`
-	ProductID int64   `json:"product_id" validate:"required,min=1"`
-	Quantity  int     `json:"quantity" validate:"required,min=1"`
-	Price     float64 `json:"price" validate:"required,min=0"`
+	ID        int64       `json:"id,omitempty"`
+	OrderID   int64       `json:"order_id"`
+	ProductID int64       `json:"product_id" validate:"required,min=1"`
+	VariantID int64       `json:"variant_id,omitempty" validate:"omitempty,min=1"` // required for products with variants
+	Quantity  int         `json:"quantity" validate:"required,min=1"`
+	Price     money.Money `json:"price" validate:"required"`
+
+	// Set by the usecase from the tax rules
+	TaxRate      string      `json:"tax_rate,omitempty"` // percentage, e.g. "11"
+	TaxAmount    money.Money `json:"tax_amount"`
+	TaxInclusive bool        `json:"tax_inclusive"` // the tax is part of the price rather than added to it
 }
 
 // CreateOrderRequest represents the request to create a new order with multiple products
 type CreateOrderRequest struct {
-	OrderID    int64                  `json:"order_id"`
-	UserID     int                    `json:"user_id" validate:"required,min=1"`
-	ShopID     int                    `json:"shop_id" validate:"required,min=1"`
-	TotalPrice float64                `json:"total_price"`
-	Items      []OrderItem            `json:"items" validate:"required,min=1,dive"`
-	OrderData  map[string]interface{} `json:"order_data,omitempty"`
-	ExpiresAt  time.Time              `json:"expires_at"`
+	OrderID    int64       `json:"order_id"`
+	UserID     int         `json:"user_id" validate:"required,min=1"`
+	ShopID     int         `json:"shop_id" validate:"required,min=1"`
+	Currency   string      `json:"currency,omitempty"` // buyer's currency, defaults to the currency of total_price
+	TotalPrice money.Money `json:"total_price"`        // after discounts
+	CouponCode string      `json:"coupon_code,omitempty"`
+	AddressID  int64       `json:"address_id,omitempty"` // address book entry to ship to
+	Items      []OrderItem `json:"items" validate:"required,min=1,dive"`
+	OrderData  *OrderData  `json:"order_data,omitempty"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+	CheckoutID string      `json:"-"` // set for orders created by a multi-shop checkout
+
+	// Reservation of the request's Idempotency-Key, bound to the order in the
+	// transaction creating it
+	IdempotencyKeyID int64 `json:"-"`
+
+	// Set by the usecase from the products and the exchange-rate table
+	ShopCurrency string `json:"shop_currency,omitempty"`
+	ExchangeRate string `json:"exchange_rate,omitempty"`
+
+	// Set by the usecase when a coupon is applied
+	DiscountTotal money.Money     `json:"-"`
+	Discounts     []OrderDiscount `json:"-"`
+
+	// Set by the usecase from the tax rules
+	TaxTotal money.Money `json:"-"`
+
+	// Set by the usecase from the address book and the shipping rates
+	ShippingAddress *ShippingAddress `json:"-"`
+	ShippingFee     money.Money      `json:"-"`
+}
+
+// CheckoutRequest represents a cart checkout that may span several shops
+type CheckoutRequest struct {
+	UserID     int         `json:"-"`
+	Currency   string      `json:"currency,omitempty"` // buyer's currency, defaults to the currency of total_price
+	TotalPrice money.Money `json:"total_price"`
+	CouponCode string      `json:"coupon_code,omitempty"` // rejected: coupons apply to single-shop orders only
+	AddressID  int64       `json:"address_id,omitempty"`  // address book entry every order ships to
+	Items      []OrderItem `json:"items" validate:"required,min=1,dive"`
+	OrderData  *OrderData  `json:"order_data,omitempty"`
 }
 
 // CreateOrderResponse represents the response after creating an order
 type CreateOrderResponse struct {
-	OrderIDs   []int   `json:"order_ids"`
-	TotalPrice float64 `json:"total_price"`
-	TotalItems int     `json:"total_items"`
-	Status     string  `json:"status"`
-	Message    string  `json:"message"`
+	Order
+	TotalItems int    `json:"total_items"`
+	Message    string `json:"message"`
+}
+
+// PayOrderRequest represents the payment confirmation for a pending order
+type PayOrderRequest struct {
+	PaymentReference string `json:"payment_reference" validate:"omitempty,max=100"`
+}
+
+// CancelOrderRequest represents a customer's request to cancel a pending order
+type CancelOrderRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=255"`
+}
+
+// RejectOrderRequest represents a shop's reason for rejecting an order
+type RejectOrderRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=255"`
+}
+
+// OrderListRequest represents request for order listing with filters
+type OrderListRequest struct {
+	UserID    int    `json:"-"`
+	Page      int    `json:"page" query:"page" validate:"min=1"`
+	Limit     int    `json:"limit" query:"limit" validate:"min=1,max=100"`
+	Status    string `json:"status" query:"status" validate:"omitempty,oneof=pending confirmed partially_shipped shipped delivered cancelled expired"`
+	ShopID    int    `json:"shop_id" query:"shop_id" validate:"omitempty,min=1"`
+	StartDate string `json:"start_date" query:"start_date"` // YYYY-MM-DD, inclusive
+	EndDate   string `json:"end_date" query:"end_date"`     // YYYY-MM-DD, inclusive
+
+	// Parsed date range, populated by the usecase from StartDate/EndDate
+	From time.Time `json:"-"`
+	To   time.Time `json:"-"`
+}
+
+// OrderListResponse represents paginated order list response
+type OrderListResponse struct {
+	Orders []Order `json:"orders"`
+	Total  int     `json:"total"`
+	Page   int     `json:"page"`
+	Limit  int     `json:"limit"`
+	Pages  int     `json:"pages"`
 }
 
 // OrderStatus constants
 const (
-	OrderStatusPending   = "pending"
-	OrderStatusConfirmed = "confirmed"
-	OrderStatusShipped   = "shipped"
-	OrderStatusDelivered = "delivered"
-	OrderStatusCancelled = "cancelled"
+	OrderStatusPending          = "pending"
+	OrderStatusConfirmed        = "confirmed"
+	OrderStatusPartiallyShipped = "partially_shipped"
+	OrderStatusShipped          = "shipped"
+	OrderStatusDelivered        = "delivered"
+	OrderStatusCancelled        = "cancelled"
+	OrderStatusExpired          = "expired"
+)
+
+// IdempotencyKey represents a stored Idempotency-Key of an order creation request.
+// A record without ResponseStatus is still being processed.
+type IdempotencyKey struct {
+	ID             int64     `json:"id" db:"id"`
+	UserID         int       `json:"user_id" db:"user_id"`
+	Key            string    `json:"idempotency_key" db:"idempotency_key"`
+	RequestHash    string    `json:"request_hash" db:"request_hash"`
+	OrderID        int64     `json:"order_id" db:"order_id"`
+	ResponseStatus int       `json:"response_status" db:"response_status"`
+	ResponseBody   []byte    `json:"response_body" db:"response_body"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// OutboxMessage represents an order event waiting to be relayed to NSQ
+type OutboxMessage struct {
+	ID          int64     `json:"id" db:"id"`
+	OrderID     int64     `json:"order_id" db:"order_id"`
+	Topic       string    `json:"topic" db:"topic"`
+	Payload     []byte    `json:"payload" db:"payload"`
+	DeliverAt   time.Time `json:"deliver_at" db:"deliver_at"` // when consumers should receive the message
+	Status      string    `json:"status" db:"status"`         // pending, sent, failed
+	Attempts    int       `json:"attempts" db:"attempts"`
+	LastError   string    `json:"last_error,omitempty" db:"last_error"`
+	AvailableAt time.Time `json:"available_at" db:"available_at"` // next time the relay may pick it up
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// Outbox status constants
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed"
 )
+
+// Compensation represents a stock action on the product service that must
+// eventually succeed: a compensating action of the order creation saga, or the
+// commit, release or restock of an order's stock queued with its status change.
+// Pending ones are retried by the compensation worker.
+type Compensation struct {
+	ID          int64     `json:"id" db:"id"`
+	OrderID     int64     `json:"order_id" db:"order_id"`
+	Action      string    `json:"action" db:"action"`                 // release_held_stock, commit_held_stock, restock_order, restock_return
+	ReturnID    int64     `json:"return_id,omitempty" db:"return_id"` // the return a restock_return action restocks
+	Status      string    `json:"status" db:"status"`                 // pending, done
+	Attempts    int       `json:"attempts" db:"attempts"`
+	LastError   string    `json:"last_error,omitempty" db:"last_error"`
+	AvailableAt time.Time `json:"available_at" db:"available_at"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// Compensation constants
+const (
+	CompensationActionReleaseHeldStock = "release_held_stock"
+	CompensationActionCommitHeldStock  = "commit_held_stock"
+	CompensationActionRestockOrder     = "restock_order"
+	CompensationActionRestockReturn    = "restock_return"
+
+	CompensationStatusPending = "pending"
+	CompensationStatusDone    = "done"
+)
+
+// OrderStatusHistory represents a single status change of an order
+type OrderStatusHistory struct {
+	ID         int64     `json:"id" db:"id"`
+	OrderID    int64     `json:"order_id" db:"order_id"`
+	FromStatus string    `json:"from_status,omitempty" db:"from_status"`
+	ToStatus   string    `json:"to_status" db:"to_status"`
+	ChangedBy  string    `json:"changed_by" db:"changed_by"` // e.g. user:12, system
+	Reason     string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
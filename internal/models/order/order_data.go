@@ -0,0 +1,73 @@
+package order
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Christyan39/test-eDot/pkg/jsonschema"
+)
+
+// OrderDataVersion is the order_data schema version this service writes
+const OrderDataVersion = 1
+
+// MaxOrderDataBytes is the largest order_data payload accepted from clients
+const MaxOrderDataBytes = 2048
+
+//go:embed order_data.schema.json
+var orderDataSchemaJSON []byte
+
+var orderDataSchema = jsonschema.MustCompile(orderDataSchemaJSON)
+
+// OrderData is client-supplied metadata stored with an order
+type OrderData struct {
+	Version     int    `json:"version"`
+	Notes       string `json:"notes,omitempty"`
+	GiftMessage string `json:"gift_message,omitempty"`
+	Channel     string `json:"channel,omitempty" enums:"web,ios,android,api"` // where the order was placed
+	Device      string `json:"device,omitempty"`
+}
+
+// ValidateOrderData checks a client-supplied order_data payload against the
+// current schema. Unknown fields and oversized payloads are rejected.
+func ValidateOrderData(raw []byte) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if len(raw) > MaxOrderDataBytes {
+		return fmt.Errorf("invalid order_data: must be at most %d bytes", MaxOrderDataBytes)
+	}
+	if err := orderDataSchema.Validate(raw); err != nil {
+		return fmt.Errorf("invalid order_data: %v", err)
+	}
+	return nil
+}
+
+// DecodeOrderData reads order_data as stored. Decoding is lenient so rows
+// written by older or newer versions of the service still load: unknown fields
+// are dropped and known fields of an unexpected type are left empty. Rows
+// written before order_data was versioned decode with version 0.
+func DecodeOrderData(raw []byte) (*OrderData, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal order data: %w", err)
+	}
+
+	data := &OrderData{}
+	decodeOrderDataField(fields, "version", &data.Version)
+	decodeOrderDataField(fields, "notes", &data.Notes)
+	decodeOrderDataField(fields, "gift_message", &data.GiftMessage)
+	decodeOrderDataField(fields, "channel", &data.Channel)
+	decodeOrderDataField(fields, "device", &data.Device)
+	return data, nil
+}
+
+func decodeOrderDataField(fields map[string]json.RawMessage, name string, dest interface{}) {
+	if raw, exists := fields[name]; exists {
+		_ = json.Unmarshal(raw, dest) // a mistyped field is left empty
+	}
+}
@@ -0,0 +1,72 @@
+package order
+
+import (
+	"time"
+
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// Return statuses
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+)
+
+// Refund statuses
+const (
+	RefundStatusPending = "pending"
+)
+
+// OrderReturn is a customer's request to send back some of a delivered order's items
+type OrderReturn struct {
+	ID             int64        `json:"id" db:"id"`
+	OrderID        int64        `json:"order_id" db:"order_id"`
+	UserID         int          `json:"user_id" db:"user_id"`
+	Status         string       `json:"status" db:"status"` // requested, approved, rejected
+	Reason         string       `json:"reason" db:"reason"`
+	ResolutionNote string       `json:"resolution_note,omitempty" db:"resolution_note"` // seller's note when approving or rejecting
+	ResolvedAt     *time.Time   `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at" db:"updated_at"`
+	Items          []ReturnItem `json:"items"`
+	Refund         *Refund      `json:"refund,omitempty"`
+}
+
+// ReturnItem is the quantity of an order item being returned
+type ReturnItem struct {
+	ID          int64 `json:"id,omitempty" db:"id"`
+	ReturnID    int64 `json:"return_id" db:"return_id"`
+	OrderItemID int64 `json:"order_item_id" db:"order_item_id"`
+	ProductID   int64 `json:"product_id" db:"product_id"`
+	VariantID   int64 `json:"variant_id,omitempty" db:"variant_id"`
+	Quantity    int   `json:"quantity" db:"quantity"`
+}
+
+// CreateReturnRequest represents a customer returning order items
+type CreateReturnRequest struct {
+	Reason string              `json:"reason" validate:"required,max=500"`
+	Items  []ReturnItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// ReturnItemRequest is the quantity of an order item to return
+type ReturnItemRequest struct {
+	OrderItemID int64 `json:"order_item_id" validate:"required,min=1"`
+	Quantity    int   `json:"quantity" validate:"required,min=1"`
+}
+
+// ResolveReturnRequest represents a seller approving or rejecting a return
+type ResolveReturnRequest struct {
+	Note string `json:"note,omitempty" validate:"max=500"`
+}
+
+// Refund is the money owed back to the customer for an approved return or a
+// paid order the shop rejected, in the order's currency
+type Refund struct {
+	ID        int64       `json:"id" db:"id"`
+	ReturnID  int64       `json:"return_id,omitempty" db:"return_id"` // zero when the whole order is refunded
+	OrderID   int64       `json:"order_id" db:"order_id"`
+	Amount    money.Money `json:"amount" db:"amount"`
+	Status    string      `json:"status" db:"status"` // pending
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
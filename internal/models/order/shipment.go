@@ -0,0 +1,46 @@
+package order
+
+import "time"
+
+// Shipment statuses
+const (
+	ShipmentStatusInTransit = "in_transit"
+	ShipmentStatusDelivered = "delivered"
+)
+
+// Shipment represents a parcel carrying some or all of an order's items
+type Shipment struct {
+	ID             int64          `json:"id" db:"id"`
+	OrderID        int64          `json:"order_id" db:"order_id"`
+	Carrier        string         `json:"carrier" db:"carrier"`
+	TrackingNumber string         `json:"tracking_number" db:"tracking_number"`
+	Status         string         `json:"status" db:"status"` // in_transit, delivered
+	ShippedAt      time.Time      `json:"shipped_at" db:"shipped_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at" db:"updated_at"`
+	Items          []ShipmentItem `json:"items"`
+}
+
+// ShipmentItem is the quantity of an order item carried by a shipment
+type ShipmentItem struct {
+	ID          int64 `json:"id,omitempty" db:"id"`
+	ShipmentID  int64 `json:"shipment_id" db:"shipment_id"`
+	OrderItemID int64 `json:"order_item_id" db:"order_item_id"`
+	ProductID   int64 `json:"product_id" db:"product_id"`
+	Quantity    int   `json:"quantity" db:"quantity"`
+}
+
+// CreateShipmentRequest represents a seller shipping order items. Without
+// items, every item not shipped yet is included.
+type CreateShipmentRequest struct {
+	Carrier        string                `json:"carrier" validate:"required,max=50"`
+	TrackingNumber string                `json:"tracking_number" validate:"required,max=100"`
+	Items          []ShipmentItemRequest `json:"items,omitempty" validate:"dive"`
+}
+
+// ShipmentItemRequest is the quantity of an order item to ship
+type ShipmentItemRequest struct {
+	OrderItemID int64 `json:"order_item_id" validate:"required,min=1"`
+	Quantity    int   `json:"quantity" validate:"required,min=1"`
+}
@@ -0,0 +1,34 @@
+package order
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ShippingAddress is the copy of an address book entry an order ships to.
+// Later changes to the address book do not change placed orders.
+type ShippingAddress struct {
+	Label         string `json:"label"`
+	RecipientName string `json:"recipient_name"`
+	Phone         string `json:"phone"`
+	Line1         string `json:"line1"`
+	Line2         string `json:"line2,omitempty"`
+	City          string `json:"city"`
+	Province      string `json:"province"`
+	PostalCode    string `json:"postal_code"`
+	Country       string `json:"country"`
+}
+
+// UnmarshalShippingAddress decodes a stored shipping address, returning nil for
+// orders placed without one
+func UnmarshalShippingAddress(data []byte) (*ShippingAddress, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var address ShippingAddress
+	if err := json.Unmarshal(data, &address); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal shipping address: %w", err)
+	}
+	return &address, nil
+}
@@ -0,0 +1,62 @@
+package product
+
+import (
+	"time"
+
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// Category is a node of the product category tree
+type Category struct {
+	ID        int64      `json:"id" db:"id"`
+	ParentID  int64      `json:"parent_id,omitempty" db:"parent_id"` // 0 for root categories
+	Name      string     `json:"name" db:"name"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	Children  []Category `json:"children,omitempty"`
+}
+
+// CreateCategoryRequest represents request to add a category to the tree
+type CreateCategoryRequest struct {
+	ParentID int64  `json:"parent_id,omitempty" validate:"omitempty,min=1"` // omitted for a root category
+	Name     string `json:"name" validate:"required,max=100"`
+}
+
+// UpdateCategoryRequest represents request to rename or move a category
+type UpdateCategoryRequest struct {
+	ID       int64  `json:"-"`
+	ParentID *int64 `json:"parent_id,omitempty" validate:"omitempty,min=0"` // 0 moves the category to the root
+	Name     string `json:"name,omitempty" validate:"omitempty,max=100"`
+}
+
+// ProductFacets counts the products matching a listing by category, price
+// range and shop. Each facet ignores the listing's own filter on it, so its
+// counts show what selecting another value would return.
+type ProductFacets struct {
+	Categories   []CategoryFacet `json:"categories"`
+	PriceBuckets []PriceFacet    `json:"price_buckets"`
+	Shops        []ShopFacet     `json:"shops"`
+}
+
+// CategoryFacet counts the products in a category and its subcategories
+type CategoryFacet struct {
+	CategoryID int64  `json:"category_id"`
+	ParentID   int64  `json:"parent_id,omitempty"`
+	Name       string `json:"name"`
+	Count      int    `json:"count"`
+}
+
+// PriceFacet counts the products priced from Min up to but excluding Max.
+// Buckets are per currency and span one order of magnitude, e.g. 10 to 100.
+type PriceFacet struct {
+	Min   money.Money `json:"min"`
+	Max   money.Money `json:"max"`
+	Count int         `json:"count"`
+}
+
+// ShopFacet counts the products of a shop
+type ShopFacet struct {
+	ShopID   int    `json:"shop_id"`
+	ShopName string `json:"shop_name,omitempty"`
+	Count    int    `json:"count"`
+}
@@ -1,12 +1,20 @@
 package product
 
-import "time"
+import (
+	"encoding/json"
+	"time"
 
-// ShopMetadata represents shop information stored as JSON
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// ShopMetadata represents shop information shown with a product. It is
+// hydrated from the shop when products are read; the copy stored with the
+// product is only a snapshot taken at creation.
 type ShopMetadata struct {
 	ShopName string `json:"shop_name"`
 	ShopID   int64  `json:"shop_id"`
 	Status   string `json:"status"`
+	Region   string `json:"region,omitempty"` // where the shop sells from, used for tax rules
 }
 
 // Product represents a product entity
@@ -14,52 +22,118 @@ type Product struct {
 	ID           int64        `json:"id" db:"id"`
 	Name         string       `json:"name" db:"name"`
 	Description  string       `json:"description" db:"description"`
-	Price        float64      `json:"price" db:"price"`
+	Price        money.Money  `json:"price" db:"price"` // carries the product's currency
+	TaxCategory  string       `json:"tax_category" db:"tax_category"`
+	WeightGrams  int          `json:"weight_grams" db:"weight_grams"` // shipping weight of one unit
 	Stock        int          `json:"stock" db:"stock"`
 	OnHoldStock  int          `json:"on_hold_stock" db:"on_hold_stock"`
 	ShopID       int          `json:"shop_id" db:"shop_id"`
 	ShopMetadata ShopMetadata `json:"shop_metadata" db:"shop_metadata"`
-	Status       string       `json:"status" db:"status"` // active, inactive, discontinued
+	CategoryID   int64        `json:"category_id,omitempty" db:"category_id"` // 0 for uncategorized products
+	Status       string       `json:"status" db:"status"`                     // active, inactive, discontinued
 	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
+
+	// Products with variants are stocked and ordered per variant; their own
+	// stock is unused
+	Variants []ProductVariant `json:"variants,omitempty"`
+}
+
+// ProductVariant is one orderable version of a product, e.g. a size and color
+type ProductVariant struct {
+	ID          int64             `json:"id" db:"id"`
+	ProductID   int64             `json:"product_id" db:"product_id"`
+	SKU         string            `json:"sku" db:"sku"`
+	Options     map[string]string `json:"options" db:"options"`       // e.g. {"size": "M", "color": "red"}
+	Price       *money.Money      `json:"price,omitempty" db:"price"` // overrides the product's price when set
+	Stock       int               `json:"stock" db:"stock"`
+	OnHoldStock int               `json:"on_hold_stock" db:"on_hold_stock"`
+	Status      string            `json:"status" db:"status"` // active, inactive, discontinued
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// CreateVariantRequest represents request to add a variant to a product
+type CreateVariantRequest struct {
+	ProductID int64             `json:"-"`
+	SKU       string            `json:"sku" validate:"required,max=64"`
+	Options   map[string]string `json:"options" validate:"required,min=1"`
+	Price     *money.Money      `json:"price,omitempty"` // defaults to the product's price
+	Stock     int               `json:"stock" validate:"min=0"`
+}
+
+// UpdateVariantRequest represents request to update a variant. On-hold stock
+// is managed by orders.
+type UpdateVariantRequest struct {
+	ID        int64             `json:"-"`
+	ProductID int64             `json:"-"`
+	SKU       string            `json:"sku,omitempty" validate:"omitempty,max=64"`
+	Options   map[string]string `json:"options,omitempty"`
+	Price     *money.Money      `json:"price,omitempty"`
+	Stock     *int              `json:"stock,omitempty" validate:"omitempty,min=0"`
+	Status    string            `json:"status,omitempty" validate:"omitempty,oneof=active inactive discontinued"`
 }
 
 // CreateProductRequest represents request to create product
 type CreateProductRequest struct {
 	Name         string       `json:"name" validate:"required,min=2,max=100"`
 	Description  string       `json:"description" validate:"required,min=10,max=1000"`
-	Price        float64      `json:"price" validate:"required,min=0"`
+	Price        money.Money  `json:"price" validate:"required"`
+	TaxCategory  string       `json:"tax_category,omitempty" validate:"omitempty,max=50"` // defaults to standard
+	WeightGrams  int          `json:"weight_grams,omitempty" validate:"min=0"`
 	Stock        int          `json:"stock" validate:"required,min=0"`
 	OnHoldStock  int          `json:"on_hold_stock" validate:"min=0"`
-	ShopID       int          `json:"shop_id" validate:"required,min=1"`
-	ShopMetadata ShopMetadata `json:"shop_metadata" validate:"required"`
+	ShopID       int          `json:"shop_id,omitempty" validate:"omitempty,min=1"` // defaults to the seller's only shop
+	CategoryID   int64        `json:"category_id,omitempty" validate:"omitempty,min=1"`
+	ShopMetadata ShopMetadata `json:"-"` // snapshot of the shop, set by the product service
 }
 
 // UpdateProductRequest represents request to update product
 type UpdateProductRequest struct {
-	ID           int64         `json:"id" validate:"required,min=1"`
-	Name         string        `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
-	Description  string        `json:"description,omitempty" validate:"omitempty,min=10,max=1000"`
-	Price        float64       `json:"price,omitempty" validate:"omitempty,min=0"`
-	Stock        int           `json:"stock,omitempty" validate:"omitempty,min=0"`
-	OnHoldStock  int           `json:"on_hold_stock,omitempty" validate:"omitempty,min=0"`
-	ShopID       int           `json:"shop_id,omitempty" validate:"omitempty,min=1"`
-	ShopMetadata *ShopMetadata `json:"shop_metadata,omitempty"`
-	Status       string        `json:"status,omitempty" validate:"omitempty,oneof=active inactive discontinued"`
+	ID          int64           `json:"id" validate:"required,min=1"`
+	Name        string          `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Description string          `json:"description,omitempty" validate:"omitempty,min=10,max=1000"`
+	RawPrice    json.RawMessage `json:"price,omitempty" swaggertype:"object"` // in the product's currency unless it states one
+	TaxCategory string          `json:"tax_category,omitempty" validate:"omitempty,max=50"`
+	WeightGrams int             `json:"weight_grams,omitempty" validate:"omitempty,min=0"`
+	Stock       *int            `json:"stock,omitempty" validate:"omitempty,min=0"` // nil leaves the stock unchanged
+	OnHoldStock int             `json:"on_hold_stock,omitempty" validate:"omitempty,min=0"`
+	ShopID      int             `json:"shop_id,omitempty" validate:"omitempty,min=1"`
+	CategoryID  int64           `json:"category_id,omitempty" validate:"omitempty,min=1"`
+	Status      string          `json:"status,omitempty" validate:"omitempty,oneof=active inactive discontinued"`
+
+	// Set by the usecase from RawPrice once the product's currency is known
+	Price money.Money `json:"-"`
 }
 
 // ProductListRequest represents request for product listing with filters
 type ProductListRequest struct {
-	Page     int     `json:"page" query:"page" validate:"min=1"`
-	Limit    int     `json:"limit" query:"limit" validate:"min=1,max=100"`
-	ShopID   int     `json:"shop_id" query:"shop_id" validate:"omitempty,min=1"`
-	MinPrice float64 `json:"min_price" query:"min_price" validate:"omitempty,min=0"`
-	MaxPrice float64 `json:"max_price" query:"max_price" validate:"omitempty,min=0"`
-	Status   string  `json:"status" query:"status" validate:"omitempty,oneof=active inactive discontinued"`
-	Search   string  `json:"search" query:"search" validate:"omitempty,max=100"`
-	IDs      []int   `json:"ids" query:"ids" validate:"omitempty,dive,min=1"`
+	Page     int         `json:"page" query:"page" validate:"min=1"`
+	Limit    int         `json:"limit" query:"limit" validate:"min=1,max=100"`
+	ShopID   int         `json:"shop_id" query:"shop_id" validate:"omitempty,min=1"`
+	Currency string      `json:"currency" query:"currency" validate:"omitempty,len=3"`
+	MinPrice money.Money `json:"min_price" query:"min_price"`
+	MaxPrice money.Money `json:"max_price" query:"max_price"`
+	Status   string      `json:"status" query:"status" validate:"omitempty,oneof=active inactive discontinued"`
+	Search   string      `json:"search" query:"search" validate:"omitempty,max=100"`
+	IDs      []int       `json:"ids" query:"ids" validate:"omitempty,dive,min=1"`
+
+	CategoryID  int64   `json:"category_id" query:"category_id" validate:"omitempty,min=1"` // includes its subcategories
+	Facets      bool    `json:"facets" query:"facets"`                                      // also count matches per category, price range and shop
+	CategoryIDs []int64 `json:"-"`                                                          // category_id and its descendants, set by the usecase
 }
 
+// Product status constants. Discontinued products are soft-deleted: they stay
+// readable for existing orders but can no longer be ordered.
+const (
+	ProductStatusActive       = "active"
+	ProductStatusInactive     = "inactive"
+	ProductStatusDiscontinued = "discontinued"
+)
+
+// DefaultTaxCategory is the tax category of products that do not set one
+const DefaultTaxCategory = "standard"
+
 // ProductListResponse represents paginated product list response
 type ProductListResponse struct {
 	Products []Product `json:"products"`
@@ -67,17 +141,26 @@ type ProductListResponse struct {
 	Page     int       `json:"page"`
 	Limit    int       `json:"limit"`
 	Pages    int       `json:"pages"`
+
+	Facets *ProductFacets `json:"facets,omitempty"` // only when requested
 }
 
 type HoldStockRequest struct {
-	OrderID  int64     `json:"order_id" validate:"required,min=1"`
-	Products []Product `json:"products" validate:"required,dive"`
+	OrderID  int64            `json:"order_id" validate:"required,min=1"`
+	Products []Product        `json:"products" validate:"dive"`
+	Variants []ProductVariant `json:"variants,omitempty" validate:"dive"` // ID, ProductID and OnHoldStock as the quantity
+}
+
+// HoldStockBatchRequest holds stock for several orders atomically
+type HoldStockBatchRequest struct {
+	Holds []HoldStockRequest `json:"holds" validate:"required,min=1,dive"`
 }
 
 type HoldStockAudit struct {
 	ID        int64     `json:"id" db:"id"`
 	OrderID   int64     `json:"order_id" db:"order_id"`
 	ProductID int64     `json:"product_id" db:"product_id"`
+	VariantID int64     `json:"variant_id,omitempty" db:"variant_id"` // 0 for products without variants
 	Quantity  int       `json:"quantity" db:"quantity"`
 	Status    string    `json:"status" db:"status"` // held, success, cancelled
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
@@ -86,3 +169,40 @@ type HoldStockAudit struct {
 type ReleaseHeldStockRequest struct {
 	OrderID int64 `json:"order_id" validate:"required,min=1"`
 }
+
+type CommitHeldStockRequest struct {
+	OrderID int64 `json:"order_id" validate:"required,min=1"`
+}
+
+// Hold stock audit status constants
+const (
+	HoldStatusHeld      = "held"
+	HoldStatusSuccess   = "success"
+	HoldStatusCancelled = "cancelled"
+)
+
+// RestockRequest puts stock back on sale, e.g. for returned items. The
+// reference identifies what caused the restock so a retried request is applied
+// only once.
+type RestockRequest struct {
+	Reference string        `json:"reference" validate:"required,max=100"` // e.g. return:42
+	Reason    string        `json:"reason,omitempty" validate:"omitempty,max=255"`
+	Items     []RestockItem `json:"items" validate:"required,min=1,dive"`
+}
+
+type RestockItem struct {
+	ProductID int64 `json:"product_id" validate:"required,min=1"`
+	VariantID int64 `json:"variant_id,omitempty" validate:"omitempty,min=1"`
+	Quantity  int   `json:"quantity" validate:"required,min=1"`
+}
+
+// StockAudit records a stock increment made outside of the hold flow
+type StockAudit struct {
+	ID        int64     `json:"id" db:"id"`
+	ProductID int64     `json:"product_id" db:"product_id"`
+	VariantID int64     `json:"variant_id,omitempty" db:"variant_id"` // 0 for products without variants
+	Quantity  int       `json:"quantity" db:"quantity"`
+	Reference string    `json:"reference" db:"reference"`
+	Reason    string    `json:"reason" db:"reason"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
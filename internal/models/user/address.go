@@ -0,0 +1,49 @@
+package user
+
+import "time"
+
+// Address represents a shipping address in a user's address book
+type Address struct {
+	ID            int64     `json:"id"`
+	UserID        int       `json:"user_id"`
+	Label         string    `json:"label"` // e.g. Home, Office
+	RecipientName string    `json:"recipient_name"`
+	Phone         string    `json:"phone"`
+	Line1         string    `json:"line1"`
+	Line2         string    `json:"line2,omitempty"`
+	City          string    `json:"city"`
+	Province      string    `json:"province"`
+	PostalCode    string    `json:"postal_code"`
+	Country       string    `json:"country"` // ISO 3166-1 alpha-2 code, e.g. ID
+	IsDefault     bool      `json:"is_default"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CreateAddressRequest represents request to add an address to the address book
+type CreateAddressRequest struct {
+	Label         string `json:"label"`
+	RecipientName string `json:"recipient_name"`
+	Phone         string `json:"phone"`
+	Line1         string `json:"line1"`
+	Line2         string `json:"line2,omitempty"`
+	City          string `json:"city"`
+	Province      string `json:"province"`
+	PostalCode    string `json:"postal_code"`
+	Country       string `json:"country"`
+	IsDefault     bool   `json:"is_default"` // the first address is always the default
+}
+
+// UpdateAddressRequest represents request to replace an address
+type UpdateAddressRequest struct {
+	Label         string `json:"label"`
+	RecipientName string `json:"recipient_name"`
+	Phone         string `json:"phone"`
+	Line1         string `json:"line1"`
+	Line2         string `json:"line2,omitempty"`
+	City          string `json:"city"`
+	Province      string `json:"province"`
+	PostalCode    string `json:"postal_code"`
+	Country       string `json:"country"`
+	IsDefault     bool   `json:"is_default"`
+}
@@ -0,0 +1,35 @@
+package user
+
+import "time"
+
+// Shop statuses
+const (
+	ShopStatusActive   = "active"
+	ShopStatusInactive = "inactive"
+)
+
+// Shop is a seller's storefront. Its members manage its products and orders.
+type Shop struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Region      string    `json:"region,omitempty"` // where the shop sells from, used for tax and shipping
+	Status      string    `json:"status"`           // active, inactive
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateShopRequest represents request to open a shop
+type CreateShopRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Region      string `json:"region,omitempty"`
+}
+
+// UpdateShopRequest represents request to replace a shop's details
+type UpdateShopRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Status      string `json:"status"` // defaults to active
+}
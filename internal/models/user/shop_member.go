@@ -0,0 +1,24 @@
+package user
+
+import "time"
+
+// Shop member roles
+const (
+	ShopRoleOwner = "owner"
+	ShopRoleStaff = "staff"
+)
+
+// ShopMember grants a user access to manage a shop
+type ShopMember struct {
+	ShopID    int       `json:"shop_id"`
+	UserID    int       `json:"user_id"`
+	Role      string    `json:"role"` // owner, staff
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AddShopMemberRequest represents request to give a user access to a shop
+type AddShopMemberRequest struct {
+	UserID int    `json:"user_id"`
+	Role   string `json:"role"` // defaults to staff
+}
@@ -0,0 +1,192 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// InsertCompensation records a failed compensating action for later retry.
+// It does not use a transaction because the order transaction has been rolled back.
+func (r *orderRepository) InsertCompensation(ctx context.Context, compensation *orderModel.Compensation) error {
+	lastError := compensation.LastError
+	if len(lastError) > 500 {
+		lastError = lastError[:500]
+	}
+
+	query := `
+		INSERT INTO order_compensations (
+		order_id,
+		action,
+		status,
+		attempts,
+		last_error,
+		available_at,
+		created_at,
+		updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, NOW(), NOW())
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		compensation.OrderID,
+		compensation.Action,
+		orderModel.CompensationStatusPending,
+		compensation.Attempts,
+		lastError,
+		compensation.AvailableAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert compensation: %w", err)
+	}
+
+	return nil
+}
+
+// InsertCompensationTx queues a stock action within the order's transaction, so
+// it is recorded if and only if the order change is committed
+func (r *orderRepository) InsertCompensationTx(ctx context.Context, tx *sql.Tx, compensation *orderModel.Compensation) (int64, error) {
+	query := `
+		INSERT INTO order_compensations (
+		order_id,
+		action,
+		return_id,
+		status,
+		attempts,
+		available_at,
+		created_at,
+		updated_at)
+		VALUES (?, ?, ?, ?, 0, ?, NOW(), NOW())
+	`
+
+	result, err := tx.ExecContext(ctx, query,
+		compensation.OrderID,
+		compensation.Action,
+		compensation.ReturnID,
+		orderModel.CompensationStatusPending,
+		compensation.AvailableAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert compensation: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted compensation ID: %w", err)
+	}
+	return id, nil
+}
+
+// GetPendingCompensationForUpdateTx locks a pending compensation by ID. It
+// returns nil when the compensation is done or locked by a worker applying it.
+func (r *orderRepository) GetPendingCompensationForUpdateTx(ctx context.Context, tx *sql.Tx, id int64) (*orderModel.Compensation, error) {
+	query := `
+		SELECT id, order_id, action, return_id, status, attempts, COALESCE(last_error, ''), available_at, created_at
+		FROM order_compensations
+		WHERE id = ? AND status = ?
+		FOR UPDATE SKIP LOCKED
+	`
+
+	var compensation orderModel.Compensation
+	err := tx.QueryRowContext(ctx, query, id, orderModel.CompensationStatusPending).Scan(
+		&compensation.ID,
+		&compensation.OrderID,
+		&compensation.Action,
+		&compensation.ReturnID,
+		&compensation.Status,
+		&compensation.Attempts,
+		&compensation.LastError,
+		&compensation.AvailableAt,
+		&compensation.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compensation: %w", err)
+	}
+
+	return &compensation, nil
+}
+
+// GetPendingCompensationsForUpdateTx locks a batch of due compensations,
+// skipping rows already locked by another worker
+func (r *orderRepository) GetPendingCompensationsForUpdateTx(ctx context.Context, tx *sql.Tx, limit int) ([]orderModel.Compensation, error) {
+	query := `
+		SELECT id, order_id, action, return_id, status, attempts, COALESCE(last_error, ''), available_at, created_at
+		FROM order_compensations
+		WHERE status = ? AND available_at <= NOW()
+		ORDER BY id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.QueryContext(ctx, query, orderModel.CompensationStatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compensations: %w", err)
+	}
+	defer rows.Close()
+
+	compensations := []orderModel.Compensation{}
+	for rows.Next() {
+		var compensation orderModel.Compensation
+		err := rows.Scan(
+			&compensation.ID,
+			&compensation.OrderID,
+			&compensation.Action,
+			&compensation.ReturnID,
+			&compensation.Status,
+			&compensation.Attempts,
+			&compensation.LastError,
+			&compensation.AvailableAt,
+			&compensation.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan compensation: %w", err)
+		}
+		compensations = append(compensations, compensation)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return compensations, nil
+}
+
+// MarkCompensationDoneTx marks a compensation as successfully applied
+func (r *orderRepository) MarkCompensationDoneTx(ctx context.Context, tx *sql.Tx, id int64) error {
+	query := `
+		UPDATE order_compensations
+		SET status = ?, attempts = attempts + 1, last_error = NULL, updated_at = NOW()
+		WHERE id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, orderModel.CompensationStatusDone, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark compensation as done: %w", err)
+	}
+
+	return nil
+}
+
+// MarkCompensationRetryTx records a failed attempt and when to try again
+func (r *orderRepository) MarkCompensationRetryTx(ctx context.Context, tx *sql.Tx, id int64, lastError string, availableAt time.Time) error {
+	if len(lastError) > 500 {
+		lastError = lastError[:500]
+	}
+
+	query := `
+		UPDATE order_compensations
+		SET attempts = attempts + 1, last_error = ?, available_at = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, lastError, availableAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update compensation: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// mysqlErrDuplicateEntry is the MySQL error number for a unique key violation
+const mysqlErrDuplicateEntry = 1062
+
+// CreateCoupon inserts a new coupon and returns its ID
+func (r *orderRepository) CreateCoupon(ctx context.Context, coupon *orderModel.Coupon) (int64, error) {
+	query := `
+		INSERT INTO coupons (
+		code,
+		description,
+		discount_type,
+		percent_off,
+		amount_off,
+		min_spend,
+		currency,
+		scope,
+		shop_id,
+		product_id,
+		usage_limit,
+		per_user_limit,
+		status,
+		starts_at,
+		ends_at,
+		created_at,
+		updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, 0), NULLIF(?, 0), ?, ?, ?, ?, ?, NOW(), NOW())
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		coupon.Code,
+		coupon.Description,
+		coupon.DiscountType,
+		coupon.PercentOff,
+		coupon.AmountOff,
+		coupon.MinSpend,
+		coupon.AmountOff.Currency,
+		coupon.Scope,
+		coupon.ShopID,
+		coupon.ProductID,
+		coupon.UsageLimit,
+		coupon.PerUserLimit,
+		coupon.Status,
+		coupon.StartsAt,
+		coupon.EndsAt,
+	)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			return 0, fmt.Errorf("coupon code %s already exists", coupon.Code)
+		}
+		return 0, fmt.Errorf("failed to create coupon: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted coupon ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetCouponByCodeForUpdateTx locks a coupon by its code so its usage can be
+// checked and counted atomically. It returns nil when no coupon has the code.
+func (r *orderRepository) GetCouponByCodeForUpdateTx(ctx context.Context, tx *sql.Tx, code string) (*orderModel.Coupon, error) {
+	query := `
+		SELECT id, code, description, discount_type, percent_off, currency, amount_off, currency, min_spend,
+			scope, COALESCE(shop_id, 0), COALESCE(product_id, 0), usage_limit, per_user_limit, used_count,
+			status, starts_at, ends_at, created_at, updated_at
+		FROM coupons
+		WHERE code = ?
+		FOR UPDATE
+	`
+
+	var coupon orderModel.Coupon
+	var endsAt sql.NullTime
+	err := tx.QueryRowContext(ctx, query, code).Scan(
+		&coupon.ID,
+		&coupon.Code,
+		&coupon.Description,
+		&coupon.DiscountType,
+		&coupon.PercentOff,
+		&coupon.AmountOff.Currency, // scanned first, the amount's precision depends on it
+		&coupon.AmountOff,
+		&coupon.MinSpend.Currency,
+		&coupon.MinSpend,
+		&coupon.Scope,
+		&coupon.ShopID,
+		&coupon.ProductID,
+		&coupon.UsageLimit,
+		&coupon.PerUserLimit,
+		&coupon.UsedCount,
+		&coupon.Status,
+		&coupon.StartsAt,
+		&endsAt,
+		&coupon.CreatedAt,
+		&coupon.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get coupon: %w", err)
+	}
+	if endsAt.Valid {
+		coupon.EndsAt = &endsAt.Time
+	}
+
+	return &coupon, nil
+}
+
+// CountCouponRedemptionsByUserTx counts the orders of a user that currently use the coupon
+func (r *orderRepository) CountCouponRedemptionsByUserTx(ctx context.Context, tx *sql.Tx, couponID int64, userID int) (int, error) {
+	query := `SELECT COUNT(*) FROM coupon_redemptions WHERE coupon_id = ? AND user_id = ?`
+
+	var count int
+	err := tx.QueryRowContext(ctx, query, couponID, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count coupon redemptions: %w", err)
+	}
+
+	return count, nil
+}
+
+// InsertCouponRedemptionTx records a coupon used by an order and counts it towards the coupon's usage
+func (r *orderRepository) InsertCouponRedemptionTx(ctx context.Context, tx *sql.Tx, redemption *orderModel.CouponRedemption) error {
+	query := `
+		INSERT INTO coupon_redemptions (coupon_id, user_id, order_id, amount, currency, created_at)
+		VALUES (?, ?, ?, ?, ?, NOW())
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		redemption.CouponID,
+		redemption.UserID,
+		redemption.OrderID,
+		redemption.Amount,
+		redemption.Amount.Currency,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert coupon redemption: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE coupons SET used_count = used_count + 1, updated_at = NOW() WHERE id = ?`, redemption.CouponID)
+	if err != nil {
+		return fmt.Errorf("failed to update coupon usage: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseCouponRedemptionsTx gives back the coupon usage of an order that will not be fulfilled
+func (r *orderRepository) ReleaseCouponRedemptionsTx(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	query := `
+		UPDATE coupons c
+		JOIN coupon_redemptions cr ON cr.coupon_id = c.id
+		SET c.used_count = GREATEST(c.used_count - 1, 0), c.updated_at = NOW()
+		WHERE cr.order_id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to release coupon usage: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM coupon_redemptions WHERE order_id = ?`, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to delete coupon redemptions: %w", err)
+	}
+
+	return nil
+}
+
+// InsertOrderDiscountsTx records the discount lines applied to an order
+func (r *orderRepository) InsertOrderDiscountsTx(ctx context.Context, tx *sql.Tx, discounts []orderModel.OrderDiscount) error {
+	if len(discounts) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(discounts))
+	args := make([]interface{}, 0, len(discounts)*6)
+	for _, discount := range discounts {
+		placeholders = append(placeholders, "(?, ?, ?, NULLIF(?, 0), ?, ?, NOW())")
+		args = append(args, discount.OrderID, discount.CouponID, discount.Code, discount.ProductID, discount.Description, discount.Amount)
+	}
+
+	query := `
+		INSERT INTO order_discounts (
+		order_id,
+		coupon_id,
+		code,
+		product_id,
+		description,
+		amount,
+		created_at)
+		VALUES ` + strings.Join(placeholders, ",")
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to insert order discounts: %w", err)
+	}
+
+	return nil
+}
+
+// GetOrderDiscountsByOrderIDs retrieves the discount lines of the given orders
+func (r *orderRepository) GetOrderDiscountsByOrderIDs(ctx context.Context, orderIDs []int64) ([]orderModel.OrderDiscount, error) {
+	if len(orderIDs) == 0 {
+		return []orderModel.OrderDiscount{}, nil
+	}
+
+	placeholders := make([]string, len(orderIDs))
+	args := make([]interface{}, len(orderIDs))
+	for i, id := range orderIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`
+		SELECT od.id, od.order_id, od.coupon_id, od.code, COALESCE(od.product_id, 0), od.description, o.currency, od.amount
+		FROM order_discounts od
+		JOIN orders o ON o.id = od.order_id
+		WHERE od.order_id IN (%s)
+		ORDER BY od.id
+	`, strings.Join(placeholders, ","))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order discounts: %w", err)
+	}
+	defer rows.Close()
+
+	discounts := []orderModel.OrderDiscount{}
+	for rows.Next() {
+		var discount orderModel.OrderDiscount
+		err := rows.Scan(
+			&discount.ID,
+			&discount.OrderID,
+			&discount.CouponID,
+			&discount.Code,
+			&discount.ProductID,
+			&discount.Description,
+			&discount.Amount.Currency, // discounts are in the order's currency
+			&discount.Amount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order discount: %w", err)
+		}
+		discounts = append(discounts, discount)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return discounts, nil
+}
@@ -0,0 +1,75 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// ExportOrderRows calls fn for every item of the orders created in [from, to),
+// ordered by order and item. Rows are read one at a time, so the export is
+// never held in memory. An error returned by fn stops the export.
+func (r *orderRepository) ExportOrderRows(ctx context.Context, from, to time.Time, fn func(row *orderModel.OrderExportRow) error) error {
+	query := `
+		SELECT o.id, COALESCE(o.checkout_id, ''), o.user_id, o.shop_id, o.status, o.currency,
+			o.currency, o.total_price, o.currency, o.discount_total, o.currency, o.tax_total, o.currency, o.shipping_fee,
+			COALESCE(o.coupon_code, ''), o.created_at,
+			oi.id, oi.product_id, oi.quantity, o.currency, oi.item_price,
+			TRIM(TRAILING '.' FROM TRIM(TRAILING '0' FROM oi.tax_rate)), o.currency, oi.tax_amount, oi.tax_inclusive
+		FROM orders o
+		JOIN order_items oi ON oi.order_id = o.id
+		WHERE o.created_at >= ? AND o.created_at < ?
+		ORDER BY o.id, oi.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query order export: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row orderModel.OrderExportRow
+		err := rows.Scan(
+			&row.OrderID,
+			&row.CheckoutID,
+			&row.UserID,
+			&row.ShopID,
+			&row.Status,
+			&row.Currency,
+			&row.OrderTotal.Currency, // scanned first, the amount's precision depends on it
+			&row.OrderTotal,
+			&row.DiscountTotal.Currency,
+			&row.DiscountTotal,
+			&row.TaxTotal.Currency,
+			&row.TaxTotal,
+			&row.ShippingFee.Currency,
+			&row.ShippingFee,
+			&row.CouponCode,
+			&row.OrderCreatedAt,
+			&row.ItemID,
+			&row.ProductID,
+			&row.Quantity,
+			&row.UnitPrice.Currency,
+			&row.UnitPrice,
+			&row.TaxRate,
+			&row.TaxAmount.Currency,
+			&row.TaxAmount,
+			&row.TaxInclusive,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan order export row: %w", err)
+		}
+
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return nil
+}
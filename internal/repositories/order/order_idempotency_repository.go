@@ -0,0 +1,136 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// InsertIdempotencyKey reserves an idempotency key and returns the reservation
+// ID, or 0 if the user already used the key
+func (r *orderRepository) InsertIdempotencyKey(ctx context.Context, key *orderModel.IdempotencyKey) (int64, error) {
+	query := `
+		INSERT IGNORE INTO order_idempotency_keys (
+		user_id,
+		idempotency_key,
+		request_hash,
+		created_at,
+		updated_at)
+		VALUES (?, ?, ?, NOW(), NOW())
+	`
+
+	result, err := r.db.ExecContext(ctx, query, key.UserID, key.Key, key.RequestHash)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert idempotency key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return 0, nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted idempotency key ID: %w", err)
+	}
+	return id, nil
+}
+
+// GetIdempotencyKey retrieves a stored idempotency key of a user
+func (r *orderRepository) GetIdempotencyKey(ctx context.Context, userID int, key string) (*orderModel.IdempotencyKey, error) {
+	query := `
+		SELECT id, user_id, idempotency_key, request_hash, COALESCE(order_id, 0), COALESCE(response_status, 0), response_body, created_at
+		FROM order_idempotency_keys
+		WHERE user_id = ? AND idempotency_key = ?
+	`
+
+	var record orderModel.IdempotencyKey
+	err := r.db.QueryRowContext(ctx, query, userID, key).Scan(
+		&record.ID,
+		&record.UserID,
+		&record.Key,
+		&record.RequestHash,
+		&record.OrderID,
+		&record.ResponseStatus,
+		&record.ResponseBody,
+		&record.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("idempotency key not found")
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return &record, nil
+}
+
+// BindIdempotencyKeyOrderTx records the order created under a reservation
+// within the order's transaction. It fails when the reservation no longer
+// exists, i.e. it was reclaimed by a retry, so a key never gets two orders.
+func (r *orderRepository) BindIdempotencyKeyOrderTx(ctx context.Context, tx *sql.Tx, id, orderID int64) error {
+	query := `
+		UPDATE order_idempotency_keys
+		SET order_id = ?, updated_at = NOW()
+		WHERE id = ? AND order_id IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, orderID, id)
+	if err != nil {
+		return fmt.Errorf("failed to bind idempotency key to order: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("idempotency key is still being processed by another request")
+	}
+
+	return nil
+}
+
+// UpdateIdempotencyKeyResponse stores the response of a completed request
+func (r *orderRepository) UpdateIdempotencyKeyResponse(ctx context.Context, key *orderModel.IdempotencyKey) error {
+	query := `
+		UPDATE order_idempotency_keys
+		SET order_id = ?, response_status = ?, response_body = ?, updated_at = NOW()
+		WHERE user_id = ? AND idempotency_key = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		key.OrderID,
+		key.ResponseStatus,
+		key.ResponseBody,
+		key.UserID,
+		key.Key,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update idempotency key response: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey removes a reservation that has no order. Keys that
+// created an order are kept so retries replay it.
+func (r *orderRepository) DeleteIdempotencyKey(ctx context.Context, id int64) error {
+	query := `
+		DELETE FROM order_idempotency_keys
+		WHERE id = ? AND order_id IS NULL AND response_status IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
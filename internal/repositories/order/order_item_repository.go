@@ -1,7 +1,9 @@
 package order
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"strings"
 
 	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
@@ -9,18 +11,22 @@ import (
 
 func (r *orderRepository) CreateOrderItem(tx *sql.Tx, req []orderModel.OrderItem) error {
 	placeholders := make([]string, 0, len(req))
-	args := make([]interface{}, 0, len(req)*5)
+	args := make([]interface{}, 0, len(req)*8)
 	for _, item := range req {
-		placeholders = append(placeholders, "(?, ?, ?, ?, NOW(), NOW())")
-		args = append(args, item.OrderID, item.ProductID, item.Quantity, item.Price)
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())")
+		args = append(args, item.OrderID, item.ProductID, item.VariantID, item.Quantity, item.Price, taxRateOrZero(item.TaxRate), item.TaxAmount, item.TaxInclusive)
 	}
 
 	query := `
 		INSERT INTO order_items (
 		order_id,
 		product_id,
+		variant_id,
 		quantity,
 		item_price,
+		tax_rate,
+		tax_amount,
+		tax_inclusive,
 		created_at,
 		updated_at)
 		VALUES ` + strings.Join(placeholders, ",") + `	
@@ -33,3 +39,67 @@ func (r *orderRepository) CreateOrderItem(tx *sql.Tx, req []orderModel.OrderItem
 
 	return nil
 }
+
+// GetOrderItemsByOrderIDs retrieves the items belonging to the given orders
+func (r *orderRepository) GetOrderItemsByOrderIDs(ctx context.Context, orderIDs []int64) ([]orderModel.OrderItem, error) {
+	if len(orderIDs) == 0 {
+		return []orderModel.OrderItem{}, nil
+	}
+
+	placeholders := make([]string, len(orderIDs))
+	args := make([]interface{}, len(orderIDs))
+	for i, id := range orderIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`
+		SELECT oi.id, oi.order_id, oi.product_id, oi.variant_id, oi.quantity, o.currency, oi.item_price,
+			TRIM(TRAILING '.' FROM TRIM(TRAILING '0' FROM oi.tax_rate)), o.currency, oi.tax_amount, oi.tax_inclusive
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE oi.order_id IN (%s)
+		ORDER BY oi.id
+	`, strings.Join(placeholders, ","))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []orderModel.OrderItem{}
+	for rows.Next() {
+		var item orderModel.OrderItem
+		err := rows.Scan(
+			&item.ID,
+			&item.OrderID,
+			&item.ProductID,
+			&item.VariantID,
+			&item.Quantity,
+			&item.Price.Currency, // item prices are in the order's currency
+			&item.Price,
+			&item.TaxRate,
+			&item.TaxAmount.Currency,
+			&item.TaxAmount,
+			&item.TaxInclusive,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return items, nil
+}
+
+// taxRateOrZero stores items without a tax rate as untaxed
+func taxRateOrZero(rate string) string {
+	if rate == "" {
+		return "0"
+	}
+	return rate
+}
@@ -0,0 +1,121 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// InsertOutboxMessageTx writes an event to the outbox within the caller's transaction
+func (r *orderRepository) InsertOutboxMessageTx(ctx context.Context, tx *sql.Tx, msg *orderModel.OutboxMessage) error {
+	query := `
+		INSERT INTO order_outbox (
+		order_id,
+		topic,
+		payload,
+		deliver_at,
+		status,
+		attempts,
+		available_at,
+		created_at)
+		VALUES (?, ?, ?, ?, ?, 0, NOW(), NOW())
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		msg.OrderID,
+		msg.Topic,
+		msg.Payload,
+		msg.DeliverAt,
+		orderModel.OutboxStatusPending,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// GetPendingOutboxMessagesForUpdateTx locks a batch of pending messages that are due,
+// skipping rows already locked by another relay
+func (r *orderRepository) GetPendingOutboxMessagesForUpdateTx(ctx context.Context, tx *sql.Tx, limit int) ([]orderModel.OutboxMessage, error) {
+	query := `
+		SELECT id, order_id, topic, payload, deliver_at, status, attempts, COALESCE(last_error, ''), available_at, created_at
+		FROM order_outbox
+		WHERE status = ? AND available_at <= NOW()
+		ORDER BY id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.QueryContext(ctx, query, orderModel.OutboxStatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []orderModel.OutboxMessage{}
+	for rows.Next() {
+		var msg orderModel.OutboxMessage
+		err := rows.Scan(
+			&msg.ID,
+			&msg.OrderID,
+			&msg.Topic,
+			&msg.Payload,
+			&msg.DeliverAt,
+			&msg.Status,
+			&msg.Attempts,
+			&msg.LastError,
+			&msg.AvailableAt,
+			&msg.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return messages, nil
+}
+
+// MarkOutboxMessageSentTx marks a message as published
+func (r *orderRepository) MarkOutboxMessageSentTx(ctx context.Context, tx *sql.Tx, id int64) error {
+	query := `
+		UPDATE order_outbox
+		SET status = ?, attempts = attempts + 1, last_error = NULL, sent_at = NOW()
+		WHERE id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, orderModel.OutboxStatusSent, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message as sent: %w", err)
+	}
+
+	return nil
+}
+
+// MarkOutboxMessageRetryTx records a failed publish attempt and when to try again.
+// The status is set to failed once no more retries should be made.
+func (r *orderRepository) MarkOutboxMessageRetryTx(ctx context.Context, tx *sql.Tx, id int64, status, lastError string, availableAt time.Time) error {
+	if len(lastError) > 500 {
+		lastError = lastError[:500]
+	}
+
+	query := `
+		UPDATE order_outbox
+		SET status = ?, attempts = attempts + 1, last_error = ?, available_at = ?
+		WHERE id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, status, lastError, availableAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update outbox message: %w", err)
+	}
+
+	return nil
+}
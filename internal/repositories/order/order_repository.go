@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
 )
@@ -13,10 +15,52 @@ import (
 type OrderRepository interface {
 	CreateOrder(tx *sql.Tx, req *orderModel.CreateOrderRequest) (int64, error)
 	GetByID(ctx context.Context, id int) (*orderModel.Order, error)
+	List(ctx context.Context, req *orderModel.OrderListRequest) (*orderModel.OrderListResponse, error)
 	BeginTx(ctx context.Context) (*sql.Tx, error)
 	CreateOrderItem(tx *sql.Tx, req []orderModel.OrderItem) error
+	GetOrderItemsByOrderIDs(ctx context.Context, orderIDs []int64) ([]orderModel.OrderItem, error)
 	GetByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int) (*orderModel.Order, error)
 	UpdateOrderStatusTx(ctx context.Context, tx *sql.Tx, id int64, status string) error
+	GetExpiredPendingOrderForUpdateTx(ctx context.Context, tx *sql.Tx, excludeIDs []int64) (*orderModel.Order, error)
+	InsertStatusHistoryTx(ctx context.Context, tx *sql.Tx, history *orderModel.OrderStatusHistory) error
+	GetStatusHistoryByOrderID(ctx context.Context, orderID int64) ([]orderModel.OrderStatusHistory, error)
+	InsertIdempotencyKey(ctx context.Context, key *orderModel.IdempotencyKey) (int64, error)
+	GetIdempotencyKey(ctx context.Context, userID int, key string) (*orderModel.IdempotencyKey, error)
+	BindIdempotencyKeyOrderTx(ctx context.Context, tx *sql.Tx, id, orderID int64) error
+	UpdateIdempotencyKeyResponse(ctx context.Context, key *orderModel.IdempotencyKey) error
+	DeleteIdempotencyKey(ctx context.Context, id int64) error
+	InsertOutboxMessageTx(ctx context.Context, tx *sql.Tx, msg *orderModel.OutboxMessage) error
+	GetPendingOutboxMessagesForUpdateTx(ctx context.Context, tx *sql.Tx, limit int) ([]orderModel.OutboxMessage, error)
+	MarkOutboxMessageSentTx(ctx context.Context, tx *sql.Tx, id int64) error
+	MarkOutboxMessageRetryTx(ctx context.Context, tx *sql.Tx, id int64, status, lastError string, availableAt time.Time) error
+	InsertCompensation(ctx context.Context, compensation *orderModel.Compensation) error
+	GetPendingCompensationsForUpdateTx(ctx context.Context, tx *sql.Tx, limit int) ([]orderModel.Compensation, error)
+	InsertCompensationTx(ctx context.Context, tx *sql.Tx, compensation *orderModel.Compensation) (int64, error)
+	GetPendingCompensationForUpdateTx(ctx context.Context, tx *sql.Tx, id int64) (*orderModel.Compensation, error)
+	MarkCompensationDoneTx(ctx context.Context, tx *sql.Tx, id int64) error
+	MarkCompensationRetryTx(ctx context.Context, tx *sql.Tx, id int64, lastError string, availableAt time.Time) error
+	CreateCoupon(ctx context.Context, coupon *orderModel.Coupon) (int64, error)
+	GetCouponByCodeForUpdateTx(ctx context.Context, tx *sql.Tx, code string) (*orderModel.Coupon, error)
+	CountCouponRedemptionsByUserTx(ctx context.Context, tx *sql.Tx, couponID int64, userID int) (int, error)
+	InsertCouponRedemptionTx(ctx context.Context, tx *sql.Tx, redemption *orderModel.CouponRedemption) error
+	ReleaseCouponRedemptionsTx(ctx context.Context, tx *sql.Tx, orderID int64) error
+	InsertOrderDiscountsTx(ctx context.Context, tx *sql.Tx, discounts []orderModel.OrderDiscount) error
+	GetOrderDiscountsByOrderIDs(ctx context.Context, orderIDs []int64) ([]orderModel.OrderDiscount, error)
+	InsertShipmentTx(ctx context.Context, tx *sql.Tx, shipment *orderModel.Shipment) (int64, error)
+	GetShippedQuantitiesTx(ctx context.Context, tx *sql.Tx, orderID int64) (map[int64]int, error)
+	GetShipmentForUpdateTx(ctx context.Context, tx *sql.Tx, orderID, shipmentID int64) (*orderModel.Shipment, error)
+	MarkShipmentDeliveredTx(ctx context.Context, tx *sql.Tx, shipmentID int64, deliveredAt time.Time) error
+	CountUndeliveredShipmentsTx(ctx context.Context, tx *sql.Tx, orderID int64) (int, error)
+	GetShipmentsByOrderIDs(ctx context.Context, orderIDs []int64) ([]orderModel.Shipment, error)
+	InsertReturnTx(ctx context.Context, tx *sql.Tx, orderReturn *orderModel.OrderReturn) (int64, error)
+	GetReturnedQuantitiesTx(ctx context.Context, tx *sql.Tx, orderID int64, statuses ...string) (map[int64]int, error)
+	GetReturnForUpdateTx(ctx context.Context, tx *sql.Tx, orderID, returnID int64) (*orderModel.OrderReturn, error)
+	ResolveReturnTx(ctx context.Context, tx *sql.Tx, returnID int64, status, note string, resolvedAt time.Time) error
+	InsertRefundTx(ctx context.Context, tx *sql.Tx, refund *orderModel.Refund) (int64, error)
+	GetReturnsByOrderIDs(ctx context.Context, orderIDs []int64) ([]orderModel.OrderReturn, error)
+	GetRefundsByOrderIDs(ctx context.Context, orderIDs []int64) ([]orderModel.Refund, error)
+	MarkOrderAcceptedTx(ctx context.Context, tx *sql.Tx, id int64, acceptedAt time.Time) error
+	ExportOrderRows(ctx context.Context, from, to time.Time, fn func(row *orderModel.OrderExportRow) error) error
 }
 
 // orderRepository implements OrderRepository
@@ -38,24 +82,52 @@ func (r *orderRepository) CreateOrder(tx *sql.Tx, req *orderModel.CreateOrderReq
 		return 0, fmt.Errorf("failed to marshal order data: %w", err)
 	}
 
+	var shippingAddressJSON []byte
+	if req.ShippingAddress != nil {
+		shippingAddressJSON, err = json.Marshal(req.ShippingAddress)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal shipping address: %w", err)
+		}
+	}
+
 	// Begin transaction for atomic order creation
 	query := `
 		INSERT INTO orders (
 		user_id, 
 		shop_id, 
+		checkout_id,
 		total_price,
+		currency,
+		shop_currency,
+		exchange_rate,
+		coupon_code,
+		discount_total,
+		tax_total,
+		address_id,
+		shipping_address,
+		shipping_fee,
 		status, 
 		order_data, 
 		created_at, 
 		updated_at,
 		expires_at)
-		VALUES (?, ?, ?, ?, ?, NOW(), NOW(), ?)
+		VALUES (?, ?, NULLIF(?, ''), ?, ?, ?, ?, NULLIF(?, ''), ?, ?, NULLIF(?, 0), ?, ?, ?, ?, NOW(), NOW(), ?)
 	`
 
 	result, err := tx.Exec(query,
 		req.UserID,
 		req.ShopID,
+		req.CheckoutID,
 		req.TotalPrice,
+		req.TotalPrice.Currency,
+		req.ShopCurrency,
+		req.ExchangeRate,
+		req.CouponCode,
+		req.DiscountTotal,
+		req.TaxTotal,
+		req.AddressID,
+		shippingAddressJSON,
+		req.ShippingFee,
 		orderModel.OrderStatusPending,
 		orderDataJSON,
 		req.ExpiresAt,
@@ -74,42 +146,168 @@ func (r *orderRepository) CreateOrder(tx *sql.Tx, req *orderModel.CreateOrderReq
 	return orderID, nil
 }
 
-// GetByID retrieves an order by ID
-func (r *orderRepository) GetByID(ctx context.Context, id int) (*orderModel.Order, error) {
-	query := `
-		SELECT id, user_id, shop_id, total_price, status, order_data, created_at, updated_at
-		FROM orders
-		WHERE id = ?
-	`
+// orderColumns selects an order in the order scanOrder reads it
+const orderColumns = `id, user_id, shop_id, currency, total_price, shop_currency, TRIM(TRAILING '.' FROM TRIM(TRAILING '0' FROM exchange_rate)), COALESCE(coupon_code, ''), currency, discount_total, currency, tax_total, COALESCE(address_id, 0), shipping_address, currency, shipping_fee, status, order_data, created_at, updated_at, expires_at, COALESCE(checkout_id, ''), accepted_at`
 
+// scanOrder scans an orders row selected with orderColumns. It returns
+// sql.ErrNoRows as is so callers can tell a missing order apart.
+func scanOrder(row rowScanner) (*orderModel.Order, error) {
 	var order orderModel.Order
 	var orderDataJSON []byte
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	var shippingAddressJSON []byte
+	var acceptedAt sql.NullTime
+	err := row.Scan(
 		&order.ID,
 		&order.UserID,
 		&order.ShopID,
+		&order.TotalPrice.Currency, // scanned first, the price's precision depends on it
 		&order.TotalPrice,
+		&order.ShopCurrency,
+		&order.ExchangeRate,
+		&order.CouponCode,
+		&order.DiscountTotal.Currency,
+		&order.DiscountTotal,
+		&order.TaxTotal.Currency,
+		&order.TaxTotal,
+		&order.AddressID,
+		&shippingAddressJSON,
+		&order.ShippingFee.Currency,
+		&order.ShippingFee,
 		&order.Status,
 		&orderDataJSON,
 		&order.CreatedAt,
 		&order.UpdatedAt,
+		&order.ExpiresAt,
+		&order.CheckoutID,
+		&acceptedAt,
 	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan order: %w", err)
+	}
 
+	// Decode order data, tolerating other schema versions
+	order.OrderData, err = orderModel.DecodeOrderData(orderDataJSON)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("order not found")
-		}
-		return nil, fmt.Errorf("failed to get order: %w", err)
+		return nil, err
 	}
 
-	// Unmarshal order data
-	if err := json.Unmarshal(orderDataJSON, &order.OrderData); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal order data: %w", err)
+	order.ShippingAddress, err = orderModel.UnmarshalShippingAddress(shippingAddressJSON)
+	if err != nil {
+		return nil, err
+	}
+	if acceptedAt.Valid {
+		order.AcceptedAt = &acceptedAt.Time
 	}
 
 	return &order, nil
 }
 
+// GetByID retrieves an order by ID
+func (r *orderRepository) GetByID(ctx context.Context, id int) (*orderModel.Order, error) {
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE id = ?
+	`
+
+	order, err := scanOrder(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("order not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// List retrieves orders with filtering and pagination
+func (r *orderRepository) List(ctx context.Context, req *orderModel.OrderListRequest) (*orderModel.OrderListResponse, error) {
+	countQuery := "SELECT COUNT(*) FROM orders WHERE 1=1"
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE 1=1
+	`
+
+	args := []interface{}{}
+	conditions := []string{}
+
+	// Add filters
+	if req.UserID > 0 {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, req.UserID)
+	}
+	if req.ShopID > 0 {
+		conditions = append(conditions, "shop_id = ?")
+		args = append(args, req.ShopID)
+	}
+	if req.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, req.Status)
+	}
+	if !req.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, req.From)
+	}
+	if !req.To.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, req.To)
+	}
+
+	// Apply conditions
+	if len(conditions) > 0 {
+		conditionStr := " AND " + strings.Join(conditions, " AND ")
+		countQuery += conditionStr
+		query += conditionStr
+	}
+
+	// Get total count
+	var total int
+	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total count: %w", err)
+	}
+
+	// Add pagination
+	query += " ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?"
+	offset := (req.Page - 1) * req.Limit
+	args = append(args, req.Limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []orderModel.Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	// Calculate pages
+	pages := (total + req.Limit - 1) / req.Limit
+
+	return &orderModel.OrderListResponse{
+		Orders: orders,
+		Total:  total,
+		Page:   req.Page,
+		Limit:  req.Limit,
+		Pages:  pages,
+	}, nil
+}
+
 func (r *orderRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -120,39 +318,21 @@ func (r *orderRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
 
 func (r *orderRepository) GetByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int) (*orderModel.Order, error) {
 	query := `
-		SELECT id, user_id, shop_id, total_price, status, order_data, created_at, updated_at, expires_at
+		SELECT ` + orderColumns + `
 		FROM orders
 		WHERE id = ?
 		FOR UPDATE
 	`
 
-	var order orderModel.Order
-	var orderDataJSON []byte
-	err := tx.QueryRowContext(ctx, query, id).Scan(
-		&order.ID,
-		&order.UserID,
-		&order.ShopID,
-		&order.TotalPrice,
-		&order.Status,
-		&orderDataJSON,
-		&order.CreatedAt,
-		&order.UpdatedAt,
-		&order.ExpiresAt,
-	)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("order not found")
-		}
-		return nil, fmt.Errorf("failed to get order: %w", err)
+	order, err := scanOrder(tx.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("order not found")
 	}
-
-	// Unmarshal order data
-	if err := json.Unmarshal(orderDataJSON, &order.OrderData); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal order data: %w", err)
+	if err != nil {
+		return nil, err
 	}
 
-	return &order, nil
+	return order, nil
 }
 
 func (r *orderRepository) UpdateOrderStatusTx(ctx context.Context, tx *sql.Tx, id int64, status string) error {
@@ -169,3 +349,54 @@ func (r *orderRepository) UpdateOrderStatusTx(ctx context.Context, tx *sql.Tx, i
 
 	return nil
 }
+
+// MarkOrderAcceptedTx records when the shop accepted an order
+func (r *orderRepository) MarkOrderAcceptedTx(ctx context.Context, tx *sql.Tx, id int64, acceptedAt time.Time) error {
+	query := `
+		UPDATE orders
+		SET accepted_at = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, acceptedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark order accepted: %w", err)
+	}
+
+	return nil
+}
+
+// GetExpiredPendingOrderForUpdateTx locks the oldest pending order past its expiry,
+// skipping orders already locked by another transaction and the excluded ones.
+// It returns nil when none is due.
+func (r *orderRepository) GetExpiredPendingOrderForUpdateTx(ctx context.Context, tx *sql.Tx, excludeIDs []int64) (*orderModel.Order, error) {
+	args := []interface{}{orderModel.OrderStatusPending}
+	exclude := ""
+	if len(excludeIDs) > 0 {
+		placeholders := make([]string, len(excludeIDs))
+		for i, id := range excludeIDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		exclude = fmt.Sprintf("AND id NOT IN (%s)", strings.Join(placeholders, ","))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM orders
+		WHERE status = ? AND expires_at <= NOW() %s
+		ORDER BY expires_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`, orderColumns, exclude)
+
+	order, err := scanOrder(tx.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
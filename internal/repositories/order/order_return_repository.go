@@ -0,0 +1,376 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// InsertReturnTx records a return request with its items and returns its ID
+func (r *orderRepository) InsertReturnTx(ctx context.Context, tx *sql.Tx, orderReturn *orderModel.OrderReturn) (int64, error) {
+	query := `
+		INSERT INTO order_returns (order_id, user_id, status, reason, created_at, updated_at)
+		VALUES (?, ?, ?, ?, NOW(), NOW())
+	`
+
+	result, err := tx.ExecContext(ctx, query,
+		orderReturn.OrderID,
+		orderReturn.UserID,
+		orderReturn.Status,
+		orderReturn.Reason,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create return: %w", err)
+	}
+
+	returnID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted return ID: %w", err)
+	}
+
+	placeholders := make([]string, 0, len(orderReturn.Items))
+	args := make([]interface{}, 0, len(orderReturn.Items)*3)
+	for _, item := range orderReturn.Items {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, returnID, item.OrderItemID, item.Quantity)
+	}
+
+	query = `INSERT INTO order_return_items (return_id, order_item_id, quantity) VALUES ` + strings.Join(placeholders, ",")
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create return items: %w", err)
+	}
+
+	return returnID, nil
+}
+
+// GetReturnedQuantitiesTx returns the quantity of each order item in the order's
+// returns with one of the given statuses, by order item ID
+func (r *orderRepository) GetReturnedQuantitiesTx(ctx context.Context, tx *sql.Tx, orderID int64, statuses ...string) (map[int64]int, error) {
+	returned := make(map[int64]int)
+	if len(statuses) == 0 {
+		return returned, nil
+	}
+
+	placeholders := make([]string, len(statuses))
+	args := make([]interface{}, 0, len(statuses)+1)
+	args = append(args, orderID)
+	for i, status := range statuses {
+		placeholders[i] = "?"
+		args = append(args, status)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT ri.order_item_id, SUM(ri.quantity)
+		FROM order_return_items ri
+		JOIN order_returns rt ON rt.id = ri.return_id
+		WHERE rt.order_id = ? AND rt.status IN (%s)
+		GROUP BY ri.order_item_id
+	`, strings.Join(placeholders, ","))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get returned quantities: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderItemID int64
+		var quantity int
+		if err := rows.Scan(&orderItemID, &quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan returned quantity: %w", err)
+		}
+		returned[orderItemID] = quantity
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return returned, nil
+}
+
+// GetReturnForUpdateTx locks a return of an order and loads its items
+func (r *orderRepository) GetReturnForUpdateTx(ctx context.Context, tx *sql.Tx, orderID, returnID int64) (*orderModel.OrderReturn, error) {
+	query := `
+		SELECT id, order_id, user_id, status, reason, resolution_note, resolved_at, created_at, updated_at
+		FROM order_returns
+		WHERE id = ? AND order_id = ?
+		FOR UPDATE
+	`
+
+	orderReturn, err := scanReturn(tx.QueryRowContext(ctx, query, returnID, orderID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("return not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	itemQuery := `
+		SELECT ri.id, ri.return_id, ri.order_item_id, oi.product_id, oi.variant_id, ri.quantity
+		FROM order_return_items ri
+		JOIN order_items oi ON oi.id = ri.order_item_id
+		WHERE ri.return_id = ?
+		ORDER BY ri.id
+	`
+
+	rows, err := tx.QueryContext(ctx, itemQuery, orderReturn.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get return items: %w", err)
+	}
+	defer rows.Close()
+
+	orderReturn.Items = []orderModel.ReturnItem{}
+	for rows.Next() {
+		item, err := scanReturnItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		orderReturn.Items = append(orderReturn.Items, *item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return orderReturn, nil
+}
+
+// ResolveReturnTx records the seller's decision on a return
+func (r *orderRepository) ResolveReturnTx(ctx context.Context, tx *sql.Tx, returnID int64, status, note string, resolvedAt time.Time) error {
+	query := `UPDATE order_returns SET status = ?, resolution_note = ?, resolved_at = ?, updated_at = NOW() WHERE id = ?`
+
+	_, err := tx.ExecContext(ctx, query, status, note, resolvedAt, returnID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve return: %w", err)
+	}
+
+	return nil
+}
+
+// InsertRefundTx records a refund and returns its ID
+func (r *orderRepository) InsertRefundTx(ctx context.Context, tx *sql.Tx, refund *orderModel.Refund) (int64, error) {
+	query := `
+		INSERT INTO refunds (return_id, order_id, amount, currency, status, created_at)
+		VALUES (NULLIF(?, 0), ?, ?, ?, ?, ?)
+	`
+
+	result, err := tx.ExecContext(ctx, query,
+		refund.ReturnID,
+		refund.OrderID,
+		refund.Amount,
+		refund.Amount.Currency,
+		refund.Status,
+		refund.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	refundID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted refund ID: %w", err)
+	}
+
+	return refundID, nil
+}
+
+// GetReturnsByOrderIDs retrieves the returns of the given orders with their items and refunds
+func (r *orderRepository) GetReturnsByOrderIDs(ctx context.Context, orderIDs []int64) ([]orderModel.OrderReturn, error) {
+	if len(orderIDs) == 0 {
+		return []orderModel.OrderReturn{}, nil
+	}
+
+	placeholders := make([]string, len(orderIDs))
+	args := make([]interface{}, len(orderIDs))
+	for i, id := range orderIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, order_id, user_id, status, reason, resolution_note, resolved_at, created_at, updated_at
+		FROM order_returns
+		WHERE order_id IN (%s)
+		ORDER BY id
+	`, strings.Join(placeholders, ","))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get returns: %w", err)
+	}
+	defer rows.Close()
+
+	returns := []orderModel.OrderReturn{}
+	returnIndex := make(map[int64]int)
+	for rows.Next() {
+		orderReturn, err := scanReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		orderReturn.Items = []orderModel.ReturnItem{}
+		returnIndex[orderReturn.ID] = len(returns)
+		returns = append(returns, *orderReturn)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	if len(returns) == 0 {
+		return returns, nil
+	}
+
+	itemQuery := fmt.Sprintf(`
+		SELECT ri.id, ri.return_id, ri.order_item_id, oi.product_id, oi.variant_id, ri.quantity
+		FROM order_return_items ri
+		JOIN order_returns rt ON rt.id = ri.return_id
+		JOIN order_items oi ON oi.id = ri.order_item_id
+		WHERE rt.order_id IN (%s)
+		ORDER BY ri.id
+	`, strings.Join(placeholders, ","))
+
+	itemRows, err := r.db.QueryContext(ctx, itemQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get return items: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		item, err := scanReturnItem(itemRows)
+		if err != nil {
+			return nil, err
+		}
+		if i, exists := returnIndex[item.ReturnID]; exists {
+			returns[i].Items = append(returns[i].Items, *item)
+		}
+	}
+	if err = itemRows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	refundQuery := fmt.Sprintf(`
+		SELECT id, return_id, order_id, currency, amount, status, created_at
+		FROM refunds
+		WHERE order_id IN (%s) AND return_id IS NOT NULL
+	`, strings.Join(placeholders, ","))
+
+	refundRows, err := r.db.QueryContext(ctx, refundQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunds: %w", err)
+	}
+	defer refundRows.Close()
+
+	for refundRows.Next() {
+		var refund orderModel.Refund
+		err := refundRows.Scan(
+			&refund.ID,
+			&refund.ReturnID,
+			&refund.OrderID,
+			&refund.Amount.Currency,
+			&refund.Amount,
+			&refund.Status,
+			&refund.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan refund: %w", err)
+		}
+		if i, exists := returnIndex[refund.ReturnID]; exists {
+			returns[i].Refund = &refund
+		}
+	}
+	if err = refundRows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return returns, nil
+}
+
+// scanReturn scans an order_returns row without its items
+func scanReturn(row rowScanner) (*orderModel.OrderReturn, error) {
+	var orderReturn orderModel.OrderReturn
+	var resolvedAt sql.NullTime
+	err := row.Scan(
+		&orderReturn.ID,
+		&orderReturn.OrderID,
+		&orderReturn.UserID,
+		&orderReturn.Status,
+		&orderReturn.Reason,
+		&orderReturn.ResolutionNote,
+		&resolvedAt,
+		&orderReturn.CreatedAt,
+		&orderReturn.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan return: %w", err)
+	}
+	if resolvedAt.Valid {
+		orderReturn.ResolvedAt = &resolvedAt.Time
+	}
+
+	return &orderReturn, nil
+}
+
+// scanReturnItem scans an order_return_items row joined with its order item's product
+func scanReturnItem(row rowScanner) (*orderModel.ReturnItem, error) {
+	var item orderModel.ReturnItem
+	if err := row.Scan(&item.ID, &item.ReturnID, &item.OrderItemID, &item.ProductID, &item.VariantID, &item.Quantity); err != nil {
+		return nil, fmt.Errorf("failed to scan return item: %w", err)
+	}
+	return &item, nil
+}
+
+// GetRefundsByOrderIDs retrieves every refund of the given orders
+func (r *orderRepository) GetRefundsByOrderIDs(ctx context.Context, orderIDs []int64) ([]orderModel.Refund, error) {
+	if len(orderIDs) == 0 {
+		return []orderModel.Refund{}, nil
+	}
+
+	placeholders := make([]string, len(orderIDs))
+	args := make([]interface{}, len(orderIDs))
+	for i, id := range orderIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, COALESCE(return_id, 0), order_id, currency, amount, status, created_at
+		FROM refunds
+		WHERE order_id IN (%s)
+		ORDER BY id
+	`, strings.Join(placeholders, ","))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunds: %w", err)
+	}
+	defer rows.Close()
+
+	refunds := []orderModel.Refund{}
+	for rows.Next() {
+		var refund orderModel.Refund
+		err := rows.Scan(
+			&refund.ID,
+			&refund.ReturnID,
+			&refund.OrderID,
+			&refund.Amount.Currency,
+			&refund.Amount,
+			&refund.Status,
+			&refund.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan refund: %w", err)
+		}
+		refunds = append(refunds, refund)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return refunds, nil
+}
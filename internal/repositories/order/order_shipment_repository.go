@@ -0,0 +1,236 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// InsertShipmentTx records a shipment with its items and returns its ID
+func (r *orderRepository) InsertShipmentTx(ctx context.Context, tx *sql.Tx, shipment *orderModel.Shipment) (int64, error) {
+	query := `
+		INSERT INTO shipments (order_id, carrier, tracking_number, status, shipped_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, NOW(), NOW())
+	`
+
+	result, err := tx.ExecContext(ctx, query,
+		shipment.OrderID,
+		shipment.Carrier,
+		shipment.TrackingNumber,
+		shipment.Status,
+		shipment.ShippedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create shipment: %w", err)
+	}
+
+	shipmentID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted shipment ID: %w", err)
+	}
+
+	placeholders := make([]string, 0, len(shipment.Items))
+	args := make([]interface{}, 0, len(shipment.Items)*3)
+	for _, item := range shipment.Items {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, shipmentID, item.OrderItemID, item.Quantity)
+	}
+
+	query = `INSERT INTO shipment_items (shipment_id, order_item_id, quantity) VALUES ` + strings.Join(placeholders, ",")
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create shipment items: %w", err)
+	}
+
+	return shipmentID, nil
+}
+
+// GetShippedQuantitiesTx returns the quantity of each order item already shipped, by order item ID
+func (r *orderRepository) GetShippedQuantitiesTx(ctx context.Context, tx *sql.Tx, orderID int64) (map[int64]int, error) {
+	query := `
+		SELECT si.order_item_id, SUM(si.quantity)
+		FROM shipment_items si
+		JOIN shipments s ON s.id = si.shipment_id
+		WHERE s.order_id = ?
+		GROUP BY si.order_item_id
+	`
+
+	rows, err := tx.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipped quantities: %w", err)
+	}
+	defer rows.Close()
+
+	shipped := make(map[int64]int)
+	for rows.Next() {
+		var orderItemID int64
+		var quantity int
+		if err := rows.Scan(&orderItemID, &quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan shipped quantity: %w", err)
+		}
+		shipped[orderItemID] = quantity
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return shipped, nil
+}
+
+// GetShipmentForUpdateTx locks a shipment of an order
+func (r *orderRepository) GetShipmentForUpdateTx(ctx context.Context, tx *sql.Tx, orderID, shipmentID int64) (*orderModel.Shipment, error) {
+	query := `
+		SELECT id, order_id, carrier, tracking_number, status, shipped_at, delivered_at, created_at, updated_at
+		FROM shipments
+		WHERE id = ? AND order_id = ?
+		FOR UPDATE
+	`
+
+	shipment, err := scanShipment(tx.QueryRowContext(ctx, query, shipmentID, orderID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("shipment not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return shipment, nil
+}
+
+// MarkShipmentDeliveredTx sets a shipment as delivered
+func (r *orderRepository) MarkShipmentDeliveredTx(ctx context.Context, tx *sql.Tx, shipmentID int64, deliveredAt time.Time) error {
+	query := `UPDATE shipments SET status = ?, delivered_at = ?, updated_at = NOW() WHERE id = ?`
+
+	_, err := tx.ExecContext(ctx, query, orderModel.ShipmentStatusDelivered, deliveredAt, shipmentID)
+	if err != nil {
+		return fmt.Errorf("failed to mark shipment delivered: %w", err)
+	}
+
+	return nil
+}
+
+// CountUndeliveredShipmentsTx counts the shipments of an order still in transit
+func (r *orderRepository) CountUndeliveredShipmentsTx(ctx context.Context, tx *sql.Tx, orderID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM shipments WHERE order_id = ? AND status <> ?`
+
+	var count int
+	err := tx.QueryRowContext(ctx, query, orderID, orderModel.ShipmentStatusDelivered).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count undelivered shipments: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetShipmentsByOrderIDs retrieves the shipments of the given orders with their items
+func (r *orderRepository) GetShipmentsByOrderIDs(ctx context.Context, orderIDs []int64) ([]orderModel.Shipment, error) {
+	if len(orderIDs) == 0 {
+		return []orderModel.Shipment{}, nil
+	}
+
+	placeholders := make([]string, len(orderIDs))
+	args := make([]interface{}, len(orderIDs))
+	for i, id := range orderIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, order_id, carrier, tracking_number, status, shipped_at, delivered_at, created_at, updated_at
+		FROM shipments
+		WHERE order_id IN (%s)
+		ORDER BY id
+	`, strings.Join(placeholders, ","))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipments: %w", err)
+	}
+	defer rows.Close()
+
+	shipments := []orderModel.Shipment{}
+	shipmentIndex := make(map[int64]int)
+	for rows.Next() {
+		shipment, err := scanShipment(rows)
+		if err != nil {
+			return nil, err
+		}
+		shipment.Items = []orderModel.ShipmentItem{}
+		shipmentIndex[shipment.ID] = len(shipments)
+		shipments = append(shipments, *shipment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	if len(shipments) == 0 {
+		return shipments, nil
+	}
+
+	itemQuery := fmt.Sprintf(`
+		SELECT si.id, si.shipment_id, si.order_item_id, oi.product_id, si.quantity
+		FROM shipment_items si
+		JOIN shipments s ON s.id = si.shipment_id
+		JOIN order_items oi ON oi.id = si.order_item_id
+		WHERE s.order_id IN (%s)
+		ORDER BY si.id
+	`, strings.Join(placeholders, ","))
+
+	itemRows, err := r.db.QueryContext(ctx, itemQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipment items: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var item orderModel.ShipmentItem
+		if err := itemRows.Scan(&item.ID, &item.ShipmentID, &item.OrderItemID, &item.ProductID, &item.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan shipment item: %w", err)
+		}
+		if i, exists := shipmentIndex[item.ShipmentID]; exists {
+			shipments[i].Items = append(shipments[i].Items, item)
+		}
+	}
+	if err = itemRows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return shipments, nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanShipment scans a shipments row without its items
+func scanShipment(row rowScanner) (*orderModel.Shipment, error) {
+	var shipment orderModel.Shipment
+	var deliveredAt sql.NullTime
+	err := row.Scan(
+		&shipment.ID,
+		&shipment.OrderID,
+		&shipment.Carrier,
+		&shipment.TrackingNumber,
+		&shipment.Status,
+		&shipment.ShippedAt,
+		&deliveredAt,
+		&shipment.CreatedAt,
+		&shipment.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan shipment: %w", err)
+	}
+	if deliveredAt.Valid {
+		shipment.DeliveredAt = &deliveredAt.Time
+	}
+
+	return &shipment, nil
+}
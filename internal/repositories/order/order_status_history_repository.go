@@ -0,0 +1,75 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// InsertStatusHistoryTx records an order status change within a transaction
+func (r *orderRepository) InsertStatusHistoryTx(ctx context.Context, tx *sql.Tx, history *orderModel.OrderStatusHistory) error {
+	query := `
+		INSERT INTO order_status_history (
+		order_id,
+		from_status,
+		to_status,
+		changed_by,
+		reason,
+		created_at)
+		VALUES (?, NULLIF(?, ''), ?, ?, NULLIF(?, ''), NOW())
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		history.OrderID,
+		history.FromStatus,
+		history.ToStatus,
+		history.ChangedBy,
+		history.Reason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert order status history: %w", err)
+	}
+
+	return nil
+}
+
+// GetStatusHistoryByOrderID retrieves the status changes of an order, oldest first
+func (r *orderRepository) GetStatusHistoryByOrderID(ctx context.Context, orderID int64) ([]orderModel.OrderStatusHistory, error) {
+	query := `
+		SELECT id, order_id, COALESCE(from_status, ''), to_status, changed_by, COALESCE(reason, ''), created_at
+		FROM order_status_history
+		WHERE order_id = ?
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order status history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []orderModel.OrderStatusHistory{}
+	for rows.Next() {
+		var entry orderModel.OrderStatusHistory
+		err := rows.Scan(
+			&entry.ID,
+			&entry.OrderID,
+			&entry.FromStatus,
+			&entry.ToStatus,
+			&entry.ChangedBy,
+			&entry.Reason,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order status history: %w", err)
+		}
+		history = append(history, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return history, nil
+}
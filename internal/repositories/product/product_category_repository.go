@@ -0,0 +1,273 @@
+package product
+
+import (
+	"fmt"
+	"strings"
+
+	productModel "github.com/Christyan39/test-eDot/internal/models/product"
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// Facets whose own filter listConditions can leave out
+const (
+	facetNone     = ""
+	facetCategory = "category"
+	facetPrice    = "price"
+	facetShop     = "shop"
+)
+
+// listConditions builds the filters of a product listing, leaving out the
+// filter on the given facet
+func listConditions(req *productModel.ProductListRequest, without string) ([]string, []interface{}) {
+	args := []interface{}{}
+	conditions := []string{}
+
+	if req.ShopID > 0 && without != facetShop {
+		conditions = append(conditions, "shop_id = ?")
+		args = append(args, req.ShopID)
+	}
+	if req.Currency != "" {
+		conditions = append(conditions, "currency = ?")
+		args = append(args, req.Currency)
+	}
+	if req.MinPrice.IsPositive() && without != facetPrice {
+		conditions = append(conditions, "price >= ?")
+		args = append(args, req.MinPrice)
+	}
+	if req.MaxPrice.IsPositive() && without != facetPrice {
+		conditions = append(conditions, "price <= ?")
+		args = append(args, req.MaxPrice)
+	}
+	if req.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, req.Status)
+	}
+	if req.Search != "" {
+		conditions = append(conditions, "(name LIKE ? OR description LIKE ?)")
+		searchParam := "%" + req.Search + "%"
+		args = append(args, searchParam, searchParam)
+	}
+	if len(req.IDs) > 0 {
+		placeholders := make([]string, len(req.IDs))
+		for i, id := range req.IDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		conditions = append(conditions, fmt.Sprintf("id IN (%s)", strings.Join(placeholders, ",")))
+	}
+	if len(req.CategoryIDs) > 0 && without != facetCategory {
+		placeholders := make([]string, len(req.CategoryIDs))
+		for i, id := range req.CategoryIDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		conditions = append(conditions, fmt.Sprintf("category_id IN (%s)", strings.Join(placeholders, ",")))
+	}
+
+	return conditions, args
+}
+
+// facetWhere returns the WHERE clause of a facet count
+func facetWhere(req *productModel.ProductListRequest, facet string) (string, []interface{}) {
+	conditions, args := listConditions(req, facet)
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// CountFacets counts the products matching a listing per category, price
+// bucket and shop. Category counts are of the products directly in each
+// category; rolling them up the tree is left to the caller.
+func (r *productRepository) CountFacets(req *productModel.ProductListRequest) (*productModel.ProductFacets, error) {
+	facets := &productModel.ProductFacets{
+		Categories:   []productModel.CategoryFacet{},
+		PriceBuckets: []productModel.PriceFacet{},
+		Shops:        []productModel.ShopFacet{},
+	}
+
+	where, args := facetWhere(req, facetCategory)
+	rows, err := r.db.Query(fmt.Sprintf(`
+		SELECT category_id, COUNT(*)
+		FROM products
+		%s
+		GROUP BY category_id
+		HAVING category_id IS NOT NULL
+	`, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count products per category: %w", err)
+	}
+	for rows.Next() {
+		var facet productModel.CategoryFacet
+		if err := rows.Scan(&facet.CategoryID, &facet.Count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan category facet: %w", err)
+		}
+		facets.Categories = append(facets.Categories, facet)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	// Buckets span an order of magnitude of the price, taken from the number of
+	// digits of its whole part; the first one starts at 0
+	where, args = facetWhere(req, facetPrice)
+	rows, err = r.db.Query(fmt.Sprintf(`
+		SELECT currency, CHAR_LENGTH(FLOOR(price)) - 1 AS magnitude, COUNT(*)
+		FROM products
+		%s
+		GROUP BY currency, magnitude
+		ORDER BY currency, magnitude
+	`, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count products per price bucket: %w", err)
+	}
+	for rows.Next() {
+		var currency string
+		var magnitude, count int
+		if err := rows.Scan(&currency, &magnitude, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan price facet: %w", err)
+		}
+		facet, err := priceBucket(currency, magnitude, count)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		facets.PriceBuckets = append(facets.PriceBuckets, *facet)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	where, args = facetWhere(req, facetShop)
+	rows, err = r.db.Query(fmt.Sprintf(`
+		SELECT shop_id, COUNT(*)
+		FROM products
+		%s
+		GROUP BY shop_id
+		ORDER BY COUNT(*) DESC, shop_id
+	`, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count products per shop: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var facet productModel.ShopFacet
+		if err := rows.Scan(&facet.ShopID, &facet.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan shop facet: %w", err)
+		}
+		facets.Shops = append(facets.Shops, facet)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return facets, nil
+}
+
+// priceBucket returns the price facet of the given order of magnitude, from
+// 10^magnitude (or 0 for the first bucket) up to 10^(magnitude+1)
+func priceBucket(currency string, magnitude, count int) (*productModel.PriceFacet, error) {
+	min := money.Zero(currency)
+	if magnitude > 0 {
+		parsed, err := money.Parse("1"+strings.Repeat("0", magnitude), currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build price bucket: %w", err)
+		}
+		min = parsed
+	}
+	max, err := money.Parse("1"+strings.Repeat("0", magnitude+1), currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build price bucket: %w", err)
+	}
+	return &productModel.PriceFacet{Min: min, Max: max, Count: count}, nil
+}
+
+// CreateCategory adds a category and returns its ID
+func (r *productRepository) CreateCategory(req *productModel.CreateCategoryRequest) (int64, error) {
+	query := `
+		INSERT INTO categories (parent_id, name, created_at, updated_at)
+		VALUES (NULLIF(?, 0), ?, NOW(), NOW())
+	`
+
+	result, err := r.db.Exec(query, req.ParentID, req.Name)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return 0, fmt.Errorf("invalid name: category %s already exists", req.Name)
+		}
+		return 0, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted category ID: %w", err)
+	}
+	return id, nil
+}
+
+// ListCategories retrieves every category. The taxonomy is small enough to be
+// handled as a whole.
+func (r *productRepository) ListCategories() ([]productModel.Category, error) {
+	query := `
+		SELECT id, COALESCE(parent_id, 0), name, created_at, updated_at
+		FROM categories
+		ORDER BY name, id
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []productModel.Category{}
+	for rows.Next() {
+		var category productModel.Category
+		err := rows.Scan(
+			&category.ID,
+			&category.ParentID,
+			&category.Name,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, category)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return categories, nil
+}
+
+// UpdateCategory writes back the parent and name of a category
+func (r *productRepository) UpdateCategory(category *productModel.Category) error {
+	query := `
+		UPDATE categories
+		SET parent_id = NULLIF(?, 0), name = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, category.ParentID, category.Name, category.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return fmt.Errorf("invalid name: category %s already exists", category.Name)
+		}
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("category not found")
+	}
+
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 // ProductRepository defines the product repository interface
 type ProductRepository interface {
 	Create(product *productModel.CreateProductRequest) error
+	GetByID(id int) (*productModel.Product, error)
 	GetByIDForUpdateTx(tx *sql.Tx, id int) (*productModel.Product, error)
 	List(req *productModel.ProductListRequest) (*productModel.ProductListResponse, error)
 	UpdateTx(tx *sql.Tx, id int, req *productModel.UpdateProductRequest) error
@@ -20,7 +21,20 @@ type ProductRepository interface {
 	GetByIDsForUpdateTx(tx *sql.Tx, ids []int64) ([]productModel.Product, error)
 	InsertHoldStockAuditsTx(tx *sql.Tx, audits []productModel.HoldStockAudit) error
 	GetHoldStockAuditsByOrderIDTx(tx *sql.Tx, orderID int64) ([]productModel.HoldStockAudit, error)
-	UpdateHoldStockAuditsStatusTx(tx *sql.Tx, orderID int64, status string) error
+	UpdateHoldStockAuditsStatusTx(tx *sql.Tx, orderID int64, status string) (int64, error)
+	CancelHoldTx(tx *sql.Tx, orderID int64) error
+	IsHoldCancelledTx(tx *sql.Tx, orderID int64) (bool, error)
+	InsertStockAuditsTx(tx *sql.Tx, audits []productModel.StockAudit) error
+	CountStockAuditsByReferenceTx(tx *sql.Tx, reference string) (int, error)
+	CreateVariant(req *productModel.CreateVariantRequest) (int64, error)
+	GetVariantsByProductIDs(productIDs []int64) ([]productModel.ProductVariant, error)
+	GetVariantsByIDsForUpdateTx(tx *sql.Tx, ids []int64) ([]productModel.ProductVariant, error)
+	GetProductIDsWithVariantsTx(tx *sql.Tx, productIDs []int64) (map[int64]bool, error)
+	UpdateVariantTx(tx *sql.Tx, variant *productModel.ProductVariant) error
+	CountFacets(req *productModel.ProductListRequest) (*productModel.ProductFacets, error)
+	CreateCategory(req *productModel.CreateCategoryRequest) (int64, error)
+	ListCategories() ([]productModel.Category, error)
+	UpdateCategory(category *productModel.Category) error
 }
 
 // productRepository implements ProductRepository
@@ -43,18 +57,22 @@ func (r *productRepository) Create(req *productModel.CreateProductRequest) error
 	}
 
 	query := `
-		INSERT INTO products (name, description, price, stock, on_hold_stock, shop_id, shop_metadata, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, 'active', NOW(), NOW())
+		INSERT INTO products (name, description, price, currency, tax_category, weight_grams, stock, on_hold_stock, shop_id, shop_metadata, category_id, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, 0), 'active', NOW(), NOW())
 	`
 
 	_, err = r.db.Exec(query,
 		req.Name,
 		req.Description,
 		req.Price,
+		req.Price.Currency,
+		req.TaxCategory,
+		req.WeightGrams,
 		req.Stock,
 		req.OnHoldStock,
 		req.ShopID,
 		shopMetadataJSON,
+		req.CategoryID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create product: %w", err)
@@ -63,10 +81,53 @@ func (r *productRepository) Create(req *productModel.CreateProductRequest) error
 	return nil
 }
 
+// GetByID retrieves a product by ID
+func (r *productRepository) GetByID(id int) (*productModel.Product, error) {
+	query := `
+		SELECT id, name, description, currency, price, tax_category, weight_grams, stock, on_hold_stock, shop_id, shop_metadata, COALESCE(category_id, 0), status, created_at, updated_at
+		FROM products
+		WHERE id = ?
+	`
+
+	var product productModel.Product
+	var shopMetadataJSON []byte
+	err := r.db.QueryRow(query, id).Scan(
+		&product.ID,
+		&product.Name,
+		&product.Description,
+		&product.Price.Currency, // scanned first, the price's precision depends on it
+		&product.Price,
+		&product.TaxCategory,
+		&product.WeightGrams,
+		&product.Stock,
+		&product.OnHoldStock,
+		&product.ShopID,
+		&shopMetadataJSON,
+		&product.CategoryID,
+		&product.Status,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found")
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	// Unmarshal shop metadata
+	if err := json.Unmarshal(shopMetadataJSON, &product.ShopMetadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal shop metadata: %w", err)
+	}
+
+	return &product, nil
+}
+
 // GetByIDForUpdateTx retrieves a product by ID within a transaction with row lock
 func (r *productRepository) GetByIDForUpdateTx(tx *sql.Tx, id int) (*productModel.Product, error) {
 	query := `
-		SELECT id, name, description, price, stock, on_hold_stock, shop_id, shop_metadata, status, created_at, updated_at
+		SELECT id, name, description, currency, price, tax_category, weight_grams, stock, on_hold_stock, shop_id, shop_metadata, COALESCE(category_id, 0), status, created_at, updated_at
 		FROM products
 		WHERE id = ? FOR UPDATE
 	`
@@ -77,11 +138,15 @@ func (r *productRepository) GetByIDForUpdateTx(tx *sql.Tx, id int) (*productMode
 		&product.ID,
 		&product.Name,
 		&product.Description,
+		&product.Price.Currency, // scanned first, the price's precision depends on it
 		&product.Price,
+		&product.TaxCategory,
+		&product.WeightGrams,
 		&product.Stock,
 		&product.OnHoldStock,
 		&product.ShopID,
 		&shopMetadataJSON,
+		&product.CategoryID,
 		&product.Status,
 		&product.CreatedAt,
 		&product.UpdatedAt,
@@ -106,45 +171,12 @@ func (r *productRepository) GetByIDForUpdateTx(tx *sql.Tx, id int) (*productMode
 func (r *productRepository) List(req *productModel.ProductListRequest) (*productModel.ProductListResponse, error) {
 	countQuery := "SELECT COUNT(*) FROM products WHERE 1=1"
 	query := `
-		SELECT id, name, description, price, stock, on_hold_stock, shop_id, shop_metadata, status, created_at, updated_at
+		SELECT id, name, description, currency, price, tax_category, weight_grams, stock, on_hold_stock, shop_id, shop_metadata, COALESCE(category_id, 0), status, created_at, updated_at
 		FROM products
 		WHERE 1=1
 	`
 
-	args := []interface{}{}
-	conditions := []string{}
-
-	// Add filters
-	if req.ShopID > 0 {
-		conditions = append(conditions, "shop_id = ?")
-		args = append(args, req.ShopID)
-	}
-	if req.MinPrice > 0 {
-		conditions = append(conditions, "price >= ?")
-		args = append(args, req.MinPrice)
-	}
-	if req.MaxPrice > 0 {
-		conditions = append(conditions, "price <= ?")
-		args = append(args, req.MaxPrice)
-	}
-	if req.Status != "" {
-		conditions = append(conditions, "status = ?")
-		args = append(args, req.Status)
-	}
-	if req.Search != "" {
-		conditions = append(conditions, "(name LIKE ? OR description LIKE ?)")
-		searchParam := "%" + req.Search + "%"
-		args = append(args, searchParam, searchParam)
-	}
-
-	if len(req.IDs) > 0 {
-		placeholders := make([]string, len(req.IDs))
-		for i, id := range req.IDs {
-			placeholders[i] = "?"
-			args = append(args, id)
-		}
-		conditions = append(conditions, fmt.Sprintf("id IN (%s)", strings.Join(placeholders, ",")))
-	}
+	conditions, args := listConditions(req, facetNone)
 
 	// Apply conditions
 	if len(conditions) > 0 {
@@ -180,11 +212,15 @@ func (r *productRepository) List(req *productModel.ProductListRequest) (*product
 			&product.ID,
 			&product.Name,
 			&product.Description,
+			&product.Price.Currency, // scanned first, the price's precision depends on it
 			&product.Price,
+			&product.TaxCategory,
+			&product.WeightGrams,
 			&product.Stock,
 			&product.OnHoldStock,
 			&product.ShopID,
 			&shopMetadataJSON,
+			&product.CategoryID,
 			&product.Status,
 			&product.CreatedAt,
 			&product.UpdatedAt,
@@ -233,26 +269,32 @@ func (r *productRepository) UpdateTx(tx *sql.Tx, id int, req *productModel.Updat
 		args = append(args, req.Description)
 	}
 
-	if req.Price > 0 {
-		setClauses = append(setClauses, "price = ?")
-		args = append(args, req.Price)
+	if req.Price.IsPositive() {
+		setClauses = append(setClauses, "price = ?", "currency = ?")
+		args = append(args, req.Price, req.Price.Currency)
 	}
 
-	setClauses = append(setClauses, "stock = ?")
-	args = append(args, req.Stock)
+	if req.TaxCategory != "" {
+		setClauses = append(setClauses, "tax_category = ?")
+		args = append(args, req.TaxCategory)
+	}
+	if req.WeightGrams > 0 {
+		setClauses = append(setClauses, "weight_grams = ?")
+		args = append(args, req.WeightGrams)
+	}
+	if req.CategoryID > 0 {
+		setClauses = append(setClauses, "category_id = ?")
+		args = append(args, req.CategoryID)
+	}
+
+	if req.Stock != nil {
+		setClauses = append(setClauses, "stock = ?")
+		args = append(args, *req.Stock)
+	}
 
 	setClauses = append(setClauses, "on_hold_stock = ?")
 	args = append(args, req.OnHoldStock)
 
-	if req.ShopMetadata != nil {
-		shopMetadataJSON, err := json.Marshal(req.ShopMetadata)
-		if err != nil {
-			return fmt.Errorf("failed to marshal shop metadata: %w", err)
-		}
-		setClauses = append(setClauses, "shop_metadata = ?")
-		args = append(args, string(shopMetadataJSON))
-	}
-
 	if req.Status != "" {
 		setClauses = append(setClauses, "status = ?")
 		args = append(args, req.Status)
@@ -316,7 +358,7 @@ func (r *productRepository) GetByIDsForUpdateTx(tx *sql.Tx, ids []int64) ([]prod
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, name, description, price, stock, on_hold_stock, shop_id, shop_metadata, status, created_at, updated_at
+		SELECT id, name, description, currency, price, tax_category, weight_grams, stock, on_hold_stock, shop_id, shop_metadata, COALESCE(category_id, 0), status, created_at, updated_at
 		FROM products
 		WHERE id IN (%s) FOR UPDATE
 	`, strings.Join(placeholders, ","))
@@ -336,11 +378,15 @@ func (r *productRepository) GetByIDsForUpdateTx(tx *sql.Tx, ids []int64) ([]prod
 			&product.ID,
 			&product.Name,
 			&product.Description,
+			&product.Price.Currency, // scanned first, the price's precision depends on it
 			&product.Price,
+			&product.TaxCategory,
+			&product.WeightGrams,
 			&product.Stock,
 			&product.OnHoldStock,
 			&product.ShopID,
 			&shopMetadataJSON,
+			&product.CategoryID,
 			&product.Status,
 			&product.CreatedAt,
 			&product.UpdatedAt,
@@ -369,13 +415,13 @@ func (r *productRepository) InsertHoldStockAuditsTx(tx *sql.Tx, audits []product
 	}
 
 	placeholders := make([]string, 0, len(audits))
-	args := make([]interface{}, 0, len(audits)*5)
+	args := make([]interface{}, 0, len(audits)*6)
 	for _, audit := range audits {
-		placeholders = append(placeholders, "(?, ?, ?, ?, ?)")
-		args = append(args, audit.ProductID, audit.Quantity, audit.Status, audit.OrderID, audit.CreatedAt)
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?)")
+		args = append(args, audit.ProductID, audit.VariantID, audit.Quantity, audit.Status, audit.OrderID, audit.CreatedAt)
 	}
 
-	query := `INSERT INTO product_hold_audit (product_id, quantity, status, order_id, created_at) VALUES ` + strings.Join(placeholders, ",")
+	query := `INSERT INTO product_hold_audit (product_id, variant_id, quantity, status, order_id, created_at) VALUES ` + strings.Join(placeholders, ",")
 
 	_, err := tx.Exec(query, args...)
 	if err != nil {
@@ -385,11 +431,14 @@ func (r *productRepository) InsertHoldStockAuditsTx(tx *sql.Tx, audits []product
 	return nil
 }
 
+// GetHoldStockAuditsByOrderIDTx returns the hold audits of an order, locking them
+// so concurrent commits and releases of the same order serialize
 func (r *productRepository) GetHoldStockAuditsByOrderIDTx(tx *sql.Tx, orderID int64) ([]productModel.HoldStockAudit, error) {
 	query := `
-		SELECT id, product_id, quantity, status, order_id, created_at
+		SELECT id, product_id, variant_id, quantity, status, order_id, created_at
 		FROM product_hold_audit
 		WHERE order_id = ?
+		FOR UPDATE
 	`
 
 	rows, err := tx.Query(query, orderID)
@@ -404,6 +453,7 @@ func (r *productRepository) GetHoldStockAuditsByOrderIDTx(tx *sql.Tx, orderID in
 		err := rows.Scan(
 			&audit.ID,
 			&audit.ProductID,
+			&audit.VariantID,
 			&audit.Quantity,
 			&audit.Status,
 			&audit.OrderID,
@@ -421,17 +471,96 @@ func (r *productRepository) GetHoldStockAuditsByOrderIDTx(tx *sql.Tx, orderID in
 	return audits, nil
 }
 
-func (r *productRepository) UpdateHoldStockAuditsStatusTx(tx *sql.Tx, orderID int64, status string) error {
+// UpdateHoldStockAuditsStatusTx moves the held audits of an order to a final
+// status and returns how many were moved
+func (r *productRepository) UpdateHoldStockAuditsStatusTx(tx *sql.Tx, orderID int64, status string) (int64, error) {
 	query := `
 		UPDATE product_hold_audit
 		SET status = ?
 		WHERE order_id = ? and status = 'held'
 	`
 
-	_, err := tx.Exec(query, status, orderID)
+	result, err := tx.Exec(query, status, orderID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update hold stock audits status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// CancelHoldTx marks an order's hold as cancelled so later holds for it are
+// refused. Cancelling an order twice is a no-op.
+func (r *productRepository) CancelHoldTx(tx *sql.Tx, orderID int64) error {
+	query := `INSERT IGNORE INTO product_hold_cancellations (order_id) VALUES (?)`
+
+	_, err := tx.Exec(query, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel hold: %w", err)
+	}
+
+	return nil
+}
+
+// IsHoldCancelledTx reports whether an order's hold was cancelled, locking the
+// marker so a concurrent cancellation waits for the hold or is seen by it
+func (r *productRepository) IsHoldCancelledTx(tx *sql.Tx, orderID int64) (bool, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM product_hold_cancellations
+		WHERE order_id = ?
+		FOR UPDATE
+	`
+
+	var count int
+	if err := tx.QueryRow(query, orderID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check hold cancellation: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// InsertStockAuditsTx inserts stock audit records within a transaction
+func (r *productRepository) InsertStockAuditsTx(tx *sql.Tx, audits []productModel.StockAudit) error {
+	if len(audits) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(audits))
+	args := make([]interface{}, 0, len(audits)*6)
+	for _, audit := range audits {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?)")
+		args = append(args, audit.ProductID, audit.VariantID, audit.Quantity, audit.Reference, audit.Reason, audit.CreatedAt)
+	}
+
+	query := `INSERT INTO product_stock_audit (product_id, variant_id, quantity, reference, reason, created_at) VALUES ` + strings.Join(placeholders, ",")
+
+	_, err := tx.Exec(query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to update hold stock audits status: %w", err)
+		return fmt.Errorf("failed to insert stock audits: %w", err)
 	}
 
 	return nil
 }
+
+// CountStockAuditsByReferenceTx counts the stock audits recorded for a reference,
+// locking them so concurrent restocks of the same reference serialize
+func (r *productRepository) CountStockAuditsByReferenceTx(tx *sql.Tx, reference string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM product_stock_audit
+		WHERE reference = ?
+		FOR UPDATE
+	`
+
+	var count int
+	if err := tx.QueryRow(query, reference).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count stock audits: %w", err)
+	}
+
+	return count, nil
+}
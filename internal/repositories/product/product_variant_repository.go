@@ -0,0 +1,241 @@
+package product
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	productModel "github.com/Christyan39/test-eDot/internal/models/product"
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// variantColumns selects a variant with the currency of its product, which its
+// price override is in. The currency subquery is not locked by FOR UPDATE.
+const variantColumns = `v.id, v.product_id, v.sku, v.options, (SELECT p.currency FROM products p WHERE p.id = v.product_id), v.price, v.stock, v.on_hold_stock, v.status, v.created_at, v.updated_at`
+
+// CreateVariant adds a variant to a product and returns its ID
+func (r *productRepository) CreateVariant(req *productModel.CreateVariantRequest) (int64, error) {
+	optionsJSON, err := json.Marshal(req.Options)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal variant options: %w", err)
+	}
+
+	query := `
+		INSERT INTO product_variants (product_id, sku, options, price, stock, on_hold_stock, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, 0, 'active', NOW(), NOW())
+	`
+
+	result, err := r.db.Exec(query,
+		req.ProductID,
+		req.SKU,
+		optionsJSON,
+		variantPrice(req.Price),
+		req.Stock,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return 0, fmt.Errorf("invalid SKU: %s is already used by another variant of the product", req.SKU)
+		}
+		return 0, fmt.Errorf("failed to create variant: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted variant ID: %w", err)
+	}
+	return id, nil
+}
+
+// GetVariantsByProductIDs retrieves the variants of the given products
+func (r *productRepository) GetVariantsByProductIDs(productIDs []int64) ([]productModel.ProductVariant, error) {
+	if len(productIDs) == 0 {
+		return []productModel.ProductVariant{}, nil
+	}
+
+	placeholders := make([]string, len(productIDs))
+	args := make([]interface{}, len(productIDs))
+	for i, id := range productIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM product_variants v
+		WHERE v.product_id IN (%s)
+		ORDER BY v.product_id, v.id
+	`, variantColumns, strings.Join(placeholders, ","))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variants: %w", err)
+	}
+	defer rows.Close()
+
+	return scanVariants(rows)
+}
+
+// GetVariantsByIDsForUpdateTx retrieves variants by ID within a transaction with row lock
+func (r *productRepository) GetVariantsByIDsForUpdateTx(tx *sql.Tx, ids []int64) ([]productModel.ProductVariant, error) {
+	if len(ids) == 0 {
+		return []productModel.ProductVariant{}, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM product_variants v
+		WHERE v.id IN (%s)
+		ORDER BY v.id
+		FOR UPDATE
+	`, variantColumns, strings.Join(placeholders, ","))
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variants: %w", err)
+	}
+	defer rows.Close()
+
+	return scanVariants(rows)
+}
+
+// GetProductIDsWithVariantsTx returns which of the given products have variants
+func (r *productRepository) GetProductIDsWithVariantsTx(tx *sql.Tx, productIDs []int64) (map[int64]bool, error) {
+	withVariants := make(map[int64]bool)
+	if len(productIDs) == 0 {
+		return withVariants, nil
+	}
+
+	placeholders := make([]string, len(productIDs))
+	args := make([]interface{}, len(productIDs))
+	for i, id := range productIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`
+		SELECT DISTINCT product_id
+		FROM product_variants
+		WHERE product_id IN (%s)
+	`, strings.Join(placeholders, ","))
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products with variants: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID int64
+		if err := rows.Scan(&productID); err != nil {
+			return nil, fmt.Errorf("failed to scan product ID: %w", err)
+		}
+		withVariants[productID] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return withVariants, nil
+}
+
+// UpdateVariantTx updates a variant by ID with partial data within a
+// transaction. Stock and on-hold stock are always written.
+func (r *productRepository) UpdateVariantTx(tx *sql.Tx, variant *productModel.ProductVariant) error {
+	optionsJSON, err := json.Marshal(variant.Options)
+	if err != nil {
+		return fmt.Errorf("failed to marshal variant options: %w", err)
+	}
+
+	query := `
+		UPDATE product_variants
+		SET sku = ?, options = ?, price = ?, stock = ?, on_hold_stock = ?, status = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+
+	result, err := tx.Exec(query,
+		variant.SKU,
+		optionsJSON,
+		variantPrice(variant.Price),
+		variant.Stock,
+		variant.OnHoldStock,
+		variant.Status,
+		variant.ID,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return fmt.Errorf("invalid SKU: %s is already used by another variant of the product", variant.SKU)
+		}
+		return fmt.Errorf("failed to update variant: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("variant not found")
+	}
+
+	return nil
+}
+
+// variantPrice stores variants without a price override as NULL
+func variantPrice(price *money.Money) interface{} {
+	if price == nil {
+		return nil
+	}
+	return *price
+}
+
+// scanVariants scans rows selected with variantColumns
+func scanVariants(rows *sql.Rows) ([]productModel.ProductVariant, error) {
+	variants := []productModel.ProductVariant{}
+	for rows.Next() {
+		var variant productModel.ProductVariant
+		var optionsJSON []byte
+		var currency string
+		var price sql.NullString
+		err := rows.Scan(
+			&variant.ID,
+			&variant.ProductID,
+			&variant.SKU,
+			&optionsJSON,
+			&currency,
+			&price,
+			&variant.Stock,
+			&variant.OnHoldStock,
+			&variant.Status,
+			&variant.CreatedAt,
+			&variant.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan variant: %w", err)
+		}
+
+		if err := json.Unmarshal(optionsJSON, &variant.Options); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal variant options: %w", err)
+		}
+		if price.Valid {
+			override, err := money.Parse(price.String, currency)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse variant price: %w", err)
+			}
+			variant.Price = &override
+		}
+
+		variants = append(variants, variant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return variants, nil
+}
@@ -0,0 +1,246 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	models "github.com/Christyan39/test-eDot/internal/models/user"
+)
+
+const addressColumns = `id, user_id, label, recipient_name, phone, line1, line2, city, province, postal_code, country, is_default, created_at, updated_at`
+
+// CreateAddress adds an address to a user's address book. The user's first
+// address becomes the default; a new default replaces the previous one.
+func (r *UserRepository) CreateAddress(ctx context.Context, userID int, req *models.CreateAddressRequest) (int64, error) {
+	if r.db == nil {
+		return 0, fmt.Errorf("database connection is not available")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer rollbackUnlessCommitted(tx)
+
+	var count int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_addresses WHERE user_id = ? FOR UPDATE`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count addresses: %v", err)
+	}
+
+	isDefault := req.IsDefault || count == 0
+	if isDefault {
+		if err := clearDefaultAddressTx(ctx, tx, userID); err != nil {
+			return 0, err
+		}
+	}
+
+	query := `
+		INSERT INTO user_addresses (user_id, label, recipient_name, phone, line1, line2, city, province, postal_code, country, is_default, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+	`
+	result, err := tx.ExecContext(ctx, query,
+		userID, req.Label, req.RecipientName, req.Phone, req.Line1, req.Line2,
+		req.City, req.Province, req.PostalCode, req.Country, isDefault,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create address: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted address ID: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return id, nil
+}
+
+// ListAddresses retrieves a user's address book, default address first
+func (r *UserRepository) ListAddresses(ctx context.Context, userID int) ([]models.Address, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection is not available")
+	}
+
+	query := `SELECT ` + addressColumns + ` FROM user_addresses WHERE user_id = ? ORDER BY is_default DESC, id`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list addresses: %v", err)
+	}
+	defer rows.Close()
+
+	addresses := []models.Address{}
+	for rows.Next() {
+		address, err := scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, *address)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %v", err)
+	}
+
+	return addresses, nil
+}
+
+// GetAddress retrieves one of a user's addresses. It returns nil when the user
+// has no address with the ID.
+func (r *UserRepository) GetAddress(ctx context.Context, userID int, addressID int64) (*models.Address, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection is not available")
+	}
+
+	query := `SELECT ` + addressColumns + ` FROM user_addresses WHERE id = ? AND user_id = ?`
+	address, err := scanAddress(r.db.QueryRowContext(ctx, query, addressID, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// UpdateAddress replaces one of a user's addresses. A default address can only
+// stop being the default by making another address the default.
+func (r *UserRepository) UpdateAddress(ctx context.Context, userID int, addressID int64, req *models.UpdateAddressRequest) error {
+	if r.db == nil {
+		return fmt.Errorf("database connection is not available")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer rollbackUnlessCommitted(tx)
+
+	var isDefault bool
+	err = tx.QueryRowContext(ctx, `SELECT is_default FROM user_addresses WHERE id = ? AND user_id = ? FOR UPDATE`, addressID, userID).Scan(&isDefault)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("address not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get address: %v", err)
+	}
+
+	if req.IsDefault && !isDefault {
+		if err := clearDefaultAddressTx(ctx, tx, userID); err != nil {
+			return err
+		}
+		isDefault = true
+	}
+
+	query := `
+		UPDATE user_addresses
+		SET label = ?, recipient_name = ?, phone = ?, line1 = ?, line2 = ?, city = ?, province = ?, postal_code = ?, country = ?, is_default = ?, updated_at = NOW()
+		WHERE id = ? AND user_id = ?
+	`
+	_, err = tx.ExecContext(ctx, query,
+		req.Label, req.RecipientName, req.Phone, req.Line1, req.Line2,
+		req.City, req.Province, req.PostalCode, req.Country, isDefault,
+		addressID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update address: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// DeleteAddress removes one of a user's addresses. When it was the default,
+// the most recently added remaining address becomes the default.
+func (r *UserRepository) DeleteAddress(ctx context.Context, userID int, addressID int64) error {
+	if r.db == nil {
+		return fmt.Errorf("database connection is not available")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer rollbackUnlessCommitted(tx)
+
+	var isDefault bool
+	err = tx.QueryRowContext(ctx, `SELECT is_default FROM user_addresses WHERE id = ? AND user_id = ? FOR UPDATE`, addressID, userID).Scan(&isDefault)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("address not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get address: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_addresses WHERE id = ? AND user_id = ?`, addressID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete address: %v", err)
+	}
+
+	if isDefault {
+		_, err = tx.ExecContext(ctx, `UPDATE user_addresses SET is_default = TRUE, updated_at = NOW() WHERE user_id = ? ORDER BY id DESC LIMIT 1`, userID)
+		if err != nil {
+			return fmt.Errorf("failed to set default address: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// clearDefaultAddressTx unsets the user's current default address
+func clearDefaultAddressTx(ctx context.Context, tx *sql.Tx, userID int) error {
+	_, err := tx.ExecContext(ctx, `UPDATE user_addresses SET is_default = FALSE, updated_at = NOW() WHERE user_id = ? AND is_default = TRUE`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to clear default address: %v", err)
+	}
+	return nil
+}
+
+// rollbackUnlessCommitted rolls back a transaction that was not committed
+func rollbackUnlessCommitted(tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+		log.Printf("Failed to rollback transaction: %v", err)
+	}
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAddress scans a row selected with addressColumns
+func scanAddress(row rowScanner) (*models.Address, error) {
+	address := &models.Address{}
+	err := row.Scan(
+		&address.ID,
+		&address.UserID,
+		&address.Label,
+		&address.RecipientName,
+		&address.Phone,
+		&address.Line1,
+		&address.Line2,
+		&address.City,
+		&address.Province,
+		&address.PostalCode,
+		&address.Country,
+		&address.IsDefault,
+		&address.CreatedAt,
+		&address.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan address: %v", err)
+	}
+	return address, nil
+}
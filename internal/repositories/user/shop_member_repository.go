@@ -0,0 +1,155 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	models "github.com/Christyan39/test-eDot/internal/models/user"
+)
+
+// SaveShopMember gives a user a role in a shop, replacing any role they had
+func (r *UserRepository) SaveShopMember(ctx context.Context, shopID int, req *models.AddShopMemberRequest) error {
+	if r.db == nil {
+		return fmt.Errorf("database connection is not available")
+	}
+
+	query := `
+		INSERT INTO shop_members (shop_id, user_id, role, created_at, updated_at)
+		VALUES (?, ?, ?, NOW(), NOW())
+		ON DUPLICATE KEY UPDATE role = VALUES(role), updated_at = NOW()
+	`
+	_, err := r.db.ExecContext(ctx, query, shopID, req.UserID, req.Role)
+	if err != nil {
+		// The shop or the user does not exist when a foreign key rejects the row
+		if strings.Contains(err.Error(), "fk_shop_members_shop_id") {
+			return fmt.Errorf("shop not found")
+		}
+		if strings.Contains(err.Error(), "foreign key constraint") {
+			return fmt.Errorf("user not found")
+		}
+		return fmt.Errorf("failed to save shop member: %v", err)
+	}
+
+	return nil
+}
+
+// GetShopMember retrieves a user's membership of a shop. It returns nil when
+// the user is not a member.
+func (r *UserRepository) GetShopMember(ctx context.Context, shopID, userID int) (*models.ShopMember, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection is not available")
+	}
+
+	query := `SELECT shop_id, user_id, role, created_at, updated_at FROM shop_members WHERE shop_id = ? AND user_id = ?`
+
+	var member models.ShopMember
+	err := r.db.QueryRowContext(ctx, query, shopID, userID).Scan(
+		&member.ShopID,
+		&member.UserID,
+		&member.Role,
+		&member.CreatedAt,
+		&member.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shop member: %v", err)
+	}
+
+	return &member, nil
+}
+
+// ListShopMembersByUser retrieves every shop membership of a user
+func (r *UserRepository) ListShopMembersByUser(ctx context.Context, userID int) ([]models.ShopMember, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection is not available")
+	}
+
+	query := `SELECT shop_id, user_id, role, created_at, updated_at FROM shop_members WHERE user_id = ? ORDER BY shop_id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shop members: %v", err)
+	}
+	defer rows.Close()
+
+	members := []models.ShopMember{}
+	for rows.Next() {
+		var member models.ShopMember
+		if err := rows.Scan(
+			&member.ShopID,
+			&member.UserID,
+			&member.Role,
+			&member.CreatedAt,
+			&member.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan shop member: %v", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate shop members: %v", err)
+	}
+
+	return members, nil
+}
+
+// ListShopMembers retrieves the members of a shop, owners first
+func (r *UserRepository) ListShopMembers(ctx context.Context, shopID int) ([]models.ShopMember, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection is not available")
+	}
+
+	query := `SELECT shop_id, user_id, role, created_at, updated_at FROM shop_members WHERE shop_id = ? ORDER BY role = 'owner' DESC, user_id`
+
+	rows, err := r.db.QueryContext(ctx, query, shopID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shop members: %v", err)
+	}
+	defer rows.Close()
+
+	members := []models.ShopMember{}
+	for rows.Next() {
+		var member models.ShopMember
+		if err := rows.Scan(
+			&member.ShopID,
+			&member.UserID,
+			&member.Role,
+			&member.CreatedAt,
+			&member.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan shop member: %v", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate shop members: %v", err)
+	}
+
+	return members, nil
+}
+
+// DeleteShopMember removes a user's access to a shop
+func (r *UserRepository) DeleteShopMember(ctx context.Context, shopID, userID int) error {
+	if r.db == nil {
+		return fmt.Errorf("database connection is not available")
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM shop_members WHERE shop_id = ? AND user_id = ?`, shopID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete shop member: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("shop member not found")
+	}
+
+	return nil
+}
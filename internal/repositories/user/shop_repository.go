@@ -0,0 +1,164 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	models "github.com/Christyan39/test-eDot/internal/models/user"
+)
+
+const shopColumns = `id, name, description, region, status, created_at, updated_at`
+
+// CreateShop opens a shop with its creator as owner
+func (r *UserRepository) CreateShop(ctx context.Context, ownerID int, req *models.CreateShopRequest) (int, error) {
+	if r.db == nil {
+		return 0, fmt.Errorf("database connection is not available")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer rollbackUnlessCommitted(tx)
+
+	query := `
+		INSERT INTO shops (name, description, region, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, NOW(), NOW())
+	`
+	result, err := tx.ExecContext(ctx, query, req.Name, req.Description, req.Region, models.ShopStatusActive)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create shop: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted shop ID: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO shop_members (shop_id, user_id, role, created_at, updated_at)
+		VALUES (?, ?, ?, NOW(), NOW())
+	`, id, ownerID, models.ShopRoleOwner)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add shop owner: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return int(id), nil
+}
+
+// GetShop retrieves a shop. It returns nil when there is no shop with the ID.
+func (r *UserRepository) GetShop(ctx context.Context, shopID int) (*models.Shop, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection is not available")
+	}
+
+	query := `SELECT ` + shopColumns + ` FROM shops WHERE id = ?`
+	shop, err := scanShop(r.db.QueryRowContext(ctx, query, shopID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return shop, nil
+}
+
+// ListShopsByIDs retrieves the shops with the given IDs, skipping unknown ones
+func (r *UserRepository) ListShopsByIDs(ctx context.Context, shopIDs []int) ([]models.Shop, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection is not available")
+	}
+
+	shops := []models.Shop{}
+	if len(shopIDs) == 0 {
+		return shops, nil
+	}
+
+	placeholders := make([]string, len(shopIDs))
+	args := make([]interface{}, len(shopIDs))
+	for i, id := range shopIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := `SELECT ` + shopColumns + ` FROM shops WHERE id IN (` + strings.Join(placeholders, ", ") + `) ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shops: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		shop, err := scanShop(rows)
+		if err != nil {
+			return nil, err
+		}
+		shops = append(shops, *shop)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %v", err)
+	}
+
+	return shops, nil
+}
+
+// UpdateShop replaces a shop's details
+func (r *UserRepository) UpdateShop(ctx context.Context, shopID int, req *models.UpdateShopRequest) error {
+	if r.db == nil {
+		return fmt.Errorf("database connection is not available")
+	}
+
+	query := `
+		UPDATE shops
+		SET name = ?, description = ?, region = ?, status = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+	result, err := r.db.ExecContext(ctx, query, req.Name, req.Description, req.Region, req.Status, shopID)
+	if err != nil {
+		return fmt.Errorf("failed to update shop: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rowsAffected == 0 {
+		// MySQL reports 0 rows for an update that changes nothing, so check existence
+		shop, err := r.GetShop(ctx, shopID)
+		if err != nil {
+			return err
+		}
+		if shop == nil {
+			return fmt.Errorf("shop not found")
+		}
+	}
+
+	return nil
+}
+
+// scanShop scans a row selected with shopColumns
+func scanShop(row rowScanner) (*models.Shop, error) {
+	shop := &models.Shop{}
+	err := row.Scan(
+		&shop.ID,
+		&shop.Name,
+		&shop.Description,
+		&shop.Region,
+		&shop.Status,
+		&shop.CreatedAt,
+		&shop.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan shop: %v", err)
+	}
+	return shop, nil
+}
@@ -13,6 +13,20 @@ import (
 type UserRepositoryInterface interface {
 	GetByEmailOrPhone(ctx context.Context, identifier string) (*models.User, error)
 	Create(ctx context.Context, req *models.CreateUserRequest) error
+	CreateAddress(ctx context.Context, userID int, req *models.CreateAddressRequest) (int64, error)
+	ListAddresses(ctx context.Context, userID int) ([]models.Address, error)
+	GetAddress(ctx context.Context, userID int, addressID int64) (*models.Address, error)
+	UpdateAddress(ctx context.Context, userID int, addressID int64, req *models.UpdateAddressRequest) error
+	DeleteAddress(ctx context.Context, userID int, addressID int64) error
+	SaveShopMember(ctx context.Context, shopID int, req *models.AddShopMemberRequest) error
+	GetShopMember(ctx context.Context, shopID, userID int) (*models.ShopMember, error)
+	ListShopMembersByUser(ctx context.Context, userID int) ([]models.ShopMember, error)
+	ListShopMembers(ctx context.Context, shopID int) ([]models.ShopMember, error)
+	DeleteShopMember(ctx context.Context, shopID, userID int) error
+	CreateShop(ctx context.Context, ownerID int, req *models.CreateShopRequest) (int, error)
+	GetShop(ctx context.Context, shopID int) (*models.Shop, error)
+	ListShopsByIDs(ctx context.Context, shopIDs []int) ([]models.Shop, error)
+	UpdateShop(ctx context.Context, shopID int, req *models.UpdateShopRequest) error
 }
 
 // UserRepository implements UserRepositoryInterface
@@ -0,0 +1,248 @@
+package order
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	productModels "github.com/Christyan39/test-eDot/internal/models/product"
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// Checkout creates one order per shop for a cart spanning several shops. All orders
+// share a checkout ID and their stock is held atomically: either every order is
+// created with its stock held, or none is.
+func (u *orderUsecase) Checkout(ctx context.Context, req *orderModel.CheckoutRequest) (*orderModel.OrderGroup, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("invalid checkout: at least one item is required")
+	}
+	// A coupon's usage limits and fixed discounts are per order, so it is not
+	// spread across the per-shop orders of a checkout
+	if strings.TrimSpace(req.CouponCode) != "" {
+		return nil, fmt.Errorf("invalid checkout: coupons cannot be used on a multi-shop checkout, order from each shop separately to use coupon %s", normalizeCouponCode(req.CouponCode))
+	}
+	if req.OrderData != nil {
+		req.OrderData.Version = orderModel.OrderDataVersion
+	}
+
+	itemIDs := make([]int64, 0, len(req.Items))
+	for _, item := range req.Items {
+		itemIDs = append(itemIDs, item.ProductID)
+	}
+
+	// Fetch product details in batch
+	products, err := u.productClient.GetProductByIDs(itemIDs)
+	if err != nil {
+		log.Printf("Failed to fetch products from Product Service: %v", err)
+		return nil, fmt.Errorf("failed to fetch product details")
+	}
+
+	productMap := make(map[int64]*productModels.Product)
+	for i, product := range products {
+		productMap[product.ID] = &products[i]
+	}
+
+	currency, err := buyerCurrency(req.Currency, req.TotalPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the items by the shop that owns each product
+	itemsByShop := make(map[int][]orderModel.OrderItem)
+	shopIDs := []int{}
+	for _, item := range req.Items {
+		product, exists := productMap[item.ProductID]
+		if !exists {
+			return nil, fmt.Errorf("product %d not found", item.ProductID)
+		}
+		shopID := product.ShopID
+		if _, exists := itemsByShop[shopID]; !exists {
+			shopIDs = append(shopIDs, shopID)
+		}
+		itemsByShop[shopID] = append(itemsByShop[shopID], item)
+	}
+	sort.Ints(shopIDs)
+
+	// Every order of the checkout ships to the same address
+	address, err := u.orderShippingAddress(ctx, req.UserID, req.AddressID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each shop prices in its own currency, so each order has its own exchange rate
+	orderRequests := make([]*orderModel.CreateOrderRequest, 0, len(shopIDs))
+	totalPrice := money.Zero(currency)
+	for _, shopID := range shopIDs {
+		pricing, err := u.pricingFor(currency, itemsByShop[shopID], productMap)
+		if err != nil {
+			return nil, err
+		}
+
+		shopTotal, err := validateOrderItems(itemsByShop[shopID], productMap, pricing)
+		if err != nil {
+			return nil, err
+		}
+
+		orderReq := &orderModel.CreateOrderRequest{
+			UserID:    req.UserID,
+			ShopID:    shopID,
+			AddressID: req.AddressID,
+			Items:     itemsByShop[shopID],
+			OrderData: req.OrderData,
+		}
+		pricing.apply(orderReq)
+
+		// Each shop's region decides its tax; only exclusive tax adds to the total
+		exclusiveTax, err := u.applyTax(ctx, orderReq, productMap)
+		if err != nil {
+			return nil, err
+		}
+		shopTotal, err = shopTotal.Add(exclusiveTax)
+		if err != nil {
+			return nil, fmt.Errorf("cannot apply tax for shop %d: %w", shopID, err)
+		}
+
+		// Each shop ships its own parcel
+		err = u.applyShipping(ctx, orderReq, address, productMap)
+		if err != nil {
+			return nil, err
+		}
+		shopTotal, err = shopTotal.Add(orderReq.ShippingFee)
+		if err != nil {
+			return nil, fmt.Errorf("cannot apply shipping fee for shop %d: %w", shopID, err)
+		}
+		orderReq.TotalPrice = shopTotal
+
+		totalPrice, err = totalPrice.Add(shopTotal)
+		if err != nil {
+			return nil, fmt.Errorf("cannot total shop %d: %w", shopID, err)
+		}
+		orderRequests = append(orderRequests, orderReq)
+	}
+
+	if !totalPrice.Equal(req.TotalPrice) {
+		return nil, fmt.Errorf("total price mismatch: expected %s, got %s", totalPrice, req.TotalPrice)
+	}
+
+	checkoutID, err := newCheckoutID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate checkout ID: %w", err)
+	}
+
+	log.Printf("[Checkout] Creating %d orders for checkout %s", len(shopIDs), checkoutID)
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	createdAt := time.Now()
+	expiresAt := createdAt.Add(orderExpiration())
+
+	holdStockRequest := &productModels.HoldStockBatchRequest{
+		Holds: make([]productModels.HoldStockRequest, 0, len(shopIDs)),
+	}
+	for _, orderReq := range orderRequests {
+		orderReq.CheckoutID = checkoutID
+		orderReq.ExpiresAt = expiresAt
+
+		var hold *productModels.HoldStockRequest
+		hold, err = u.insertOrderTx(ctx, tx, orderReq)
+		if err != nil {
+			return nil, err
+		}
+
+		holdStockRequest.Holds = append(holdStockRequest.Holds, *hold)
+	}
+
+	err = u.productClient.HoldStockForOrders(ctx, holdStockRequest)
+
+	// From here on the product service may hold stock for these orders, even when
+	// the hold call itself failed (e.g. timeout), so any failure must release it
+	defer func() {
+		if err != nil {
+			for _, orderReq := range orderRequests {
+				u.compensateHeldStock(orderReq.OrderID, err)
+			}
+		}
+	}()
+
+	if err != nil {
+		log.Printf("Failed to hold stock in Product Service: %v", err)
+		return nil, fmt.Errorf("failed to hold stock in Product Service: %w", err)
+	}
+
+	// Queue one event per order in the same transaction
+	for _, orderReq := range orderRequests {
+		err = u.enqueueOrderEventTx(ctx, tx, orderReq, expiresAt)
+		if err != nil {
+			log.Printf("Failed to enqueue order event: %v", err)
+			return nil, fmt.Errorf("failed to enqueue order event: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	group := &orderModel.OrderGroup{
+		CheckoutID: checkoutID,
+		Orders:     make([]orderModel.Order, 0, len(orderRequests)),
+		TotalPrice: totalPrice,
+		UserID:     req.UserID,
+		Status:     orderModel.OrderStatusPending,
+		CreatedAt:  createdAt,
+	}
+	for _, orderReq := range orderRequests {
+		for _, item := range orderReq.Items {
+			group.TotalItems += item.Quantity
+		}
+		group.Orders = append(group.Orders, orderModel.Order{
+			ID:              orderReq.OrderID,
+			UserID:          orderReq.UserID,
+			ShopID:          orderReq.ShopID,
+			CheckoutID:      checkoutID,
+			TotalPrice:      orderReq.TotalPrice,
+			ShopCurrency:    orderReq.ShopCurrency,
+			ExchangeRate:    orderReq.ExchangeRate,
+			TaxTotal:        orderReq.TaxTotal,
+			AddressID:       orderReq.AddressID,
+			ShippingAddress: orderReq.ShippingAddress,
+			ShippingFee:     orderReq.ShippingFee,
+			Status:          orderModel.OrderStatusPending,
+			OrderData:       orderReq.OrderData,
+			CreatedAt:       createdAt,
+			UpdatedAt:       createdAt,
+			ExpiresAt:       expiresAt,
+			Items:           orderReq.Items,
+		})
+	}
+
+	log.Printf("[Checkout] Checkout %s created %d orders", checkoutID, len(group.Orders))
+	return group, nil
+}
+
+// newCheckoutID returns a random 32 character hex identifier
+func newCheckoutID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
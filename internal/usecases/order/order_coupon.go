@@ -0,0 +1,267 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// normalizeCouponCode makes coupon codes case-insensitive
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CreateCoupon validates and stores a new coupon
+func (u *orderUsecase) CreateCoupon(ctx context.Context, req *orderModel.CreateCouponRequest) (*orderModel.Coupon, error) {
+	code := normalizeCouponCode(req.Code)
+	if code == "" || len(code) > 64 {
+		return nil, fmt.Errorf("invalid coupon code: must be 1 to 64 characters")
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = money.DefaultCurrency()
+	}
+	if !money.IsValidCurrency(currency) {
+		return nil, fmt.Errorf("invalid currency %q", req.Currency)
+	}
+
+	coupon := &orderModel.Coupon{
+		Code:         code,
+		Description:  req.Description,
+		DiscountType: req.DiscountType,
+		PercentOff:   "0",
+		AmountOff:    money.Zero(currency),
+		MinSpend:     money.Zero(currency),
+		Scope:        req.Scope,
+		ShopID:       req.ShopID,
+		ProductID:    req.ProductID,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		Status:       orderModel.CouponStatusActive,
+		StartsAt:     time.Now(),
+		EndsAt:       req.EndsAt,
+	}
+
+	switch req.DiscountType {
+	case orderModel.CouponDiscountPercentage:
+		percent, ok := new(big.Rat).SetString(strings.TrimSpace(req.PercentOff))
+		if !ok || percent.Sign() <= 0 || percent.Cmp(big.NewRat(100, 1)) > 0 {
+			return nil, fmt.Errorf("invalid percent_off: must be greater than 0 and at most 100")
+		}
+		coupon.PercentOff = money.FormatRate(percent)
+	case orderModel.CouponDiscountFixed:
+		amountOff, err := money.Parse(req.AmountOff, currency)
+		if err != nil || !amountOff.IsPositive() {
+			return nil, fmt.Errorf("invalid amount_off: must be greater than 0")
+		}
+		coupon.AmountOff = amountOff
+	default:
+		return nil, fmt.Errorf("invalid discount_type: must be %s or %s", orderModel.CouponDiscountPercentage, orderModel.CouponDiscountFixed)
+	}
+
+	if req.MinSpend != "" {
+		minSpend, err := money.Parse(req.MinSpend, currency)
+		if err != nil || minSpend.IsNegative() {
+			return nil, fmt.Errorf("invalid min_spend: must be 0 or more")
+		}
+		coupon.MinSpend = minSpend
+	}
+
+	switch coupon.Scope {
+	case "", orderModel.CouponScopeAll:
+		coupon.Scope = orderModel.CouponScopeAll
+		coupon.ShopID, coupon.ProductID = 0, 0
+	case orderModel.CouponScopeShop:
+		if coupon.ShopID <= 0 {
+			return nil, fmt.Errorf("invalid coupon: shop_id is required for shop scope")
+		}
+		coupon.ProductID = 0
+	case orderModel.CouponScopeProduct:
+		if coupon.ProductID <= 0 {
+			return nil, fmt.Errorf("invalid coupon: product_id is required for product scope")
+		}
+		coupon.ShopID = 0
+	default:
+		return nil, fmt.Errorf("invalid scope: must be all, shop or product")
+	}
+
+	if coupon.UsageLimit < 0 || coupon.PerUserLimit < 0 {
+		return nil, fmt.Errorf("invalid coupon: usage limits must be 0 (unlimited) or more")
+	}
+
+	if req.StartsAt != nil {
+		coupon.StartsAt = *req.StartsAt
+	}
+	if coupon.EndsAt != nil && !coupon.EndsAt.After(coupon.StartsAt) {
+		return nil, fmt.Errorf("invalid coupon: ends_at must be after starts_at")
+	}
+
+	id, err := u.orderRepo.CreateCoupon(ctx, coupon)
+	if err != nil {
+		return nil, err
+	}
+	coupon.ID = id
+
+	log.Printf("[Coupon] Created coupon %s (%s, scope %s)", coupon.Code, coupon.DiscountType, coupon.Scope)
+	return coupon, nil
+}
+
+// applyCouponTx validates the order's coupon and sets the resulting discount lines
+// on req. The coupon row stays locked until the transaction ends, so concurrent
+// orders cannot exceed its usage limits.
+func (u *orderUsecase) applyCouponTx(ctx context.Context, tx *sql.Tx, req *orderModel.CreateOrderRequest) (*orderModel.Coupon, error) {
+	code := normalizeCouponCode(req.CouponCode)
+	coupon, err := u.orderRepo.GetCouponByCodeForUpdateTx(ctx, tx, code)
+	if err != nil {
+		return nil, err
+	}
+	if coupon == nil {
+		return nil, fmt.Errorf("invalid coupon: %s does not exist", code)
+	}
+
+	now := time.Now()
+	switch {
+	case coupon.Status != orderModel.CouponStatusActive:
+		return nil, fmt.Errorf("invalid coupon: %s is not active", code)
+	case now.Before(coupon.StartsAt):
+		return nil, fmt.Errorf("invalid coupon: %s is not valid yet", code)
+	case coupon.EndsAt != nil && !now.Before(*coupon.EndsAt):
+		return nil, fmt.Errorf("invalid coupon: %s has expired", code)
+	case coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit:
+		return nil, fmt.Errorf("invalid coupon: %s has reached its usage limit", code)
+	}
+
+	if coupon.PerUserLimit > 0 {
+		used, err := u.orderRepo.CountCouponRedemptionsByUserTx(ctx, tx, coupon.ID, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if used >= coupon.PerUserLimit {
+			return nil, fmt.Errorf("invalid coupon: %s can only be used %d time(s) per user", code, coupon.PerUserLimit)
+		}
+	}
+
+	// Coupon amounts are converted to the buyer's currency
+	rate, err := u.rates.Rate(coupon.AmountOff.Currency, req.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid coupon: %s cannot be used in %s: %w", code, req.Currency, err)
+	}
+
+	discounts, err := couponDiscounts(coupon, req, rate)
+	if err != nil {
+		return nil, err
+	}
+
+	discountTotal := money.Zero(req.Currency)
+	for _, discount := range discounts {
+		discountTotal, err = discountTotal.Add(discount.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("cannot total discounts: %w", err)
+		}
+	}
+
+	req.CouponCode = code
+	req.Discounts = discounts
+	req.DiscountTotal = discountTotal
+	return coupon, nil
+}
+
+// couponDiscounts computes the discount lines a coupon grants on the order's
+// eligible items. Item prices must already be validated and in req.Currency.
+func couponDiscounts(coupon *orderModel.Coupon, req *orderModel.CreateOrderRequest, rate *big.Rat) ([]orderModel.OrderDiscount, error) {
+	eligible := []orderModel.OrderItem{}
+	eligibleTotal := money.Zero(req.Currency)
+	for _, item := range req.Items {
+		switch coupon.Scope {
+		case orderModel.CouponScopeShop:
+			if coupon.ShopID != req.ShopID {
+				continue
+			}
+		case orderModel.CouponScopeProduct:
+			if coupon.ProductID != item.ProductID {
+				continue
+			}
+		}
+
+		lineTotal, err := item.Price.Mul(int64(item.Quantity))
+		if err != nil {
+			return nil, fmt.Errorf("cannot total product %d: %w", item.ProductID, err)
+		}
+		eligibleTotal, err = eligibleTotal.Add(lineTotal)
+		if err != nil {
+			return nil, fmt.Errorf("cannot total product %d: %w", item.ProductID, err)
+		}
+		eligible = append(eligible, item)
+	}
+
+	if len(eligible) == 0 {
+		return nil, fmt.Errorf("invalid coupon: %s does not apply to this order", coupon.Code)
+	}
+
+	minSpend, err := money.Convert(coupon.MinSpend, req.Currency, rate)
+	if err != nil {
+		return nil, fmt.Errorf("coupon %s: %w", coupon.Code, err)
+	}
+	if eligibleTotal.Amount < minSpend.Amount {
+		return nil, fmt.Errorf("invalid coupon: %s requires a minimum spend of %s", coupon.Code, minSpend)
+	}
+
+	discounts := []orderModel.OrderDiscount{}
+	switch coupon.DiscountType {
+	case orderModel.CouponDiscountPercentage:
+		percent, ok := new(big.Rat).SetString(coupon.PercentOff)
+		if !ok {
+			return nil, fmt.Errorf("coupon %s has an invalid percentage %q", coupon.Code, coupon.PercentOff)
+		}
+		factor := new(big.Rat).Quo(percent, big.NewRat(100, 1))
+
+		// One line per item so returns and refunds can tell what each item cost
+		for _, item := range eligible {
+			lineTotal, err := item.Price.Mul(int64(item.Quantity))
+			if err != nil {
+				return nil, fmt.Errorf("cannot total product %d: %w", item.ProductID, err)
+			}
+			amount, err := lineTotal.MulRat(factor)
+			if err != nil {
+				return nil, fmt.Errorf("cannot apply coupon %s to product %d: %w", coupon.Code, item.ProductID, err)
+			}
+			if !amount.IsPositive() {
+				continue
+			}
+			discounts = append(discounts, orderModel.OrderDiscount{
+				CouponID:    coupon.ID,
+				Code:        coupon.Code,
+				ProductID:   item.ProductID,
+				Description: fmt.Sprintf("%s%% off", money.FormatRate(percent)),
+				Amount:      amount,
+			})
+		}
+	case orderModel.CouponDiscountFixed:
+		amount, err := money.Convert(coupon.AmountOff, req.Currency, rate)
+		if err != nil {
+			return nil, fmt.Errorf("coupon %s: %w", coupon.Code, err)
+		}
+		if amount.Amount > eligibleTotal.Amount {
+			amount = eligibleTotal // never discount more than the eligible items cost
+		}
+		discounts = append(discounts, orderModel.OrderDiscount{
+			CouponID:    coupon.ID,
+			Code:        coupon.Code,
+			ProductID:   coupon.ProductID,
+			Description: fmt.Sprintf("%s off", coupon.AmountOff),
+			Amount:      amount,
+		})
+	default:
+		return nil, fmt.Errorf("coupon %s has an unknown discount type %s", coupon.Code, coupon.DiscountType)
+	}
+
+	return discounts, nil
+}
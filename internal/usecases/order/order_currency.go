@@ -0,0 +1,90 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	productModels "github.com/Christyan39/test-eDot/internal/models/product"
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// orderPricing is the currency conversion applied to one shop's order
+type orderPricing struct {
+	Currency     string   // buyer's currency, item prices and totals are in it
+	ShopCurrency string   // currency the shop's products are priced in
+	Rate         *big.Rat // from ShopCurrency to Currency
+}
+
+// buyerCurrency returns the currency the buyer pays in: the requested one, or
+// the currency of the submitted total when none is requested
+func buyerCurrency(requested string, total money.Money) (string, error) {
+	currency := strings.ToUpper(strings.TrimSpace(requested))
+	if currency == "" {
+		currency = total.Currency
+	}
+	if currency == "" {
+		currency = money.DefaultCurrency()
+	}
+	if !money.IsValidCurrency(currency) {
+		return "", fmt.Errorf("invalid currency %q", requested)
+	}
+	return currency, nil
+}
+
+// pricingFor returns the conversion for a shop's items into the buyer's currency.
+// All products of one order must be priced in the same currency.
+func (u *orderUsecase) pricingFor(currency string, items []orderModel.OrderItem, productMap map[int64]*productModels.Product) (*orderPricing, error) {
+	shopCurrency := ""
+	for _, item := range items {
+		product, exists := productMap[item.ProductID]
+		if !exists {
+			continue // reported by validateOrderItems
+		}
+		productCurrency := product.Price.Currency
+		if shopCurrency == "" {
+			shopCurrency = productCurrency
+		} else if productCurrency != shopCurrency {
+			return nil, fmt.Errorf("invalid order: products are priced in different currencies (%s and %s)", shopCurrency, productCurrency)
+		}
+	}
+	if shopCurrency == "" {
+		shopCurrency = currency
+	}
+
+	rate, err := u.rates.Rate(shopCurrency, currency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid currency %s: %w", currency, err)
+	}
+
+	return &orderPricing{
+		Currency:     currency,
+		ShopCurrency: shopCurrency,
+		Rate:         rate,
+	}, nil
+}
+
+// apply records the conversion on an order request
+func (p *orderPricing) apply(req *orderModel.CreateOrderRequest) {
+	req.Currency = p.Currency
+	req.ShopCurrency = p.ShopCurrency
+	req.ExchangeRate = money.FormatRate(p.Rate)
+}
+
+// GetExchangeRates returns the exchange rates orders are currently priced with
+func (u *orderUsecase) GetExchangeRates(ctx context.Context) *money.ExchangeRates {
+	return u.rates.Snapshot()
+}
+
+// UpdateExchangeRates replaces the exchange rates used for new orders.
+// Existing orders keep the rate recorded when they were placed.
+func (u *orderUsecase) UpdateExchangeRates(ctx context.Context, rates *money.ExchangeRates) error {
+	if err := u.rates.Replace(rates); err != nil {
+		return err
+	}
+	log.Printf("[ExchangeRates] Loaded %d rates against %s", len(rates.Rates), strings.ToUpper(rates.Base))
+	return nil
+}
@@ -0,0 +1,122 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	"github.com/Christyan39/test-eDot/pkg/config"
+)
+
+// expireOrderTx marks a row-locked pending order as expired and queues the
+// release of its held stock, returning the queued release.
+// Both the NSQ consumer and the sweeper expire orders through here.
+func (u *orderUsecase) expireOrderTx(ctx context.Context, tx *sql.Tx, order *orderModel.Order) (int64, error) {
+	return u.releasePendingOrderTx(ctx, tx, order, orderModel.OrderStatusExpired, StatusChangedBySystem, "payment window elapsed")
+}
+
+// SweepExpiredOrders tries to expire up to batchSize pending orders whose expiry
+// has passed. It is a fallback for deferred NSQ messages that were lost or never
+// published. An order that fails to expire is logged and skipped for the rest of
+// the sweep so it cannot hold up the others; the next sweep retries it.
+func (u *orderUsecase) SweepExpiredOrders(ctx context.Context, batchSize int) (int, error) {
+	expired := 0
+	failed := []int64{}
+	for expired+len(failed) < batchSize {
+		orderID, err := u.sweepExpiredOrder(ctx, failed)
+		if err != nil && orderID == 0 {
+			// No order could be picked, e.g. the database is unavailable
+			return expired, err
+		}
+		if err != nil {
+			log.Printf("[SWEEPER] Skipping order %d: %v", orderID, err)
+			failed = append(failed, orderID)
+			continue
+		}
+		if orderID == 0 {
+			break
+		}
+		expired++
+	}
+
+	if len(failed) > 0 {
+		return expired, fmt.Errorf("failed to expire %d orders: %v", len(failed), failed)
+	}
+	return expired, nil
+}
+
+// sweepExpiredOrder expires a single due order, other than the excluded ones, in
+// its own transaction. It returns the ID of the order it picked, 0 when none is due.
+func (u *orderUsecase) sweepExpiredOrder(ctx context.Context, excludeIDs []int64) (int64, error) {
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("[SWEEPER] Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	order, err := u.orderRepo.GetExpiredPendingOrderForUpdateTx(ctx, tx, excludeIDs)
+	if err != nil {
+		return 0, err
+	}
+	if order == nil {
+		err = tx.Commit()
+		return 0, err
+	}
+
+	releaseID, err := u.expireOrderTx(ctx, tx, order)
+	if err != nil {
+		return order.ID, fmt.Errorf("failed to expire order %d: %w", order.ID, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return order.ID, fmt.Errorf("failed to commit transaction for order %d: %w", order.ID, err)
+	}
+
+	u.applyStockAction(releaseID)
+
+	log.Printf("[SWEEPER] Order %d marked as EXPIRED due to non-payment", order.ID)
+	return order.ID, nil
+}
+
+// StartExpirySweeper runs SweepExpiredOrders periodically until the context is cancelled
+func StartExpirySweeper(ctx context.Context, usecase OrderUsecase) {
+	interval, _ := strconv.Atoi(config.GetEnv("ORDER_EXPIRY_SWEEP_INTERVAL_SECONDS", "60"))
+	batchSize, _ := strconv.Atoi(config.GetEnv("ORDER_EXPIRY_SWEEP_BATCH_SIZE", "100"))
+	if interval <= 0 {
+		interval = 60
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	log.Printf("[SWEEPER] Expiry sweeper started (interval %ds, batch size %d)", interval, batchSize)
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("[SWEEPER] Expiry sweeper stopped")
+			return
+		case <-ticker.C:
+			expired, err := usecase.SweepExpiredOrders(ctx, batchSize)
+			if err != nil {
+				log.Printf("[SWEEPER] Sweep error: %v", err)
+			}
+			if expired > 0 {
+				log.Printf("[SWEEPER] Expired %d orders", expired)
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package order
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	orderRepo "github.com/Christyan39/test-eDot/internal/repositories/order"
+)
+
+// orderExportColumns is the header row of the CSV export
+var orderExportColumns = []string{
+	"order_id", "checkout_id", "user_id", "shop_id", "status", "currency",
+	"order_total", "discount_total", "tax_total", "shipping_fee", "coupon_code", "order_created_at",
+	"item_id", "product_id", "quantity", "unit_price", "tax_rate", "tax_amount", "tax_inclusive",
+}
+
+// ExportOrders writes the items of the orders created in the request's date
+// range to w, one row per item. Nothing is written if the request is invalid.
+func (u *orderUsecase) ExportOrders(ctx context.Context, req *orderModel.OrderExportRequest, w io.Writer) (int, error) {
+	return WriteOrderExport(ctx, u.orderRepo, req, w)
+}
+
+// WriteOrderExport streams the order export straight from the repository to w,
+// so exports of any size run in constant memory. It returns the number of rows
+// written. Shared by the export endpoint and the export command.
+func WriteOrderExport(ctx context.Context, repo orderRepo.OrderRepository, req *orderModel.OrderExportRequest, w io.Writer) (int, error) {
+	if err := parseOrderExportRequest(req); err != nil {
+		return 0, err
+	}
+
+	writer := newOrderExportWriter(req.Format, w)
+	rows := 0
+	err := repo.ExportOrderRows(ctx, req.FromTime, req.ToTime, func(row *orderModel.OrderExportRow) error {
+		rows++
+		return writer.Write(row)
+	})
+	if err != nil {
+		log.Printf("Failed to export orders: %v", err)
+		return rows, fmt.Errorf("failed to export orders: %w", err)
+	}
+	if err := writer.Flush(); err != nil {
+		return rows, fmt.Errorf("failed to export orders: %w", err)
+	}
+
+	log.Printf("[OrderExport] Exported %d rows of orders created from %s to %s as %s", rows, req.From, req.To, req.Format)
+	return rows, nil
+}
+
+// parseOrderExportRequest validates the format and parses the inclusive date range
+func parseOrderExportRequest(req *orderModel.OrderExportRequest) error {
+	switch req.Format {
+	case "":
+		req.Format = orderModel.ExportFormatCSV
+	case orderModel.ExportFormatCSV, orderModel.ExportFormatNDJSON:
+	default:
+		return fmt.Errorf("invalid format %q, must be %s or %s", req.Format, orderModel.ExportFormatCSV, orderModel.ExportFormatNDJSON)
+	}
+
+	if req.From == "" || req.To == "" {
+		return fmt.Errorf("from and to must be set")
+	}
+	from, err := time.ParseInLocation("2006-01-02", req.From, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid from, use format YYYY-MM-DD")
+	}
+	to, err := time.ParseInLocation("2006-01-02", req.To, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid to, use format YYYY-MM-DD")
+	}
+	// The end date is inclusive, so export up to the start of the next day
+	to = to.AddDate(0, 0, 1)
+	if !from.Before(to) {
+		return fmt.Errorf("from must be before or equal to to")
+	}
+
+	req.FromTime = from
+	req.ToTime = to
+	return nil
+}
+
+// orderExportWriter encodes export rows in one of the export formats
+type orderExportWriter interface {
+	Write(row *orderModel.OrderExportRow) error
+	Flush() error
+}
+
+func newOrderExportWriter(format string, w io.Writer) orderExportWriter {
+	if format == orderModel.ExportFormatNDJSON {
+		buffered := bufio.NewWriter(w)
+		return &ndjsonOrderExportWriter{buffered: buffered, encoder: json.NewEncoder(buffered)}
+	}
+	return &csvOrderExportWriter{writer: csv.NewWriter(w)}
+}
+
+// csvOrderExportWriter writes a header row followed by one record per row.
+// Amounts are decimals in the currency column's currency.
+type csvOrderExportWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (e *csvOrderExportWriter) Write(row *orderModel.OrderExportRow) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.writer.Write([]string{
+		strconv.FormatInt(row.OrderID, 10),
+		row.CheckoutID,
+		strconv.Itoa(row.UserID),
+		strconv.Itoa(row.ShopID),
+		row.Status,
+		row.Currency,
+		row.OrderTotal.Decimal(),
+		row.DiscountTotal.Decimal(),
+		row.TaxTotal.Decimal(),
+		row.ShippingFee.Decimal(),
+		row.CouponCode,
+		row.OrderCreatedAt.Format(time.RFC3339),
+		strconv.FormatInt(row.ItemID, 10),
+		strconv.FormatInt(row.ProductID, 10),
+		strconv.Itoa(row.Quantity),
+		row.UnitPrice.Decimal(),
+		row.TaxRate,
+		row.TaxAmount.Decimal(),
+		strconv.FormatBool(row.TaxInclusive),
+	})
+}
+
+// Flush writes the header even when there are no rows, so an empty export is
+// still a valid CSV file
+func (e *csvOrderExportWriter) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvOrderExportWriter) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	return e.writer.Write(orderExportColumns)
+}
+
+// ndjsonOrderExportWriter writes one JSON object per line
+type ndjsonOrderExportWriter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+func (e *ndjsonOrderExportWriter) Write(row *orderModel.OrderExportRow) error {
+	return e.encoder.Encode(row)
+}
+
+func (e *ndjsonOrderExportWriter) Flush() error {
+	return e.buffered.Flush()
+}
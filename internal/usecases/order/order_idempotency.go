@@ -0,0 +1,101 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// idempotencyReservationTimeout is how long a key without an order is
+// considered in flight before it may be reclaimed by a retry. Keys that created
+// an order are never reclaimed.
+const idempotencyReservationTimeout = time.Minute
+
+// ReserveIdempotencyKey reserves the key for a new request. It reports whether
+// the caller holds a new reservation, whose ID CreateOrder binds to the order.
+// Otherwise it returns the stored record of the key, already used with the
+// same request: its response to replay, or only the ID of the order created
+// when storing the response failed.
+func (u *orderUsecase) ReserveIdempotencyKey(ctx context.Context, userID int, key, requestHash string) (*orderModel.IdempotencyKey, bool, error) {
+	record := &orderModel.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+	}
+
+	id, err := u.orderRepo.InsertIdempotencyKey(ctx, record)
+	if err != nil {
+		log.Printf("Failed to reserve idempotency key for user %d: %v", userID, err)
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if id != 0 {
+		record.ID = id
+		return record, true, nil
+	}
+
+	existing, err := u.orderRepo.GetIdempotencyKey(ctx, userID, key)
+	if err != nil {
+		// The reservation was released between insert and read, let the client retry
+		return nil, false, fmt.Errorf("idempotency key is still being processed")
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, false, fmt.Errorf("idempotency key was already used with a different request body")
+	}
+
+	if existing.ResponseStatus != 0 || existing.OrderID != 0 {
+		return existing, false, nil
+	}
+
+	if time.Since(existing.CreatedAt) < idempotencyReservationTimeout {
+		return nil, false, fmt.Errorf("idempotency key is still being processed")
+	}
+
+	// No order was created under the key. Should the original request still
+	// complete, binding its order to the deleted reservation fails.
+	log.Printf("Reclaiming stale idempotency key for user %d", userID)
+	if err := u.orderRepo.DeleteIdempotencyKey(ctx, existing.ID); err != nil {
+		return nil, false, fmt.Errorf("failed to reclaim idempotency key: %w", err)
+	}
+	id, err = u.orderRepo.InsertIdempotencyKey(ctx, record)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if id == 0 {
+		return nil, false, fmt.Errorf("idempotency key is still being processed")
+	}
+	record.ID = id
+	return record, true, nil
+}
+
+// CompleteIdempotencyKey stores the response of a request so duplicates replay it
+func (u *orderUsecase) CompleteIdempotencyKey(ctx context.Context, userID int, key string, orderID int64, status int, body []byte) error {
+	err := u.orderRepo.UpdateIdempotencyKeyResponse(ctx, &orderModel.IdempotencyKey{
+		UserID:         userID,
+		Key:            key,
+		OrderID:        orderID,
+		ResponseStatus: status,
+		ResponseBody:   body,
+	})
+	if err != nil {
+		log.Printf("Failed to store idempotent response for user %d: %v", userID, err)
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey frees a reservation after a failed request so it can be
+// retried. A reservation bound to an order is kept.
+func (u *orderUsecase) ReleaseIdempotencyKey(ctx context.Context, userID int, reservationID int64) error {
+	err := u.orderRepo.DeleteIdempotencyKey(ctx, reservationID)
+	if err != nil {
+		log.Printf("Failed to release idempotency key for user %d: %v", userID, err)
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	"github.com/Christyan39/test-eDot/pkg/config"
+	"github.com/Christyan39/test-eDot/pkg/nsq"
+)
+
+// maxRetryBackoff caps the delay between retries of a single outbox message or compensation
+const maxRetryBackoff = 5 * time.Minute
+
+// enqueueOrderEventTx writes an order event to the outbox within the order's transaction.
+// The relay publishes it to NSQ so that consumers receive it at deliverAt.
+func (u *orderUsecase) enqueueOrderEventTx(ctx context.Context, tx *sql.Tx, req *orderModel.CreateOrderRequest, deliverAt time.Time) error {
+	topic := config.GetEnv("NSQ_TOPIC_ORDER", "")
+	if topic == "" {
+		log.Printf("[OUTBOX] NSQ topic not set, skipping event for order %d", req.OrderID)
+		return nil
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order event: %w", err)
+	}
+
+	return u.orderRepo.InsertOutboxMessageTx(ctx, tx, &orderModel.OutboxMessage{
+		OrderID:   req.OrderID,
+		Topic:     topic,
+		Payload:   payload,
+		DeliverAt: deliverAt,
+	})
+}
+
+// RelayOutbox publishes a batch of due outbox messages to NSQ and returns how many were sent.
+// Failed publishes are retried with exponential backoff until maxAttempts is reached.
+func (u *orderUsecase) RelayOutbox(ctx context.Context, batchSize, maxAttempts int) (int, error) {
+	nsqAddress := config.GetEnv("NSQD_HOST", "http://localhost:4151")
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("[OUTBOX] Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	messages, err := u.orderRepo.GetPendingOutboxMessagesForUpdateTx(ctx, tx, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, msg := range messages {
+		deferMs := int(time.Until(msg.DeliverAt).Milliseconds())
+		publishErr := nsq.PublishHTTP(nsqAddress, msg.Topic, json.RawMessage(msg.Payload), deferMs)
+		if publishErr == nil {
+			err = u.orderRepo.MarkOutboxMessageSentTx(ctx, tx, msg.ID)
+			if err != nil {
+				return sent, err
+			}
+			sent++
+			continue
+		}
+
+		attempts := msg.Attempts + 1
+		status := orderModel.OutboxStatusPending
+		if attempts >= maxAttempts {
+			status = orderModel.OutboxStatusFailed
+			log.Printf("[NSQERROR] Giving up on outbox message %d for order %d after %d attempts: %v", msg.ID, msg.OrderID, attempts, publishErr)
+		} else {
+			log.Printf("[NSQERROR] Failed to publish outbox message %d for order %d (attempt %d): %v", msg.ID, msg.OrderID, attempts, publishErr)
+		}
+
+		err = u.orderRepo.MarkOutboxMessageRetryTx(ctx, tx, msg.ID, status, publishErr.Error(), time.Now().Add(retryBackoff(attempts)))
+		if err != nil {
+			return sent, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return sent, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return sent, nil
+}
+
+// retryBackoff returns the exponential delay before the next attempt
+func retryBackoff(attempts int) time.Duration {
+	backoff := time.Second
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+// StartOutboxRelay runs RelayOutbox periodically until the context is cancelled
+func StartOutboxRelay(ctx context.Context, usecase OrderUsecase) {
+	interval, _ := strconv.Atoi(config.GetEnv("ORDER_OUTBOX_RELAY_INTERVAL_MS", "1000"))
+	batchSize, _ := strconv.Atoi(config.GetEnv("ORDER_OUTBOX_BATCH_SIZE", "50"))
+	maxAttempts, _ := strconv.Atoi(config.GetEnv("ORDER_OUTBOX_MAX_ATTEMPTS", "10"))
+	if interval <= 0 {
+		interval = 1000
+	}
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 10
+	}
+
+	log.Printf("[OUTBOX] Relay started (interval %dms, batch size %d, max attempts %d)", interval, batchSize, maxAttempts)
+	ticker := time.NewTicker(time.Duration(interval) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("[OUTBOX] Relay stopped")
+			return
+		case <-ticker.C:
+			// Drain full batches before waiting for the next tick
+			for {
+				sent, err := usecase.RelayOutbox(ctx, batchSize, maxAttempts)
+				if err != nil {
+					log.Printf("[OUTBOX] Relay error: %v", err)
+					break
+				}
+				if sent < batchSize {
+					break
+				}
+			}
+		}
+	}
+}
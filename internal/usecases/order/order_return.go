@@ -0,0 +1,358 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// RequestReturn records a customer's request to return items of their
+// delivered order. Items already in a requested or approved return cannot be
+// returned again.
+func (u *orderUsecase) RequestReturn(ctx context.Context, userID int, orderID int64, req *orderModel.CreateReturnRequest) (*orderModel.OrderReturn, error) {
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return nil, fmt.Errorf("invalid return: reason is required")
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("invalid return: at least one item is required")
+	}
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// Lock the order so concurrent requests cannot return the same items twice
+	order, err := u.orderRepo.GetByIDForUpdateTx(ctx, tx, int(orderID))
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not reveal the existence of orders owned by other users
+	if order.UserID != userID {
+		err = fmt.Errorf("order not found")
+		return nil, err
+	}
+	if order.Status != orderModel.OrderStatusDelivered {
+		err = fmt.Errorf("cannot return items of order %d: order is %s", order.ID, order.Status)
+		return nil, err
+	}
+
+	items, err := u.orderRepo.GetOrderItemsByOrderIDs(ctx, []int64{order.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	returned, err := u.orderRepo.GetReturnedQuantitiesTx(ctx, tx, order.ID, orderModel.ReturnStatusRequested, orderModel.ReturnStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	returnItems, err := returnItemsFor(items, returned, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	orderReturn := &orderModel.OrderReturn{
+		OrderID:   order.ID,
+		UserID:    userID,
+		Status:    orderModel.ReturnStatusRequested,
+		Reason:    req.Reason,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Items:     returnItems,
+	}
+	orderReturn.ID, err = u.orderRepo.InsertReturnTx(ctx, tx, orderReturn)
+	if err != nil {
+		return nil, err
+	}
+	for i := range orderReturn.Items {
+		orderReturn.Items[i].ReturnID = orderReturn.ID
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("[Return] User %d requested return %d of %d item(s) for order %d", userID, orderReturn.ID, len(orderReturn.Items), order.ID)
+	return orderReturn, nil
+}
+
+// ApproveReturn accepts a requested return of an order of the user's shop, puts
+// its items back in stock and records the refund owed for them. Shipping fees
+// are not refunded.
+func (u *orderUsecase) ApproveReturn(ctx context.Context, userID, shopID int, orderID, returnID int64, req *orderModel.ResolveReturnRequest) (*orderModel.OrderReturn, error) {
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	if returnID <= 0 {
+		return nil, fmt.Errorf("invalid return ID")
+	}
+	if err := u.authorizeShopMember(ctx, userID, shopID); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// Lock the order so refunds of concurrent approvals are computed one at a time
+	order, err := u.shopOrderForUpdateTx(ctx, tx, shopID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	orderReturn, err := u.orderRepo.GetReturnForUpdateTx(ctx, tx, order.ID, returnID)
+	if err != nil {
+		return nil, err
+	}
+	if orderReturn.Status != orderModel.ReturnStatusRequested {
+		err = fmt.Errorf("return %d is already %s", orderReturn.ID, orderReturn.Status)
+		return nil, err
+	}
+
+	items, err := u.orderRepo.GetOrderItemsByOrderIDs(ctx, []int64{order.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	discounts, err := u.orderRepo.GetOrderDiscountsByOrderIDs(ctx, []int64{order.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order discounts: %w", err)
+	}
+	refunded, err := u.orderRepo.GetReturnedQuantitiesTx(ctx, tx, order.ID, orderModel.ReturnStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := refundAmount(order.TotalPrice.Currency, items, discounts, refunded, orderReturn.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedAt := time.Now()
+	orderReturn.Status = orderModel.ReturnStatusApproved
+	orderReturn.ResolutionNote = strings.TrimSpace(req.Note)
+	orderReturn.ResolvedAt = &resolvedAt
+	orderReturn.UpdatedAt = resolvedAt
+	err = u.orderRepo.ResolveReturnTx(ctx, tx, orderReturn.ID, orderReturn.Status, orderReturn.ResolutionNote, resolvedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := &orderModel.Refund{
+		ReturnID:  orderReturn.ID,
+		OrderID:   order.ID,
+		Amount:    amount,
+		Status:    orderModel.RefundStatusPending,
+		CreatedAt: resolvedAt,
+	}
+	refund.ID, err = u.orderRepo.InsertRefundTx(ctx, tx, refund)
+	if err != nil {
+		return nil, err
+	}
+	orderReturn.Refund = refund
+
+	// The returned items are put back on sale only once the approval is committed
+	restockID, err := u.queueReturnRestockTx(ctx, tx, order.ID, orderReturn.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	u.applyStockAction(restockID)
+
+	log.Printf("[Return] Return %d of order %d approved by user %d of shop %d, refund %d of %s", orderReturn.ID, order.ID, userID, shopID, refund.ID, refund.Amount)
+	return orderReturn, nil
+}
+
+// RejectReturn declines a requested return of an order of the user's shop. Its
+// items may be requested again.
+func (u *orderUsecase) RejectReturn(ctx context.Context, userID, shopID int, orderID, returnID int64, req *orderModel.ResolveReturnRequest) (*orderModel.OrderReturn, error) {
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	if returnID <= 0 {
+		return nil, fmt.Errorf("invalid return ID")
+	}
+	if err := u.authorizeShopMember(ctx, userID, shopID); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	order, err := u.shopOrderForUpdateTx(ctx, tx, shopID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	orderReturn, err := u.orderRepo.GetReturnForUpdateTx(ctx, tx, order.ID, returnID)
+	if err != nil {
+		return nil, err
+	}
+	if orderReturn.Status != orderModel.ReturnStatusRequested {
+		err = fmt.Errorf("return %d is already %s", orderReturn.ID, orderReturn.Status)
+		return nil, err
+	}
+
+	resolvedAt := time.Now()
+	orderReturn.Status = orderModel.ReturnStatusRejected
+	orderReturn.ResolutionNote = strings.TrimSpace(req.Note)
+	orderReturn.ResolvedAt = &resolvedAt
+	orderReturn.UpdatedAt = resolvedAt
+	err = u.orderRepo.ResolveReturnTx(ctx, tx, orderReturn.ID, orderReturn.Status, orderReturn.ResolutionNote, resolvedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("[Return] Return %d of order %d rejected by user %d of shop %d", orderReturn.ID, order.ID, userID, shopID)
+	return orderReturn, nil
+}
+
+// returnItemsFor validates the requested quantities against what is left to
+// return of each order item
+func returnItemsFor(items []orderModel.OrderItem, returned map[int64]int, requested []orderModel.ReturnItemRequest) ([]orderModel.ReturnItem, error) {
+	remaining := make(map[int64]int, len(items))
+	productIDs := make(map[int64]int64, len(items))
+	for _, item := range items {
+		remaining[item.ID] = item.Quantity - returned[item.ID]
+		productIDs[item.ID] = item.ProductID
+	}
+
+	quantities := make(map[int64]int)
+	orderItemIDs := []int64{}
+	for _, req := range requested {
+		if _, exists := remaining[req.OrderItemID]; !exists {
+			return nil, fmt.Errorf("invalid return: order item %d is not part of the order", req.OrderItemID)
+		}
+		if req.Quantity <= 0 {
+			return nil, fmt.Errorf("invalid return: quantity must be greater than 0 for order item %d", req.OrderItemID)
+		}
+		if _, seen := quantities[req.OrderItemID]; !seen {
+			orderItemIDs = append(orderItemIDs, req.OrderItemID)
+		}
+		quantities[req.OrderItemID] += req.Quantity
+	}
+
+	returnItems := make([]orderModel.ReturnItem, 0, len(orderItemIDs))
+	for _, orderItemID := range orderItemIDs {
+		if quantities[orderItemID] > remaining[orderItemID] {
+			return nil, fmt.Errorf("invalid return: order item %d has %d left to return, requested %d",
+				orderItemID, remaining[orderItemID], quantities[orderItemID])
+		}
+		returnItems = append(returnItems, orderModel.ReturnItem{
+			OrderItemID: orderItemID,
+			ProductID:   productIDs[orderItemID],
+			Quantity:    quantities[orderItemID],
+		})
+	}
+
+	return returnItems, nil
+}
+
+// refundAmount returns what the customer paid for the returned items, from the
+// prices, discounts and tax snapshotted on the order. Each item's paid amount is
+// split by quantity against what was refunded before, so refunding every unit
+// of an item over several returns adds up to exactly what was paid for it.
+func refundAmount(currency string, items []orderModel.OrderItem, discounts []orderModel.OrderDiscount, refunded map[int64]int, returnItems []orderModel.ReturnItem) (money.Money, error) {
+	bases, err := taxBases(items, discounts)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("failed to compute refund: %w", err)
+	}
+
+	itemIndex := make(map[int64]int, len(items))
+	for i, item := range items {
+		itemIndex[item.ID] = i
+	}
+
+	total := money.Zero(currency)
+	for _, returnItem := range returnItems {
+		i, exists := itemIndex[returnItem.OrderItemID]
+		if !exists {
+			return money.Money{}, fmt.Errorf("failed to compute refund: order item %d is not part of the order", returnItem.OrderItemID)
+		}
+		item := items[i]
+
+		paid := bases[i]
+		if !item.TaxInclusive {
+			paid, err = paid.Add(item.TaxAmount)
+			if err != nil {
+				return money.Money{}, fmt.Errorf("failed to compute refund: %w", err)
+			}
+		}
+
+		before := int64(refunded[item.ID])
+		after := before + int64(returnItem.Quantity)
+		quantity := int64(item.Quantity)
+		refundedAfter, err := paid.MulRat(big.NewRat(after, quantity))
+		if err != nil {
+			return money.Money{}, fmt.Errorf("failed to compute refund: %w", err)
+		}
+		refundedBefore, err := paid.MulRat(big.NewRat(before, quantity))
+		if err != nil {
+			return money.Money{}, fmt.Errorf("failed to compute refund: %w", err)
+		}
+		share, err := refundedAfter.Sub(refundedBefore)
+		if err != nil {
+			return money.Money{}, fmt.Errorf("failed to compute refund: %w", err)
+		}
+
+		total, err = total.Add(share)
+		if err != nil {
+			return money.Money{}, fmt.Errorf("failed to compute refund: %w", err)
+		}
+	}
+
+	return total, nil
+}
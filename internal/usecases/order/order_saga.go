@@ -0,0 +1,280 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	productModels "github.com/Christyan39/test-eDot/internal/models/product"
+	"github.com/Christyan39/test-eDot/pkg/config"
+)
+
+// compensationTimeout bounds a single compensating call to the product service
+const compensationTimeout = 10 * time.Second
+
+// compensateHeldStock releases the stock held for an order whose creation failed.
+// If the release fails it is recorded so the compensation worker retries it.
+// The release also cancels the order's hold in the product service, so a hold
+// that timed out here but is still running there is refused instead of leaking.
+func (u *orderUsecase) compensateHeldStock(orderID int64, cause error) {
+	// The request context may already be cancelled, compensation must still run
+	ctx, cancel := context.WithTimeout(context.Background(), compensationTimeout)
+	defer cancel()
+
+	log.Printf("[SAGA] Releasing held stock for order %d after failure: %v", orderID, cause)
+	err := u.productClient.ReleaseHeldStockInBulk(ctx, &productModels.ReleaseHeldStockRequest{
+		OrderID: orderID,
+	})
+	if err == nil {
+		return
+	}
+
+	log.Printf("[SAGA] Failed to release held stock for order %d, scheduling retry: %v", orderID, err)
+	insertErr := u.orderRepo.InsertCompensation(ctx, &orderModel.Compensation{
+		OrderID:     orderID,
+		Action:      orderModel.CompensationActionReleaseHeldStock,
+		Attempts:    1,
+		LastError:   err.Error(),
+		AvailableAt: time.Now().Add(retryBackoff(1)),
+	})
+	if insertErr != nil {
+		log.Printf("[SAGAERROR] Failed to record compensation for order %d, held stock must be released manually: %v", orderID, insertErr)
+	}
+}
+
+// RetryCompensations applies a batch of due compensations and returns how many succeeded.
+// Compensations are retried with exponential backoff until they succeed.
+func (u *orderUsecase) RetryCompensations(ctx context.Context, batchSize int) (int, error) {
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("[SAGA] Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	compensations, err := u.orderRepo.GetPendingCompensationsForUpdateTx(ctx, tx, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+	for _, compensation := range compensations {
+		var applied bool
+		applied, err = u.applyCompensationTx(ctx, tx, &compensation)
+		if err != nil {
+			return done, err
+		}
+		if applied {
+			done++
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return done, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return done, nil
+}
+
+// applyCompensationTx calls the product service for a locked compensation and
+// records the outcome, reporting whether it was applied. Commit, release and
+// restock are no-ops on the product service once done, so a retried call is harmless.
+func (u *orderUsecase) applyCompensationTx(ctx context.Context, tx *sql.Tx, compensation *orderModel.Compensation) (bool, error) {
+	var applyErr error
+	switch compensation.Action {
+	case orderModel.CompensationActionReleaseHeldStock:
+		applyErr = u.productClient.ReleaseHeldStockInBulk(ctx, &productModels.ReleaseHeldStockRequest{
+			OrderID: compensation.OrderID,
+		})
+	case orderModel.CompensationActionCommitHeldStock:
+		applyErr = u.productClient.CommitHeldStockInBulk(ctx, &productModels.CommitHeldStockRequest{
+			OrderID: compensation.OrderID,
+		})
+	case orderModel.CompensationActionRestockOrder:
+		applyErr = u.restockOrder(ctx, compensation.OrderID)
+	case orderModel.CompensationActionRestockReturn:
+		applyErr = u.restockReturn(ctx, compensation.OrderID, compensation.ReturnID)
+	default:
+		applyErr = fmt.Errorf("unknown compensation action %s", compensation.Action)
+	}
+
+	if applyErr == nil {
+		if err := u.orderRepo.MarkCompensationDoneTx(ctx, tx, compensation.ID); err != nil {
+			return false, err
+		}
+		log.Printf("[SAGA] Compensation %d (%s) for order %d applied", compensation.ID, compensation.Action, compensation.OrderID)
+		return true, nil
+	}
+
+	attempts := compensation.Attempts + 1
+	log.Printf("[SAGAERROR] Compensation %d (%s) for order %d failed (attempt %d): %v", compensation.ID, compensation.Action, compensation.OrderID, attempts, applyErr)
+	err := u.orderRepo.MarkCompensationRetryTx(ctx, tx, compensation.ID, applyErr.Error(), time.Now().Add(retryBackoff(attempts)))
+	if err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// restockOrder puts the items of a cancelled paid order back on sale. The
+// restock is referenced by the order, so the product service applies it once.
+func (u *orderUsecase) restockOrder(ctx context.Context, orderID int64) error {
+	items, err := u.orderRepo.GetOrderItemsByOrderIDs(ctx, []int64{orderID})
+	if err != nil {
+		return fmt.Errorf("failed to get order items: %w", err)
+	}
+
+	restockReq := &productModels.RestockRequest{
+		Reference: fmt.Sprintf("order:%d", orderID),
+		Reason:    fmt.Sprintf("order %d cancelled after payment", orderID),
+		Items:     make([]productModels.RestockItem, 0, len(items)),
+	}
+	for _, item := range items {
+		restockReq.Items = append(restockReq.Items, productModels.RestockItem{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+	return u.productClient.Restock(ctx, restockReq)
+}
+
+// restockReturn puts the items of an approved return back on sale. The
+// restock is referenced by the return, so the product service applies it once.
+func (u *orderUsecase) restockReturn(ctx context.Context, orderID, returnID int64) error {
+	returns, err := u.orderRepo.GetReturnsByOrderIDs(ctx, []int64{orderID})
+	if err != nil {
+		return fmt.Errorf("failed to get returns: %w", err)
+	}
+
+	for _, orderReturn := range returns {
+		if orderReturn.ID != returnID {
+			continue
+		}
+
+		restockReq := &productModels.RestockRequest{
+			Reference: fmt.Sprintf("return:%d", orderReturn.ID),
+			Reason:    fmt.Sprintf("return %d of order %d approved", orderReturn.ID, orderID),
+			Items:     make([]productModels.RestockItem, 0, len(orderReturn.Items)),
+		}
+		for _, item := range orderReturn.Items {
+			restockReq.Items = append(restockReq.Items, productModels.RestockItem{
+				ProductID: item.ProductID,
+				VariantID: item.VariantID,
+				Quantity:  item.Quantity,
+			})
+		}
+		return u.productClient.Restock(ctx, restockReq)
+	}
+	return fmt.Errorf("return %d of order %d not found", returnID, orderID)
+}
+
+// queueStockActionTx records a stock action for an order within the
+// transaction changing the order, and returns its ID for applyStockAction
+func (u *orderUsecase) queueStockActionTx(ctx context.Context, tx *sql.Tx, orderID int64, action string) (int64, error) {
+	return u.queueCompensationTx(ctx, tx, &orderModel.Compensation{
+		OrderID: orderID,
+		Action:  action,
+	})
+}
+
+// queueReturnRestockTx records the restock of an approved return within the
+// transaction approving it, and returns its ID for applyStockAction
+func (u *orderUsecase) queueReturnRestockTx(ctx context.Context, tx *sql.Tx, orderID, returnID int64) (int64, error) {
+	return u.queueCompensationTx(ctx, tx, &orderModel.Compensation{
+		OrderID:  orderID,
+		Action:   orderModel.CompensationActionRestockReturn,
+		ReturnID: returnID,
+	})
+}
+
+// queueCompensationTx records a compensation that is due immediately
+func (u *orderUsecase) queueCompensationTx(ctx context.Context, tx *sql.Tx, compensation *orderModel.Compensation) (int64, error) {
+	compensation.AvailableAt = time.Now()
+	id, err := u.orderRepo.InsertCompensationTx(ctx, tx, compensation)
+	if err != nil {
+		return 0, fmt.Errorf("failed to queue %s for order %d: %w", compensation.Action, compensation.OrderID, err)
+	}
+	return id, nil
+}
+
+// applyStockAction applies a queued stock action once the order change that
+// queued it is committed. If it fails, or a worker is applying it already, the
+// compensation worker takes care of it.
+func (u *orderUsecase) applyStockAction(compensationID int64) {
+	// The order change is committed, the stock action must run even if the
+	// request context is cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), compensationTimeout)
+	defer cancel()
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("[SAGAERROR] Failed to begin transaction for compensation %d, leaving it to the worker: %v", compensationID, err)
+		return
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("[SAGA] Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	compensation, err := u.orderRepo.GetPendingCompensationForUpdateTx(ctx, tx, compensationID)
+	if err != nil {
+		log.Printf("[SAGAERROR] Failed to lock compensation %d, leaving it to the worker: %v", compensationID, err)
+		return
+	}
+	if compensation == nil {
+		err = tx.Commit()
+		return
+	}
+
+	_, err = u.applyCompensationTx(ctx, tx, compensation)
+	if err != nil {
+		log.Printf("[SAGAERROR] Failed to record compensation %d, leaving it to the worker: %v", compensationID, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("[SAGAERROR] Failed to commit compensation %d, the worker will retry it: %v", compensationID, err)
+	}
+}
+
+// StartCompensationWorker runs RetryCompensations periodically until the context is cancelled
+func StartCompensationWorker(ctx context.Context, usecase OrderUsecase) {
+	interval, _ := strconv.Atoi(config.GetEnv("ORDER_COMPENSATION_INTERVAL_MS", "5000"))
+	batchSize, _ := strconv.Atoi(config.GetEnv("ORDER_COMPENSATION_BATCH_SIZE", "20"))
+	if interval <= 0 {
+		interval = 5000
+	}
+	if batchSize <= 0 {
+		batchSize = 20
+	}
+
+	log.Printf("[SAGA] Compensation worker started (interval %dms, batch size %d)", interval, batchSize)
+	ticker := time.NewTicker(time.Duration(interval) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("[SAGA] Compensation worker stopped")
+			return
+		case <-ticker.C:
+			if _, err := usecase.RetryCompensations(ctx, batchSize); err != nil {
+				log.Printf("[SAGA] Compensation worker error: %v", err)
+			}
+		}
+	}
+}
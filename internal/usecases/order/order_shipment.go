@@ -0,0 +1,261 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// CreateShipment ships some or all of the remaining items of a confirmed order
+// of the user's shop and moves the order to the status its shipments now give it
+func (u *orderUsecase) CreateShipment(ctx context.Context, userID, shopID int, orderID int64, req *orderModel.CreateShipmentRequest) (*orderModel.Shipment, error) {
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	if err := u.authorizeShopMember(ctx, userID, shopID); err != nil {
+		return nil, err
+	}
+
+	req.Carrier = strings.TrimSpace(req.Carrier)
+	req.TrackingNumber = strings.TrimSpace(req.TrackingNumber)
+	if req.Carrier == "" || req.TrackingNumber == "" {
+		return nil, fmt.Errorf("invalid shipment: carrier and tracking_number are required")
+	}
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// Lock the order so concurrent shipments cannot ship the same items twice
+	order, err := u.shopOrderForUpdateTx(ctx, tx, shopID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != orderModel.OrderStatusConfirmed && order.Status != orderModel.OrderStatusPartiallyShipped {
+		err = fmt.Errorf("cannot ship order %d: order is %s", order.ID, order.Status)
+		return nil, err
+	}
+
+	items, err := u.orderRepo.GetOrderItemsByOrderIDs(ctx, []int64{order.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	shipped, err := u.orderRepo.GetShippedQuantitiesTx(ctx, tx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	shipmentItems, err := shipmentItemsFor(items, shipped, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	shipment := &orderModel.Shipment{
+		OrderID:        order.ID,
+		Carrier:        req.Carrier,
+		TrackingNumber: req.TrackingNumber,
+		Status:         orderModel.ShipmentStatusInTransit,
+		ShippedAt:      time.Now(),
+		Items:          shipmentItems,
+	}
+	shipment.ID, err = u.orderRepo.InsertShipmentTx(ctx, tx, shipment)
+	if err != nil {
+		return nil, err
+	}
+	for i := range shipment.Items {
+		shipment.Items[i].ShipmentID = shipment.ID
+		shipped[shipment.Items[i].OrderItemID] += shipment.Items[i].Quantity
+	}
+
+	err = u.syncFulfillmentStatusTx(ctx, tx, order, items, shipped, fmt.Sprintf("shipment %d via %s (%s)", shipment.ID, shipment.Carrier, shipment.TrackingNumber))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	shipment.CreatedAt = shipment.ShippedAt
+	shipment.UpdatedAt = shipment.ShippedAt
+	log.Printf("[Shipment] Order %d shipped %d item(s) in shipment %d by user %d of shop %d, order is %s", order.ID, len(shipment.Items), shipment.ID, userID, shopID, order.Status)
+	return shipment, nil
+}
+
+// DeliverShipment marks a shipment of an order of the user's shop as delivered,
+// delivering the order once all its items are shipped and every shipment has arrived
+func (u *orderUsecase) DeliverShipment(ctx context.Context, userID, shopID int, orderID, shipmentID int64) (*orderModel.Shipment, error) {
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	if shipmentID <= 0 {
+		return nil, fmt.Errorf("invalid shipment ID")
+	}
+	if err := u.authorizeShopMember(ctx, userID, shopID); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	order, err := u.shopOrderForUpdateTx(ctx, tx, shopID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	shipment, err := u.orderRepo.GetShipmentForUpdateTx(ctx, tx, order.ID, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+	if shipment.Status == orderModel.ShipmentStatusDelivered {
+		err = fmt.Errorf("shipment %d is already delivered", shipment.ID)
+		return nil, err
+	}
+
+	deliveredAt := time.Now()
+	err = u.orderRepo.MarkShipmentDeliveredTx(ctx, tx, shipment.ID, deliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	shipment.Status = orderModel.ShipmentStatusDelivered
+	shipment.DeliveredAt = &deliveredAt
+	shipment.UpdatedAt = deliveredAt
+
+	items, err := u.orderRepo.GetOrderItemsByOrderIDs(ctx, []int64{order.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	shipped, err := u.orderRepo.GetShippedQuantitiesTx(ctx, tx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.syncFulfillmentStatusTx(ctx, tx, order, items, shipped, fmt.Sprintf("shipment %d delivered", shipment.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("[Shipment] Shipment %d of order %d marked delivered by user %d of shop %d, order is %s", shipment.ID, order.ID, userID, shopID, order.Status)
+	return shipment, nil
+}
+
+// shipmentItemsFor validates the requested quantities against what is left to
+// ship of each order item. Without requested items, everything left is shipped.
+func shipmentItemsFor(items []orderModel.OrderItem, shipped map[int64]int, requested []orderModel.ShipmentItemRequest) ([]orderModel.ShipmentItem, error) {
+	remaining := make(map[int64]int, len(items))
+	productIDs := make(map[int64]int64, len(items))
+	for _, item := range items {
+		remaining[item.ID] = item.Quantity - shipped[item.ID]
+		productIDs[item.ID] = item.ProductID
+	}
+
+	if len(requested) == 0 {
+		for _, item := range items {
+			if remaining[item.ID] > 0 {
+				requested = append(requested, orderModel.ShipmentItemRequest{OrderItemID: item.ID, Quantity: remaining[item.ID]})
+			}
+		}
+		if len(requested) == 0 {
+			return nil, fmt.Errorf("invalid shipment: every item of the order is already shipped")
+		}
+	}
+
+	quantities := make(map[int64]int)
+	orderItemIDs := []int64{}
+	for _, req := range requested {
+		if _, exists := remaining[req.OrderItemID]; !exists {
+			return nil, fmt.Errorf("invalid shipment: order item %d is not part of the order", req.OrderItemID)
+		}
+		if req.Quantity <= 0 {
+			return nil, fmt.Errorf("invalid shipment: quantity must be greater than 0 for order item %d", req.OrderItemID)
+		}
+		if _, seen := quantities[req.OrderItemID]; !seen {
+			orderItemIDs = append(orderItemIDs, req.OrderItemID)
+		}
+		quantities[req.OrderItemID] += req.Quantity
+	}
+
+	shipmentItems := make([]orderModel.ShipmentItem, 0, len(orderItemIDs))
+	for _, orderItemID := range orderItemIDs {
+		if quantities[orderItemID] > remaining[orderItemID] {
+			return nil, fmt.Errorf("invalid shipment: order item %d has %d left to ship, requested %d",
+				orderItemID, remaining[orderItemID], quantities[orderItemID])
+		}
+		shipmentItems = append(shipmentItems, orderModel.ShipmentItem{
+			OrderItemID: orderItemID,
+			ProductID:   productIDs[orderItemID],
+			Quantity:    quantities[orderItemID],
+		})
+	}
+
+	return shipmentItems, nil
+}
+
+// syncFulfillmentStatusTx moves a row-locked order to the status derived from
+// its shipments, if it changed
+func (u *orderUsecase) syncFulfillmentStatusTx(ctx context.Context, tx *sql.Tx, order *orderModel.Order, items []orderModel.OrderItem, shipped map[int64]int, reason string) error {
+	undelivered, err := u.orderRepo.CountUndeliveredShipmentsTx(ctx, tx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	status := fulfillmentStatus(items, shipped, undelivered)
+	if status == order.Status {
+		return nil
+	}
+
+	return u.transitionStatusTx(ctx, tx, order, status, StatusChangedByShop(order.ShopID), reason)
+}
+
+// fulfillmentStatus derives a paid order's status from how much of it has
+// shipped and whether all its shipments have arrived
+func fulfillmentStatus(items []orderModel.OrderItem, shipped map[int64]int, undelivered int) string {
+	totalQuantity, shippedQuantity := 0, 0
+	for _, item := range items {
+		totalQuantity += item.Quantity
+		shippedQuantity += shipped[item.ID]
+	}
+
+	switch {
+	case shippedQuantity == 0:
+		return orderModel.OrderStatusConfirmed
+	case shippedQuantity < totalQuantity:
+		return orderModel.OrderStatusPartiallyShipped
+	case undelivered > 0:
+		return orderModel.OrderStatusShipped
+	default:
+		return orderModel.OrderStatusDelivered
+	}
+}
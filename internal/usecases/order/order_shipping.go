@@ -0,0 +1,219 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	productModels "github.com/Christyan39/test-eDot/internal/models/product"
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// gramsPerKg is the weight step shipping fees are charged by
+const gramsPerKg = 1000
+
+// ShippingZone is the fee for parcels shipped from an origin region to a
+// destination country. An empty or "*" origin or destination matches any.
+type ShippingZone struct {
+	Name         string `json:"name"`
+	Origin       string `json:"origin"`      // region the shop sells from
+	Destination  string `json:"destination"` // country of the shipping address
+	Currency     string `json:"currency"`
+	FirstKg      string `json:"first_kg"`      // fee for the first kilogram
+	AdditionalKg string `json:"additional_kg"` // fee for each started kilogram after the first
+}
+
+// ShippingRequest is one parcel to compute the shipping fee for
+type ShippingRequest struct {
+	Origin      string
+	Destination string
+	WeightGrams int
+}
+
+// ShippingCalculator computes shipping fees
+type ShippingCalculator interface {
+	Calculate(ctx context.Context, req *ShippingRequest) (money.Money, error)
+}
+
+// zoneShippingCalculator implements ShippingCalculator with a weight/zone table
+type zoneShippingCalculator struct {
+	zones []parsedShippingZone
+}
+
+type parsedShippingZone struct {
+	ShippingZone
+	firstKg      money.Money
+	additionalKg money.Money
+}
+
+// NewZoneShippingCalculator creates a ShippingCalculator charging the fees of
+// the most specific zone matching each parcel
+func NewZoneShippingCalculator(zones []ShippingZone) (ShippingCalculator, error) {
+	parsed := make([]parsedShippingZone, 0, len(zones))
+	for i, zone := range zones {
+		currency := strings.ToUpper(strings.TrimSpace(zone.Currency))
+		if currency == "" {
+			currency = money.DefaultCurrency()
+		}
+		if !money.IsValidCurrency(currency) {
+			return nil, fmt.Errorf("invalid shipping zone %d (%s): invalid currency %q", i, zone.Name, zone.Currency)
+		}
+
+		firstKg, err := money.Parse(zone.FirstKg, currency)
+		if err != nil || firstKg.IsNegative() {
+			return nil, fmt.Errorf("invalid shipping zone %d (%s): first_kg must be 0 or more", i, zone.Name)
+		}
+		additionalKg := money.Zero(currency)
+		if zone.AdditionalKg != "" {
+			additionalKg, err = money.Parse(zone.AdditionalKg, currency)
+			if err != nil || additionalKg.IsNegative() {
+				return nil, fmt.Errorf("invalid shipping zone %d (%s): additional_kg must be 0 or more", i, zone.Name)
+			}
+		}
+
+		zone.Currency = currency
+		parsed = append(parsed, parsedShippingZone{
+			ShippingZone: zone,
+			firstKg:      firstKg,
+			additionalKg: additionalKg,
+		})
+	}
+	return &zoneShippingCalculator{zones: parsed}, nil
+}
+
+// LoadShippingZonesFile reads a JSON array of shipping zones
+func LoadShippingZonesFile(path string) ([]ShippingZone, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shipping zones: %w", err)
+	}
+
+	var zones []ShippingZone
+	if err := json.Unmarshal(data, &zones); err != nil {
+		return nil, fmt.Errorf("failed to parse shipping zones: %w", err)
+	}
+	return zones, nil
+}
+
+// Calculate returns the fee of the parcel's zone, in the zone's currency.
+// Every started kilogram is charged, with a minimum of one.
+func (c *zoneShippingCalculator) Calculate(ctx context.Context, req *ShippingRequest) (money.Money, error) {
+	zone := c.match(req.Origin, req.Destination)
+	if zone == nil {
+		return money.Money{}, fmt.Errorf("invalid shipping address: no shipping from %s to %s", regionOrUnknown(req.Origin), regionOrUnknown(req.Destination))
+	}
+
+	kilograms := (req.WeightGrams + gramsPerKg - 1) / gramsPerKg
+	if kilograms < 1 {
+		kilograms = 1
+	}
+
+	additional, err := zone.additionalKg.Mul(int64(kilograms - 1))
+	if err != nil {
+		return money.Money{}, err
+	}
+	return zone.firstKg.Add(additional)
+}
+
+// match returns the most specific zone for a parcel. A matching destination
+// weighs more than a matching origin; the first zone wins ties.
+func (c *zoneShippingCalculator) match(origin, destination string) *parsedShippingZone {
+	var best *parsedShippingZone
+	bestScore := -1
+	for i := range c.zones {
+		zone := &c.zones[i]
+		score := 0
+		if !isWildcard(zone.Destination) {
+			if !strings.EqualFold(zone.Destination, destination) {
+				continue
+			}
+			score += 2
+		}
+		if !isWildcard(zone.Origin) {
+			if !strings.EqualFold(zone.Origin, origin) {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = zone, score
+		}
+	}
+	return best
+}
+
+func regionOrUnknown(region string) string {
+	if region == "" {
+		return "an unknown region"
+	}
+	return region
+}
+
+// orderShippingAddress fetches the user's address book entry the order ships
+// to. It returns nil for orders placed without an address.
+func (u *orderUsecase) orderShippingAddress(ctx context.Context, userID int, addressID int64) (*orderModel.ShippingAddress, error) {
+	if addressID == 0 {
+		return nil, nil
+	}
+	if addressID < 0 {
+		return nil, fmt.Errorf("invalid address ID: %d", addressID)
+	}
+
+	address, err := u.userClient.GetUserAddress(ctx, userID, addressID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to fetch shipping address: %w", err)
+	}
+
+	return &orderModel.ShippingAddress{
+		Label:         address.Label,
+		RecipientName: address.RecipientName,
+		Phone:         address.Phone,
+		Line1:         address.Line1,
+		Line2:         address.Line2,
+		City:          address.City,
+		Province:      address.Province,
+		PostalCode:    address.PostalCode,
+		Country:       address.Country,
+	}, nil
+}
+
+// applyShipping records the order's shipping address and computes the fee of
+// shipping its items there in the buyer's currency. Orders without an address
+// have no shipping fee.
+func (u *orderUsecase) applyShipping(ctx context.Context, req *orderModel.CreateOrderRequest, address *orderModel.ShippingAddress, productMap map[int64]*productModels.Product) error {
+	req.ShippingAddress = address
+	req.ShippingFee = money.Zero(req.Currency)
+	if address == nil {
+		return nil
+	}
+
+	parcel := &ShippingRequest{Destination: address.Country}
+	for _, item := range req.Items {
+		product, exists := productMap[item.ProductID]
+		if !exists {
+			continue
+		}
+		if parcel.Origin == "" {
+			parcel.Origin = product.ShopMetadata.Region
+		}
+		parcel.WeightGrams += product.WeightGrams * item.Quantity
+	}
+
+	fee, err := u.shippingCalculator.Calculate(ctx, parcel)
+	if err != nil {
+		return err
+	}
+
+	rate, err := u.rates.Rate(fee.Currency, req.Currency)
+	if err != nil {
+		return fmt.Errorf("invalid currency %s: %w", req.Currency, err)
+	}
+	req.ShippingFee, err = money.Convert(fee, req.Currency, rate)
+	return err
+}
@@ -0,0 +1,204 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// authorizeShopMember checks that the user belongs to the shop they act for
+func (u *orderUsecase) authorizeShopMember(ctx context.Context, userID, shopID int) error {
+	if shopID <= 0 {
+		return fmt.Errorf("invalid shop ID")
+	}
+
+	_, err := u.userClient.GetShopMember(ctx, shopID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not a member") {
+			return fmt.Errorf("forbidden: %w", err)
+		}
+		return fmt.Errorf("failed to verify shop membership: %w", err)
+	}
+	return nil
+}
+
+// ListShopOrders retrieves a shop's orders for one of its members, with the
+// same filters and pagination as the customer's order list
+func (u *orderUsecase) ListShopOrders(ctx context.Context, userID, shopID int, req *orderModel.OrderListRequest) (*orderModel.OrderListResponse, error) {
+	if err := u.authorizeShopMember(ctx, userID, shopID); err != nil {
+		return nil, err
+	}
+
+	req.UserID = 0
+	req.ShopID = shopID
+	return u.ListOrders(ctx, req)
+}
+
+// AcceptShopOrder records that the shop will fulfill a pending or confirmed order
+func (u *orderUsecase) AcceptShopOrder(ctx context.Context, userID, shopID int, orderID int64) (*orderModel.Order, error) {
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	if err := u.authorizeShopMember(ctx, userID, shopID); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	order, err := u.shopOrderForUpdateTx(ctx, tx, shopID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != orderModel.OrderStatusPending && order.Status != orderModel.OrderStatusConfirmed {
+		err = fmt.Errorf("cannot accept order %d: order is %s", order.ID, order.Status)
+		return nil, err
+	}
+	if order.AcceptedAt != nil {
+		err = fmt.Errorf("order %d is already accepted", order.ID)
+		return nil, err
+	}
+
+	acceptedAt := time.Now()
+	err = u.orderRepo.MarkOrderAcceptedTx(ctx, tx, order.ID, acceptedAt)
+	if err != nil {
+		return nil, err
+	}
+	order.AcceptedAt = &acceptedAt
+	order.UpdatedAt = acceptedAt
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("[ShopOrder] Order %d accepted by user %d of shop %d", order.ID, userID, shopID)
+	return order, nil
+}
+
+// RejectShopOrder cancels a pending or confirmed order on behalf of the shop.
+// Pending orders release their held stock; paid orders put their stock back on
+// sale and are refunded in full.
+func (u *orderUsecase) RejectShopOrder(ctx context.Context, userID, shopID int, orderID int64, req *orderModel.RejectOrderRequest) (*orderModel.Order, error) {
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	if err := u.authorizeShopMember(ctx, userID, shopID); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	order, err := u.shopOrderForUpdateTx(ctx, tx, shopID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := "rejected by shop"
+	if strings.TrimSpace(req.Reason) != "" {
+		reason = "rejected by shop: " + strings.TrimSpace(req.Reason)
+	}
+
+	var stockActionID int64
+	switch order.Status {
+	case orderModel.OrderStatusPending:
+		stockActionID, err = u.releasePendingOrderTx(ctx, tx, order, orderModel.OrderStatusCancelled, StatusChangedByShop(shopID), reason)
+	case orderModel.OrderStatusConfirmed:
+		stockActionID, err = u.refundConfirmedOrderTx(ctx, tx, order, StatusChangedByShop(shopID), reason)
+	default:
+		err = fmt.Errorf("cannot reject order %d: order is %s", order.ID, order.Status)
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("Failed to reject order %d: %v", order.ID, err)
+		return nil, fmt.Errorf("failed to reject order: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	u.applyStockAction(stockActionID)
+
+	log.Printf("[ShopOrder] Order %d rejected by user %d of shop %d", order.ID, userID, shopID)
+	return order, nil
+}
+
+// shopOrderForUpdateTx locks an order of the shop. Orders of other shops are
+// reported as not found.
+func (u *orderUsecase) shopOrderForUpdateTx(ctx context.Context, tx *sql.Tx, shopID int, orderID int64) (*orderModel.Order, error) {
+	order, err := u.orderRepo.GetByIDForUpdateTx(ctx, tx, int(orderID))
+	if err != nil {
+		return nil, err
+	}
+	if order.ShopID != shopID {
+		return nil, fmt.Errorf("order not found")
+	}
+	return order, nil
+}
+
+// refundConfirmedOrderTx cancels a row-locked paid order that has not shipped,
+// records a refund of everything paid and queues the restock of its items. It
+// returns the queued restock for applyStockAction once the caller commits.
+func (u *orderUsecase) refundConfirmedOrderTx(ctx context.Context, tx *sql.Tx, order *orderModel.Order, changedBy, reason string) (int64, error) {
+	err := u.transitionStatusTx(ctx, tx, order, orderModel.OrderStatusCancelled, changedBy, reason)
+	if err != nil {
+		return 0, err
+	}
+
+	err = u.orderRepo.ReleaseCouponRedemptionsTx(ctx, tx, order.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	refund := &orderModel.Refund{
+		OrderID:   order.ID,
+		Amount:    order.TotalPrice,
+		Status:    orderModel.RefundStatusPending,
+		CreatedAt: time.Now(),
+	}
+	refund.ID, err = u.orderRepo.InsertRefundTx(ctx, tx, refund)
+	if err != nil {
+		return 0, err
+	}
+	order.Refunds = append(order.Refunds, *refund)
+
+	items, err := u.orderRepo.GetOrderItemsByOrderIDs(ctx, []int64{order.ID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get order items: %w", err)
+	}
+	order.Items = items
+
+	// The stock was committed when the order was paid. It is put back on sale
+	// only once the cancellation is committed.
+	return u.queueStockActionTx(ctx, tx, order.ID, orderModel.CompensationActionRestockOrder)
+}
@@ -0,0 +1,80 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+)
+
+// StatusChangedBySystem identifies status changes made by background processes
+const StatusChangedBySystem = "system"
+
+// orderStatusTransitions lists the statuses an order may move to from each status.
+// Statuses without an entry are terminal.
+var orderStatusTransitions = map[string][]string{
+	orderModel.OrderStatusPending: {
+		orderModel.OrderStatusConfirmed,
+		orderModel.OrderStatusCancelled,
+		orderModel.OrderStatusExpired,
+	},
+	orderModel.OrderStatusConfirmed: {
+		orderModel.OrderStatusPartiallyShipped,
+		orderModel.OrderStatusShipped,
+		orderModel.OrderStatusCancelled,
+	},
+	orderModel.OrderStatusPartiallyShipped: {
+		orderModel.OrderStatusShipped,
+	},
+	orderModel.OrderStatusShipped: {
+		orderModel.OrderStatusDelivered,
+	},
+}
+
+// StatusChangedByUser identifies status changes made by the given user
+func StatusChangedByUser(userID int) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// StatusChangedByShop identifies status changes made by the given shop
+func StatusChangedByShop(shopID int) string {
+	return fmt.Sprintf("shop:%d", shopID)
+}
+
+// canTransitionStatus reports whether an order may move from one status to another
+func canTransitionStatus(from, to string) bool {
+	for _, next := range orderStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionStatusTx validates and applies a status change to a row-locked order,
+// recording it in the status history within the same transaction
+func (u *orderUsecase) transitionStatusTx(ctx context.Context, tx *sql.Tx, order *orderModel.Order, to, changedBy, reason string) error {
+	if !canTransitionStatus(order.Status, to) {
+		return fmt.Errorf("invalid status transition from %s to %s for order %d", order.Status, to, order.ID)
+	}
+
+	err := u.orderRepo.UpdateOrderStatusTx(ctx, tx, order.ID, to)
+	if err != nil {
+		return err
+	}
+
+	err = u.orderRepo.InsertStatusHistoryTx(ctx, tx, &orderModel.OrderStatusHistory{
+		OrderID:    order.ID,
+		FromStatus: order.Status,
+		ToStatus:   to,
+		ChangedBy:  changedBy,
+		Reason:     reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	order.Status = to
+	return nil
+}
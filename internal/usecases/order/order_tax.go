@@ -0,0 +1,262 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	orderModel "github.com/Christyan39/test-eDot/internal/models/order"
+	productModels "github.com/Christyan39/test-eDot/internal/models/product"
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// TaxRule is the tax charged on products of a tax category sold from a region.
+// An empty or "*" region or category matches any.
+type TaxRule struct {
+	Name      string `json:"name"`
+	Region    string `json:"region"`
+	Category  string `json:"category"`
+	Rate      string `json:"rate"`      // percentage, e.g. "11"
+	Inclusive bool   `json:"inclusive"` // the tax is part of the product price rather than added to it
+}
+
+// TaxLine is one taxable order line. Amount is what the buyer pays for the
+// line after discounts, in the order's currency.
+type TaxLine struct {
+	ProductID int64
+	Category  string
+	Amount    money.Money
+}
+
+// TaxRequest is the set of lines of one order to compute tax for
+type TaxRequest struct {
+	Region string // region the shop sells from
+	Lines  []TaxLine
+}
+
+// TaxLineResult is the tax computed for the TaxLine at the same index
+type TaxLineResult struct {
+	Rate      string // percentage, "0" when no rule applies
+	Inclusive bool
+	Amount    money.Money
+}
+
+// TaxCalculator computes the tax of order lines
+type TaxCalculator interface {
+	Calculate(ctx context.Context, req *TaxRequest) ([]TaxLineResult, error)
+}
+
+// ruleTaxCalculator implements TaxCalculator with a static list of rules
+type ruleTaxCalculator struct {
+	rules []parsedTaxRule
+}
+
+type parsedTaxRule struct {
+	TaxRule
+	rate *big.Rat // fraction, e.g. 0.11
+}
+
+// NewRuleTaxCalculator creates a TaxCalculator applying the most specific
+// matching rule to each line. Lines no rule matches are not taxed.
+func NewRuleTaxCalculator(rules []TaxRule) (TaxCalculator, error) {
+	parsed := make([]parsedTaxRule, 0, len(rules))
+	for i, rule := range rules {
+		percent, ok := new(big.Rat).SetString(strings.TrimSpace(rule.Rate))
+		if !ok || percent.Sign() < 0 || percent.Cmp(big.NewRat(100, 1)) > 0 {
+			return nil, fmt.Errorf("invalid tax rule %d (%s): rate must be between 0 and 100", i, rule.Name)
+		}
+		rule.Rate = money.FormatRate(percent)
+		parsed = append(parsed, parsedTaxRule{
+			TaxRule: rule,
+			rate:    new(big.Rat).Quo(percent, big.NewRat(100, 1)),
+		})
+	}
+	return &ruleTaxCalculator{rules: parsed}, nil
+}
+
+// LoadTaxRulesFile reads a JSON array of tax rules
+func LoadTaxRulesFile(path string) ([]TaxRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tax rules: %w", err)
+	}
+
+	var rules []TaxRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse tax rules: %w", err)
+	}
+	return rules, nil
+}
+
+// Calculate computes the tax of each line from its matching rule
+func (c *ruleTaxCalculator) Calculate(ctx context.Context, req *TaxRequest) ([]TaxLineResult, error) {
+	results := make([]TaxLineResult, len(req.Lines))
+	for i, line := range req.Lines {
+		rule := c.match(req.Region, line.Category)
+		if rule == nil {
+			results[i] = TaxLineResult{Rate: "0", Amount: money.Zero(line.Amount.Currency)}
+			continue
+		}
+
+		factor := rule.rate
+		if rule.Inclusive {
+			// The amount already contains the tax: tax = amount * r / (1 + r)
+			factor = new(big.Rat).Quo(rule.rate, new(big.Rat).Add(big.NewRat(1, 1), rule.rate))
+		}
+		amount, err := line.Amount.MulRat(factor)
+		if err != nil {
+			return nil, fmt.Errorf("cannot tax %s at %s: %w", line.Amount, rule.Rate, err)
+		}
+		results[i] = TaxLineResult{
+			Rate:      rule.Rate,
+			Inclusive: rule.Inclusive,
+			Amount:    amount,
+		}
+	}
+	return results, nil
+}
+
+// match returns the most specific rule for a region and category. A matching
+// region weighs more than a matching category; the first rule wins ties.
+func (c *ruleTaxCalculator) match(region, category string) *parsedTaxRule {
+	var best *parsedTaxRule
+	bestScore := -1
+	for i := range c.rules {
+		rule := &c.rules[i]
+		score := 0
+		if !isWildcard(rule.Region) {
+			if !strings.EqualFold(rule.Region, region) {
+				continue
+			}
+			score += 2
+		}
+		if !isWildcard(rule.Category) {
+			if !strings.EqualFold(rule.Category, category) {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = rule, score
+		}
+	}
+	return best
+}
+
+func isWildcard(value string) bool {
+	return value == "" || value == "*"
+}
+
+// applyTax computes the tax of the order's items after discounts, setting it on
+// each item and on req.TaxTotal. It returns the exclusive part of the tax, which
+// is added to the order total.
+func (u *orderUsecase) applyTax(ctx context.Context, req *orderModel.CreateOrderRequest, productMap map[int64]*productModels.Product) (money.Money, error) {
+	bases, err := taxBases(req.Items, req.Discounts)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	taxReq := &TaxRequest{Lines: make([]TaxLine, len(req.Items))}
+	for i, item := range req.Items {
+		category := productModels.DefaultTaxCategory
+		if product, exists := productMap[item.ProductID]; exists {
+			if taxReq.Region == "" {
+				taxReq.Region = product.ShopMetadata.Region
+			}
+			if product.TaxCategory != "" {
+				category = product.TaxCategory
+			}
+		}
+		taxReq.Lines[i] = TaxLine{ProductID: item.ProductID, Category: category, Amount: bases[i]}
+	}
+
+	results, err := u.taxCalculator.Calculate(ctx, taxReq)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("failed to calculate tax: %w", err)
+	}
+	if len(results) != len(req.Items) {
+		return money.Money{}, fmt.Errorf("failed to calculate tax: got %d results for %d items", len(results), len(req.Items))
+	}
+
+	taxTotal := money.Zero(req.Currency)
+	exclusiveTax := money.Zero(req.Currency)
+	for i, result := range results {
+		req.Items[i].TaxRate = result.Rate
+		req.Items[i].TaxAmount = result.Amount
+		req.Items[i].TaxInclusive = result.Inclusive
+
+		taxTotal, err = taxTotal.Add(result.Amount)
+		if err != nil {
+			return money.Money{}, fmt.Errorf("cannot total tax of product %d: %w", req.Items[i].ProductID, err)
+		}
+		if !result.Inclusive {
+			exclusiveTax, err = exclusiveTax.Add(result.Amount)
+			if err != nil {
+				return money.Money{}, fmt.Errorf("cannot total tax of product %d: %w", req.Items[i].ProductID, err)
+			}
+		}
+	}
+
+	req.TaxTotal = taxTotal
+	return exclusiveTax, nil
+}
+
+// taxBases returns what the buyer pays for each item after discounts. Product
+// discounts are taken from that product's items, order discounts are spread over
+// all items in proportion to their price.
+func taxBases(items []orderModel.OrderItem, discounts []orderModel.OrderDiscount) ([]money.Money, error) {
+	bases := make([]money.Money, len(items))
+	for i, item := range items {
+		base, err := item.Price.Mul(int64(item.Quantity))
+		if err != nil {
+			return nil, fmt.Errorf("cannot total product %d: %w", item.ProductID, err)
+		}
+		bases[i] = base
+	}
+
+	for _, discount := range discounts {
+		indexes := []int{}
+		for i, item := range items {
+			if discount.ProductID == 0 || discount.ProductID == item.ProductID {
+				indexes = append(indexes, i)
+			}
+		}
+
+		for j, share := range allocate(discount.Amount, bases, indexes) {
+			var err error
+			bases[indexes[j]], err = bases[indexes[j]].Sub(share)
+			if err != nil {
+				return nil, fmt.Errorf("cannot apply discount %s: %w", discount.Code, err)
+			}
+		}
+	}
+
+	return bases, nil
+}
+
+// allocate splits amount over the given lines in proportion to their value.
+// Rounding leftovers go to the last line so the shares add up to amount.
+func allocate(amount money.Money, lines []money.Money, indexes []int) []money.Money {
+	var total int64
+	for _, i := range indexes {
+		total += lines[i].Amount
+	}
+
+	shares := make([]money.Money, len(indexes))
+	remaining := amount.Amount
+	for j, i := range indexes {
+		share := remaining
+		if j < len(indexes)-1 && total > 0 {
+			share = new(big.Int).Div(
+				new(big.Int).Mul(big.NewInt(amount.Amount), big.NewInt(lines[i].Amount)),
+				big.NewInt(total),
+			).Int64()
+		}
+		shares[j] = money.New(share, amount.Currency)
+		remaining -= share
+	}
+	return shares
+}
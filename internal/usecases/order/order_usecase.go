@@ -2,8 +2,10 @@ package order
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"strconv"
 	"time"
@@ -13,39 +15,72 @@ import (
 	productModels "github.com/Christyan39/test-eDot/internal/models/product"
 	orderRepo "github.com/Christyan39/test-eDot/internal/repositories/order"
 	"github.com/Christyan39/test-eDot/pkg/config"
-	"github.com/Christyan39/test-eDot/pkg/nsq"
+	"github.com/Christyan39/test-eDot/pkg/money"
 	nsqio "github.com/nsqio/go-nsq"
 )
 
 // OrderUsecase defines the order business logic interface
 type OrderUsecase interface {
-	CreateOrder(ctx context.Context, req *orderModel.CreateOrderRequest) error
+	CreateOrder(ctx context.Context, req *orderModel.CreateOrderRequest) (*orderModel.Order, error)
+	GetOrder(ctx context.Context, userID int, orderID int64) (*orderModel.Order, error)
+	ListOrders(ctx context.Context, req *orderModel.OrderListRequest) (*orderModel.OrderListResponse, error)
+	GetOrderHistory(ctx context.Context, userID int, orderID int64) ([]orderModel.OrderStatusHistory, error)
+	PayOrder(ctx context.Context, userID int, orderID int64, req *orderModel.PayOrderRequest) (*orderModel.Order, error)
+	CancelOrder(ctx context.Context, userID int, orderID int64, req *orderModel.CancelOrderRequest) (*orderModel.Order, error)
 	ProcessOrderMessage(msg *nsqio.Message) error
+	ReserveIdempotencyKey(ctx context.Context, userID int, key, requestHash string) (*orderModel.IdempotencyKey, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, userID int, key string, orderID int64, status int, body []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, userID int, reservationID int64) error
+	RelayOutbox(ctx context.Context, batchSize, maxAttempts int) (int, error)
+	RetryCompensations(ctx context.Context, batchSize int) (int, error)
+	SweepExpiredOrders(ctx context.Context, batchSize int) (int, error)
+	Checkout(ctx context.Context, req *orderModel.CheckoutRequest) (*orderModel.OrderGroup, error)
+	CreateCoupon(ctx context.Context, req *orderModel.CreateCouponRequest) (*orderModel.Coupon, error)
+	CreateShipment(ctx context.Context, userID, shopID int, orderID int64, req *orderModel.CreateShipmentRequest) (*orderModel.Shipment, error)
+	DeliverShipment(ctx context.Context, userID, shopID int, orderID, shipmentID int64) (*orderModel.Shipment, error)
+	RequestReturn(ctx context.Context, userID int, orderID int64, req *orderModel.CreateReturnRequest) (*orderModel.OrderReturn, error)
+	ApproveReturn(ctx context.Context, userID, shopID int, orderID, returnID int64, req *orderModel.ResolveReturnRequest) (*orderModel.OrderReturn, error)
+	RejectReturn(ctx context.Context, userID, shopID int, orderID, returnID int64, req *orderModel.ResolveReturnRequest) (*orderModel.OrderReturn, error)
+	ListShopOrders(ctx context.Context, userID, shopID int, req *orderModel.OrderListRequest) (*orderModel.OrderListResponse, error)
+	AcceptShopOrder(ctx context.Context, userID, shopID int, orderID int64) (*orderModel.Order, error)
+	RejectShopOrder(ctx context.Context, userID, shopID int, orderID int64, req *orderModel.RejectOrderRequest) (*orderModel.Order, error)
+	ExportOrders(ctx context.Context, req *orderModel.OrderExportRequest, w io.Writer) (int, error)
+	GetExchangeRates(ctx context.Context) *money.ExchangeRates
+	UpdateExchangeRates(ctx context.Context, rates *money.ExchangeRates) error
 }
 
 // orderUsecase implements OrderUsecase
 type orderUsecase struct {
-	orderRepo     orderRepo.OrderRepository
-	productClient clients.ProductServiceClientInterface
+	orderRepo          orderRepo.OrderRepository
+	productClient      clients.ProductServiceClientInterface
+	userClient         clients.UserServiceClientInterface
+	rates              *money.RateTable
+	taxCalculator      TaxCalculator
+	shippingCalculator ShippingCalculator
 }
 
 // NewOrderUsecase creates a new order usecase
-func NewOrderUsecase(orderRepo orderRepo.OrderRepository) OrderUsecase {
+func NewOrderUsecase(orderRepo orderRepo.OrderRepository, rates *money.RateTable, taxCalculator TaxCalculator, shippingCalculator ShippingCalculator) OrderUsecase {
 	productServiceURL := config.GetEnv("PRODUCT_SERVICE_URL", "http://localhost:8081")
 	apiKey := config.GetEnv("PRODUCT_SERVICE_API_KEY", "")
+	userServiceURL := config.GetEnv("USER_SERVICE_URL", "http://localhost:8080")
+	userAPIKey := config.GetEnv("USER_SERVICE_API_KEY", "")
 
 	return &orderUsecase{
-		orderRepo:     orderRepo,
-		productClient: clients.NewProductServiceClient(productServiceURL, apiKey),
+		orderRepo:          orderRepo,
+		productClient:      clients.NewProductServiceClient(productServiceURL, apiKey),
+		userClient:         clients.NewUserServiceClient(userServiceURL, userAPIKey),
+		rates:              rates,
+		taxCalculator:      taxCalculator,
+		shippingCalculator: shippingCalculator,
 	}
 }
 
 // CreateOrder creates a new order with multiple products
-func (u *orderUsecase) CreateOrder(ctx context.Context, req *orderModel.CreateOrderRequest) error {
-	// Validate each item and collect product information via HTTP calls
-	totalPrice := 0.0
-	expDuration, _ := strconv.Atoi(config.GetEnv("ORDER_EXPIRATION_DURATION_SECONDS", "1"))
-	expDurationMs := expDuration * 1000 // convert to milliseconds
+func (u *orderUsecase) CreateOrder(ctx context.Context, req *orderModel.CreateOrderRequest) (*orderModel.Order, error) {
+	if req.OrderData != nil {
+		req.OrderData.Version = orderModel.OrderDataVersion
+	}
 
 	itemIDs := make([]int64, 0, len(req.Items))
 	for _, item := range req.Items {
@@ -56,42 +91,42 @@ func (u *orderUsecase) CreateOrder(ctx context.Context, req *orderModel.CreateOr
 	products, err := u.productClient.GetProductByIDs(itemIDs)
 	if err != nil {
 		log.Printf("Failed to fetch products from Product Service: %v", err)
-		return fmt.Errorf("failed to fetch product details")
+		return nil, fmt.Errorf("failed to fetch product details")
 	}
 
 	productMap := make(map[int64]*productModels.Product)
 	for i, product := range products {
 		if product.ShopID != req.ShopID {
-			return fmt.Errorf("product %d does not belong to shop %d", product.ID, req.ShopID)
+			return nil, fmt.Errorf("product %d does not belong to shop %d", product.ID, req.ShopID)
 		}
 		productMap[product.ID] = &products[i]
 	}
 
-	for _, item := range req.Items {
-		product := productMap[item.ProductID]
-
-		if item.ProductID <= 0 {
-			return fmt.Errorf("invalid product ID: %d", item.ProductID)
-		}
-		if item.Quantity <= 0 {
-			return fmt.Errorf("quantity must be greater than 0 for product %d", item.ProductID)
-		}
-		// Check stock availability
-		if item.Quantity > product.Stock {
-			return fmt.Errorf("insufficient stock for product %d: requested %d, available %d",
-				item.ProductID, item.Quantity, product.Stock)
-		}
+	currency, err := buyerCurrency(req.Currency, req.TotalPrice)
+	if err != nil {
+		return nil, err
+	}
 
-		if item.Price != product.Price {
-			return fmt.Errorf("price mismatch for product %d: expected %.2f, got %.2f",
-				item.ProductID, product.Price, item.Price)
-		}
+	pricing, err := u.pricingFor(currency, req.Items, productMap)
+	if err != nil {
+		return nil, err
+	}
 
-		totalPrice += product.Price * float64(item.Quantity)
+	// Validate each item against the product information
+	subtotal, err := validateOrderItems(req.Items, productMap, pricing)
+	if err != nil {
+		return nil, err
 	}
+	pricing.apply(req)
 
-	if totalPrice != req.TotalPrice {
-		return fmt.Errorf("total price mismatch: expected %.2f, got %.2f", totalPrice, req.TotalPrice)
+	// The address is copied onto the order so address book edits do not change it
+	address, err := u.orderShippingAddress(ctx, req.UserID, req.AddressID)
+	if err != nil {
+		return nil, err
+	}
+	err = u.applyShipping(ctx, req, address, productMap)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create orders for all products
@@ -99,7 +134,7 @@ func (u *orderUsecase) CreateOrder(ctx context.Context, req *orderModel.CreateOr
 	tx, err := u.orderRepo.BeginTx(ctx)
 	if err != nil {
 		log.Printf("Failed to begin transaction: %v", err)
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
 	defer func() {
@@ -110,20 +145,253 @@ func (u *orderUsecase) CreateOrder(ctx context.Context, req *orderModel.CreateOr
 		}
 	}()
 
-	req.ExpiresAt = time.Now().Add(time.Duration(expDuration) * time.Millisecond)
+	// The coupon is applied inside the transaction so its usage is counted atomically
+	var coupon *orderModel.Coupon
+	totalPrice := subtotal
+	req.DiscountTotal = money.Zero(req.Currency)
+	if req.CouponCode != "" {
+		coupon, err = u.applyCouponTx(ctx, tx, req)
+		if err != nil {
+			return nil, err
+		}
+		totalPrice, err = subtotal.Sub(req.DiscountTotal)
+		if err != nil {
+			return nil, fmt.Errorf("cannot apply discount: %w", err)
+		}
+	}
+
+	// Tax is computed on the discounted prices; only exclusive tax adds to the total
+	var exclusiveTax money.Money
+	exclusiveTax, err = u.applyTax(ctx, req, productMap)
+	if err != nil {
+		return nil, err
+	}
+	totalPrice, err = totalPrice.Add(exclusiveTax)
+	if err != nil {
+		return nil, fmt.Errorf("cannot apply tax: %w", err)
+	}
+	totalPrice, err = totalPrice.Add(req.ShippingFee)
+	if err != nil {
+		return nil, fmt.Errorf("cannot apply shipping fee: %w", err)
+	}
+
+	if !totalPrice.Equal(req.TotalPrice) {
+		err = fmt.Errorf("total price mismatch: expected %s, got %s", totalPrice, req.TotalPrice)
+		return nil, err
+	}
+
+	createdAt := time.Now()
+	req.ExpiresAt = createdAt.Add(orderExpiration())
+	holdStockRequest, err := u.insertOrderTx(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+	orderID := req.OrderID
+
+	if req.IdempotencyKeyID != 0 {
+		err = u.orderRepo.BindIdempotencyKeyOrderTx(ctx, tx, req.IdempotencyKeyID, orderID)
+		if err != nil {
+			log.Printf("Failed to bind idempotency key to order %d: %v", orderID, err)
+			return nil, err
+		}
+	}
+
+	if coupon != nil {
+		err = u.orderRepo.InsertCouponRedemptionTx(ctx, tx, &orderModel.CouponRedemption{
+			CouponID: coupon.ID,
+			UserID:   req.UserID,
+			OrderID:  orderID,
+			Amount:   req.DiscountTotal,
+		})
+		if err != nil {
+			log.Printf("Failed to record coupon redemption: %v", err)
+			return nil, fmt.Errorf("failed to record coupon redemption: %w", err)
+		}
+	}
+
+	err = u.productClient.HoldStockInBulk(ctx, holdStockRequest)
+
+	// From here on the product service may hold stock for this order, even when
+	// the hold call itself failed (e.g. timeout), so any failure must release it
+	defer func() {
+		if err != nil {
+			u.compensateHeldStock(orderID, err)
+		}
+	}()
+
+	if err != nil {
+		log.Printf("Failed to hold stock in Product Service: %v", err)
+		return nil, fmt.Errorf("failed to hold stock in Product Service: %w", err)
+	}
+
+	// Queue the order event in the same transaction; the outbox relay publishes it to NSQ
+	err = u.enqueueOrderEventTx(ctx, tx, req, req.ExpiresAt)
+	if err != nil {
+		log.Printf("Failed to enqueue order event: %v", err)
+		return nil, fmt.Errorf("failed to enqueue order event: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	order := &orderModel.Order{
+		ID:              orderID,
+		UserID:          req.UserID,
+		ShopID:          req.ShopID,
+		TotalPrice:      totalPrice,
+		ShopCurrency:    req.ShopCurrency,
+		ExchangeRate:    req.ExchangeRate,
+		CouponCode:      req.CouponCode,
+		DiscountTotal:   req.DiscountTotal,
+		TaxTotal:        req.TaxTotal,
+		AddressID:       req.AddressID,
+		ShippingAddress: req.ShippingAddress,
+		ShippingFee:     req.ShippingFee,
+		Status:          orderModel.OrderStatusPending,
+		OrderData:       req.OrderData,
+		CreatedAt:       createdAt,
+		UpdatedAt:       createdAt,
+		ExpiresAt:       req.ExpiresAt,
+		Items:           req.Items,
+		Discounts:       req.Discounts,
+	}
+
+	return order, nil
+}
+
+// orderExpiration returns how long a pending order holds its stock before it expires
+func orderExpiration() time.Duration {
+	expDuration, _ := strconv.Atoi(config.GetEnv("ORDER_EXPIRATION_DURATION_SECONDS", "1"))
+	return time.Duration(expDuration) * time.Second
+}
+
+// validateOrderItems checks the requested items against the current product
+// information and returns their total price in the buyer's currency. Each
+// product or variant may only be listed once, as stock is held per line.
+func validateOrderItems(items []orderModel.OrderItem, productMap map[int64]*productModels.Product, pricing *orderPricing) (money.Money, error) {
+	totalPrice := money.Zero(pricing.Currency)
+	seen := make(map[[2]int64]bool, len(items))
+	for _, item := range items {
+		if item.ProductID <= 0 {
+			return money.Money{}, fmt.Errorf("invalid product ID: %d", item.ProductID)
+		}
+		if item.Quantity <= 0 {
+			return money.Money{}, fmt.Errorf("quantity must be greater than 0 for product %d", item.ProductID)
+		}
+		key := [2]int64{item.ProductID, item.VariantID}
+		if seen[key] {
+			if item.VariantID != 0 {
+				return money.Money{}, fmt.Errorf("invalid items: variant %d of product %d is listed more than once", item.VariantID, item.ProductID)
+			}
+			return money.Money{}, fmt.Errorf("invalid items: product %d is listed more than once", item.ProductID)
+		}
+		seen[key] = true
+
+		product, exists := productMap[item.ProductID]
+		if !exists {
+			return money.Money{}, fmt.Errorf("product %d not found", item.ProductID)
+		}
+
+		// Products with variants are stocked and priced per variant
+		stock, basePrice := product.Stock, product.Price
+		if len(product.Variants) > 0 || item.VariantID != 0 {
+			variant, err := orderItemVariant(item, product)
+			if err != nil {
+				return money.Money{}, err
+			}
+			stock = variant.Stock
+			if variant.Price != nil {
+				basePrice = *variant.Price
+			}
+		}
+
+		// Check stock availability
+		if item.Quantity > stock {
+			return money.Money{}, fmt.Errorf("insufficient stock for product %d: requested %d, available %d",
+				item.ProductID, item.Quantity, stock)
+		}
+
+		// Items are priced in the buyer's currency at the current exchange rate
+		price, err := money.Convert(basePrice, pricing.Currency, pricing.Rate)
+		if err != nil {
+			return money.Money{}, fmt.Errorf("cannot price product %d: %w", item.ProductID, err)
+		}
+		if !item.Price.Equal(price) {
+			return money.Money{}, fmt.Errorf("price mismatch for product %d: expected %s, got %s",
+				item.ProductID, price, item.Price)
+		}
+
+		lineTotal, err := price.Mul(int64(item.Quantity))
+		if err != nil {
+			return money.Money{}, fmt.Errorf("cannot total product %d: %w", item.ProductID, err)
+		}
+		totalPrice, err = totalPrice.Add(lineTotal)
+		if err != nil {
+			return money.Money{}, fmt.Errorf("cannot total product %d: %w", item.ProductID, err)
+		}
+	}
+
+	return totalPrice, nil
+}
+
+// orderItemVariant returns the variant an item is ordered as, which products
+// with variants require
+func orderItemVariant(item orderModel.OrderItem, product *productModels.Product) (*productModels.ProductVariant, error) {
+	if item.VariantID == 0 {
+		return nil, fmt.Errorf("variant_id is required for product %d", item.ProductID)
+	}
+	for i := range product.Variants {
+		variant := &product.Variants[i]
+		if variant.ID != item.VariantID {
+			continue
+		}
+		if variant.Status != productModels.ProductStatusActive {
+			return nil, fmt.Errorf("variant %d of product %d is not available", item.VariantID, item.ProductID)
+		}
+		return variant, nil
+	}
+	return nil, fmt.Errorf("variant %d of product %d not found", item.VariantID, item.ProductID)
+}
+
+// insertOrderTx persists a pending order with its items and initial status history,
+// setting req.OrderID, and returns the stock hold the order needs
+func (u *orderUsecase) insertOrderTx(ctx context.Context, tx *sql.Tx, req *orderModel.CreateOrderRequest) (*productModels.HoldStockRequest, error) {
 	orderID, err := u.orderRepo.CreateOrder(tx, req)
 	if err != nil {
 		log.Printf("Failed to create orders: %v", err)
-		return fmt.Errorf("failed to create orders: %w", err)
+		return nil, fmt.Errorf("failed to create orders: %w", err)
+	}
+	req.OrderID = orderID
+
+	err = u.orderRepo.InsertStatusHistoryTx(ctx, tx, &orderModel.OrderStatusHistory{
+		OrderID:   orderID,
+		ToStatus:  orderModel.OrderStatusPending,
+		ChangedBy: StatusChangedByUser(req.UserID),
+		Reason:    "order created",
+	})
+	if err != nil {
+		log.Printf("Failed to record order status history: %v", err)
+		return nil, fmt.Errorf("failed to record order status history: %w", err)
 	}
 
-	holdStockRequest := productModels.HoldStockRequest{
+	holdStockRequest := &productModels.HoldStockRequest{
 		OrderID:  orderID,
 		Products: []productModels.Product{},
 	}
 
 	for i, item := range req.Items {
 		req.Items[i].OrderID = orderID
+		if item.VariantID != 0 {
+			holdStockRequest.Variants = append(holdStockRequest.Variants, productModels.ProductVariant{
+				ID:          item.VariantID,
+				ProductID:   item.ProductID,
+				OnHoldStock: item.Quantity,
+			})
+			continue
+		}
 		holdStockRequest.Products = append(holdStockRequest.Products, productModels.Product{
 			ID:          item.ProductID,
 			OnHoldStock: item.Quantity,
@@ -133,32 +401,335 @@ func (u *orderUsecase) CreateOrder(ctx context.Context, req *orderModel.CreateOr
 	err = u.orderRepo.CreateOrderItem(tx, req.Items)
 	if err != nil {
 		log.Printf("Failed to create order items: %v", err)
-		return fmt.Errorf("failed to create order items: %w", err)
+		return nil, fmt.Errorf("failed to create order items: %w", err)
+	}
+
+	if len(req.Discounts) > 0 {
+		for i := range req.Discounts {
+			req.Discounts[i].OrderID = orderID
+		}
+		err = u.orderRepo.InsertOrderDiscountsTx(ctx, tx, req.Discounts)
+		if err != nil {
+			log.Printf("Failed to create order discounts: %v", err)
+			return nil, fmt.Errorf("failed to create order discounts: %w", err)
+		}
 	}
 
-	err = u.productClient.HoldStockInBulk(ctx, &holdStockRequest)
+	return holdStockRequest, nil
+}
+
+// GetOrder retrieves a single order with its items, scoped to the owning user
+func (u *orderUsecase) GetOrder(ctx context.Context, userID int, orderID int64) (*orderModel.Order, error) {
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+
+	order, err := u.orderRepo.GetByID(ctx, int(orderID))
 	if err != nil {
-		log.Printf("Failed to hold stock in Product Service: %v", err)
-		return fmt.Errorf("failed to hold stock in Product Service: %w", err)
+		return nil, err
+	}
+
+	// Do not reveal the existence of orders owned by other users
+	if order.UserID != userID {
+		return nil, fmt.Errorf("order not found")
+	}
+
+	items, err := u.orderRepo.GetOrderItemsByOrderIDs(ctx, []int64{order.ID})
+	if err != nil {
+		log.Printf("Failed to get items for order %d: %v", order.ID, err)
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	order.Items = items
+
+	discounts, err := u.orderRepo.GetOrderDiscountsByOrderIDs(ctx, []int64{order.ID})
+	if err != nil {
+		log.Printf("Failed to get discounts for order %d: %v", order.ID, err)
+		return nil, fmt.Errorf("failed to get order discounts: %w", err)
+	}
+	order.Discounts = discounts
+
+	shipments, err := u.orderRepo.GetShipmentsByOrderIDs(ctx, []int64{order.ID})
+	if err != nil {
+		log.Printf("Failed to get shipments for order %d: %v", order.ID, err)
+		return nil, fmt.Errorf("failed to get order shipments: %w", err)
+	}
+	order.Shipments = shipments
+
+	returns, err := u.orderRepo.GetReturnsByOrderIDs(ctx, []int64{order.ID})
+	if err != nil {
+		log.Printf("Failed to get returns for order %d: %v", order.ID, err)
+		return nil, fmt.Errorf("failed to get order returns: %w", err)
+	}
+	order.Returns = returns
+
+	refunds, err := u.orderRepo.GetRefundsByOrderIDs(ctx, []int64{order.ID})
+	if err != nil {
+		log.Printf("Failed to get refunds for order %d: %v", order.ID, err)
+		return nil, fmt.Errorf("failed to get order refunds: %w", err)
+	}
+	order.Refunds = refunds
+
+	return order, nil
+}
+
+// ListOrders retrieves the user's orders with filtering and pagination
+func (u *orderUsecase) ListOrders(ctx context.Context, req *orderModel.OrderListRequest) (*orderModel.OrderListResponse, error) {
+	// Set default values
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	if req.StartDate != "" {
+		from, err := time.ParseInLocation("2006-01-02", req.StartDate, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start_date, use format YYYY-MM-DD")
+		}
+		req.From = from
+	}
+	if req.EndDate != "" {
+		to, err := time.ParseInLocation("2006-01-02", req.EndDate, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end_date, use format YYYY-MM-DD")
+		}
+		// End date is inclusive, so filter up to the start of the next day
+		req.To = to.AddDate(0, 0, 1)
+	}
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		return nil, fmt.Errorf("start_date must be before or equal to end_date")
+	}
+
+	response, err := u.orderRepo.List(ctx, req)
+	if err != nil {
+		log.Printf("Failed to list orders: %v", err)
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	if len(response.Orders) == 0 {
+		return response, nil
+	}
+
+	orderIDs := make([]int64, 0, len(response.Orders))
+	orderIndex := make(map[int64]int, len(response.Orders))
+	for i, order := range response.Orders {
+		orderIDs = append(orderIDs, order.ID)
+		orderIndex[order.ID] = i
+	}
+
+	items, err := u.orderRepo.GetOrderItemsByOrderIDs(ctx, orderIDs)
+	if err != nil {
+		log.Printf("Failed to get order items: %v", err)
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	for _, item := range items {
+		if i, exists := orderIndex[item.OrderID]; exists {
+			response.Orders[i].Items = append(response.Orders[i].Items, item)
+		}
+	}
+
+	discounts, err := u.orderRepo.GetOrderDiscountsByOrderIDs(ctx, orderIDs)
+	if err != nil {
+		log.Printf("Failed to get order discounts: %v", err)
+		return nil, fmt.Errorf("failed to get order discounts: %w", err)
+	}
+	for _, discount := range discounts {
+		if i, exists := orderIndex[discount.OrderID]; exists {
+			response.Orders[i].Discounts = append(response.Orders[i].Discounts, discount)
+		}
+	}
+
+	shipments, err := u.orderRepo.GetShipmentsByOrderIDs(ctx, orderIDs)
+	if err != nil {
+		log.Printf("Failed to get order shipments: %v", err)
+		return nil, fmt.Errorf("failed to get order shipments: %w", err)
+	}
+	for _, shipment := range shipments {
+		if i, exists := orderIndex[shipment.OrderID]; exists {
+			response.Orders[i].Shipments = append(response.Orders[i].Shipments, shipment)
+		}
+	}
+
+	log.Printf("Listed %d orders for user %d, shop %d (page %d, limit %d)", len(response.Orders), req.UserID, req.ShopID, req.Page, req.Limit)
+	return response, nil
+}
+
+// GetOrderHistory retrieves the status history of an order owned by the user
+func (u *orderUsecase) GetOrderHistory(ctx context.Context, userID int, orderID int64) ([]orderModel.OrderStatusHistory, error) {
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+
+	order, err := u.orderRepo.GetByID(ctx, int(orderID))
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, fmt.Errorf("order not found")
+	}
+
+	history, err := u.orderRepo.GetStatusHistoryByOrderID(ctx, order.ID)
+	if err != nil {
+		log.Printf("Failed to get status history for order %d: %v", order.ID, err)
+		return nil, fmt.Errorf("failed to get order status history: %w", err)
+	}
+
+	return history, nil
+}
+
+// PayOrder confirms payment of a pending order and commits its held stock
+// through the stock action queue
+func (u *orderUsecase) PayOrder(ctx context.Context, userID int, orderID int64, req *orderModel.PayOrderRequest) (*orderModel.Order, error) {
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// Lock the order so the expiry consumer cannot release its stock concurrently
+	order, err := u.orderRepo.GetByIDForUpdateTx(ctx, tx, int(orderID))
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		err = fmt.Errorf("order not found")
+		return nil, err
+	}
+	if order.Status == orderModel.OrderStatusPending && !time.Now().Before(order.ExpiresAt) {
+		err = fmt.Errorf("order %d has expired", order.ID)
+		return nil, err
+	}
+
+	reason := "payment confirmed"
+	if req.PaymentReference != "" {
+		reason = fmt.Sprintf("payment confirmed (ref: %s)", req.PaymentReference)
+	}
+	err = u.transitionStatusTx(ctx, tx, order, orderModel.OrderStatusConfirmed, StatusChangedByUser(userID), reason)
+	if err != nil {
+		log.Printf("Failed to confirm order %d: %v", order.ID, err)
+		return nil, err
+	}
+
+	// The held stock is committed once the confirmation is durable. Queuing the
+	// commit with it means a crash in between cannot leave a paid order whose
+	// stock is still on hold and later released by expiry.
+	commitID, err := u.queueStockActionTx(ctx, tx, order.ID, orderModel.CompensationActionCommitHeldStock)
+	if err != nil {
+		log.Printf("Failed to queue held stock commit for order %d: %v", order.ID, err)
+		return nil, err
 	}
 
 	err = tx.Commit()
 	if err != nil {
 		log.Printf("Failed to commit transaction: %v", err)
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// Publish order creation event to NSQ for further processing
-	nsqAddress := config.GetEnv("NSQD_HOST", "http://localhost:4151")
-	topic := config.GetEnv("NSQ_TOPIC_ORDER", "")
+	u.applyStockAction(commitID)
 
-	req.OrderID = orderID
-	nsqErr := nsq.PublishHTTP(nsqAddress, topic, req, expDurationMs)
-	if nsqErr != nil {
-		log.Printf("[NSQERROR] Failed to publish order %d to NSQ: %v", orderID, nsqErr)
+	items, itemsErr := u.orderRepo.GetOrderItemsByOrderIDs(ctx, []int64{order.ID})
+	if itemsErr != nil {
+		log.Printf("Failed to get items for order %d: %v", order.ID, itemsErr)
 	}
+	order.Items = items
 
-	return nil
+	log.Printf("Order %d paid and confirmed", order.ID)
+	return order, nil
+}
+
+// CancelOrder cancels a pending order of the user and releases its held stock
+func (u *orderUsecase) CancelOrder(ctx context.Context, userID int, orderID int64, req *orderModel.CancelOrderRequest) (*orderModel.Order, error) {
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	order, err := u.orderRepo.GetByIDForUpdateTx(ctx, tx, int(orderID))
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		err = fmt.Errorf("order not found")
+		return nil, err
+	}
+	if order.Status != orderModel.OrderStatusPending {
+		err = fmt.Errorf("only pending orders can be cancelled, order %d is %s", order.ID, order.Status)
+		return nil, err
+	}
+
+	reason := "cancelled by customer"
+	if req.Reason != "" {
+		reason = req.Reason
+	}
+	releaseID, err := u.releasePendingOrderTx(ctx, tx, order, orderModel.OrderStatusCancelled, StatusChangedByUser(userID), reason)
+	if err != nil {
+		log.Printf("Failed to cancel order %d: %v", order.ID, err)
+		return nil, fmt.Errorf("failed to cancel order: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	u.applyStockAction(releaseID)
+
+	items, itemsErr := u.orderRepo.GetOrderItemsByOrderIDs(ctx, []int64{order.ID})
+	if itemsErr != nil {
+		log.Printf("Failed to get items for order %d: %v", order.ID, itemsErr)
+	}
+	order.Items = items
+
+	log.Printf("Order %d cancelled by user %d", order.ID, userID)
+	return order, nil
+}
+
+// releasePendingOrderTx closes a row-locked pending order with the given status
+// and queues the release of the stock held for it. It returns the queued
+// release, which the caller applies with applyStockAction once the transaction
+// is committed, so stock is never put back on sale for an order still pending.
+// Both customer cancellation and expiry go through here so they behave the same way.
+func (u *orderUsecase) releasePendingOrderTx(ctx context.Context, tx *sql.Tx, order *orderModel.Order, status, changedBy, reason string) (int64, error) {
+	err := u.transitionStatusTx(ctx, tx, order, status, changedBy, reason)
+	if err != nil {
+		return 0, err
+	}
+
+	// Coupons used by an order that will not be fulfilled can be used again
+	err = u.orderRepo.ReleaseCouponRedemptionsTx(ctx, tx, order.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return u.queueStockActionTx(ctx, tx, order.ID, orderModel.CompensationActionReleaseHeldStock)
 }
 
 // ProcessOrderMessage processes an order message from NSQ
@@ -194,41 +765,35 @@ func (u *orderUsecase) ProcessOrderMessage(msg *nsqio.Message) error {
 		return err
 	}
 
-	if checkedOrder.Status != "pending" {
+	if checkedOrder.Status != orderModel.OrderStatusPending {
 		log.Printf("[NSQ] Order %d already processed with status %s", req.OrderID, checkedOrder.Status)
-		tx.Commit()
-		return nil
+		err = tx.Commit()
+		if err != nil {
+			log.Printf("[NSQ] Failed to commit transaction for order %d: %v", req.OrderID, err)
+		}
+		return err
 	}
 
 	if time.Now().Before(checkedOrder.ExpiresAt) {
 		log.Printf("[NSQ] Order %d not expired yet, skipping", req.OrderID)
-		tx.Commit()
 
 		// requeue the message for later processing
-		nsqAddress := config.GetEnv("NSQD_HOST", "http://localhost:4151")
-		topic := config.GetEnv("NSQ_TOPIC_ORDER", "")
-		duration := checkedOrder.ExpiresAt.Sub(time.Now()).Milliseconds()
-
-		nsqErr := nsq.PublishHTTP(nsqAddress, topic, req, int(duration))
-		if nsqErr != nil {
-			log.Printf("[NSQERROR] Failed to publish order %d to NSQ: %v", req.OrderID, nsqErr)
+		err = u.enqueueOrderEventTx(ctx, tx, &req, checkedOrder.ExpiresAt)
+		if err != nil {
+			log.Printf("[NSQ] Failed to requeue order %d: %v", req.OrderID, err)
+			return err
 		}
 
-		return nil
-	}
-
-	checkedOrder.Status = "expired"
-	err = u.orderRepo.UpdateOrderStatusTx(ctx, tx, checkedOrder.ID, checkedOrder.Status)
-	if err != nil {
-		log.Printf("[NSQ] Failed to update order %d status to EXPIRED: %v", req.OrderID, err)
+		err = tx.Commit()
+		if err != nil {
+			log.Printf("[NSQ] Failed to commit transaction for order %d: %v", req.OrderID, err)
+		}
 		return err
 	}
 
-	err = u.productClient.ReleaseHeldStockInBulk(ctx, &productModels.ReleaseHeldStockRequest{
-		OrderID: req.OrderID,
-	})
+	releaseID, err := u.expireOrderTx(ctx, tx, checkedOrder)
 	if err != nil {
-		log.Printf("[NSQ] Failed to release held stock for order %d: %v", req.OrderID, err)
+		log.Printf("[NSQ] Failed to expire order %d: %v", req.OrderID, err)
 		return err
 	}
 
@@ -238,6 +803,8 @@ func (u *orderUsecase) ProcessOrderMessage(msg *nsqio.Message) error {
 		return err
 	}
 
+	u.applyStockAction(releaseID)
+
 	log.Printf("[NSQ] Order %d marked as EXPIRED due to non-payment", req.OrderID)
 	return nil
 }
@@ -0,0 +1,271 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	productModel "github.com/Christyan39/test-eDot/internal/models/product"
+)
+
+// categoryTree indexes the category taxonomy by ID and by parent
+type categoryTree struct {
+	byID     map[int64]*productModel.Category
+	children map[int64][]int64 // parent ID (0 for roots) to child IDs, in name order
+}
+
+// loadCategoryTree retrieves the whole category taxonomy
+func (u *productUsecase) loadCategoryTree() (*categoryTree, error) {
+	categories, err := u.productRepo.ListCategories()
+	if err != nil {
+		log.Printf("Failed to list categories: %v", err)
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+
+	tree := &categoryTree{
+		byID:     make(map[int64]*productModel.Category, len(categories)),
+		children: make(map[int64][]int64),
+	}
+	for i := range categories {
+		tree.byID[categories[i].ID] = &categories[i]
+		tree.children[categories[i].ParentID] = append(tree.children[categories[i].ParentID], categories[i].ID)
+	}
+	return tree, nil
+}
+
+// subtree returns the ID of a category followed by those of all its descendants
+func (t *categoryTree) subtree(categoryID int64) []int64 {
+	ids := []int64{categoryID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, t.children[ids[i]]...)
+	}
+	return ids
+}
+
+// nested returns the categories under a parent (0 for the roots) with their
+// children filled in
+func (t *categoryTree) nested(parentID int64) []productModel.Category {
+	childIDs := t.children[parentID]
+	categories := make([]productModel.Category, 0, len(childIDs))
+	for _, id := range childIDs {
+		category := *t.byID[id]
+		category.Children = t.nested(id)
+		categories = append(categories, category)
+	}
+	return categories
+}
+
+// nameTaken reports whether a category under the parent other than the given
+// one already has the name. Root names are not unique in the database, as
+// their parent is NULL.
+func (t *categoryTree) nameTaken(parentID int64, name string, exceptID int64) bool {
+	for _, id := range t.children[parentID] {
+		if id != exceptID && strings.EqualFold(t.byID[id].Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCategoryID checks that a category assigned to a product exists
+func (u *productUsecase) validateCategoryID(categoryID int64) error {
+	if categoryID == 0 {
+		return nil
+	}
+	tree, err := u.loadCategoryTree()
+	if err != nil {
+		return err
+	}
+	if _, exists := tree.byID[categoryID]; !exists {
+		return fmt.Errorf("invalid category ID: category %d not found", categoryID)
+	}
+	return nil
+}
+
+// ListCategories retrieves the category taxonomy as a tree
+func (u *productUsecase) ListCategories(ctx context.Context) ([]productModel.Category, error) {
+	tree, err := u.loadCategoryTree()
+	if err != nil {
+		return nil, err
+	}
+	return tree.nested(0), nil
+}
+
+// CreateCategory adds a category to the taxonomy
+func (u *productUsecase) CreateCategory(ctx context.Context, req *productModel.CreateCategoryRequest) (*productModel.Category, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if len(req.Name) > 100 {
+		return nil, fmt.Errorf("invalid name: must be at most 100 characters")
+	}
+	if req.ParentID < 0 {
+		return nil, fmt.Errorf("invalid parent ID")
+	}
+
+	tree, err := u.loadCategoryTree()
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := tree.byID[req.ParentID]; req.ParentID != 0 && !exists {
+		return nil, fmt.Errorf("invalid parent ID: category %d not found", req.ParentID)
+	}
+	if tree.nameTaken(req.ParentID, req.Name, 0) {
+		return nil, fmt.Errorf("invalid name: category %s already exists", req.Name)
+	}
+
+	categoryID, err := u.productRepo.CreateCategory(req)
+	if err != nil {
+		log.Printf("Failed to create category %s: %v", req.Name, err)
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	log.Printf("Category ID %d (%s) created under parent %d", categoryID, req.Name, req.ParentID)
+	return u.getCategory(categoryID)
+}
+
+// UpdateCategory renames a category or moves it, with its subcategories, under
+// another parent
+func (u *productUsecase) UpdateCategory(ctx context.Context, req *productModel.UpdateCategoryRequest) (*productModel.Category, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" && req.ParentID == nil {
+		return nil, fmt.Errorf("no fields provided for update")
+	}
+	if len(req.Name) > 100 {
+		return nil, fmt.Errorf("invalid name: must be at most 100 characters")
+	}
+
+	tree, err := u.loadCategoryTree()
+	if err != nil {
+		return nil, err
+	}
+	current, exists := tree.byID[req.ID]
+	if !exists {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	category := *current
+	if req.Name != "" {
+		category.Name = req.Name
+	}
+	if req.ParentID != nil {
+		parentID := *req.ParentID
+		if parentID < 0 {
+			return nil, fmt.Errorf("invalid parent ID")
+		}
+		if _, exists := tree.byID[parentID]; parentID != 0 && !exists {
+			return nil, fmt.Errorf("invalid parent ID: category %d not found", parentID)
+		}
+		// A category cannot be moved under itself or one of its descendants
+		for _, id := range tree.subtree(category.ID) {
+			if id == parentID {
+				return nil, fmt.Errorf("invalid parent ID: category %d is within category %d", parentID, category.ID)
+			}
+		}
+		category.ParentID = parentID
+	}
+	if tree.nameTaken(category.ParentID, category.Name, category.ID) {
+		return nil, fmt.Errorf("invalid name: category %s already exists", category.Name)
+	}
+
+	err = u.productRepo.UpdateCategory(&category)
+	if err != nil {
+		log.Printf("Failed to update category ID %d: %v", category.ID, err)
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+
+	log.Printf("Category ID %d updated (%s under parent %d)", category.ID, category.Name, category.ParentID)
+	return u.getCategory(category.ID)
+}
+
+// getCategory retrieves a category with its subcategories
+func (u *productUsecase) getCategory(categoryID int64) (*productModel.Category, error) {
+	tree, err := u.loadCategoryTree()
+	if err != nil {
+		return nil, err
+	}
+	category, exists := tree.byID[categoryID]
+	if !exists {
+		return nil, fmt.Errorf("category not found")
+	}
+	result := *category
+	result.Children = tree.nested(categoryID)
+	return &result, nil
+}
+
+// productFacets counts the products matching a listing per category, price
+// bucket and shop. Products count towards their category and every ancestor of it.
+func (u *productUsecase) productFacets(ctx context.Context, req *productModel.ProductListRequest, tree *categoryTree) (*productModel.ProductFacets, error) {
+	facets, err := u.productRepo.CountFacets(req)
+	if err != nil {
+		log.Printf("Failed to count product facets: %v", err)
+		return nil, fmt.Errorf("failed to count product facets: %w", err)
+	}
+
+	counts := make(map[int64]int)
+	for _, facet := range facets.Categories {
+		// Stop at a parent already visited, so a corrupt cycle cannot loop forever
+		visited := make(map[int64]bool)
+		for id := facet.CategoryID; id != 0 && !visited[id]; {
+			visited[id] = true
+			counts[id] += facet.Count
+			category, exists := tree.byID[id]
+			if !exists {
+				break
+			}
+			id = category.ParentID
+		}
+	}
+
+	facets.Categories = []productModel.CategoryFacet{}
+	for _, id := range tree.ordered() {
+		if counts[id] == 0 {
+			continue
+		}
+		category := tree.byID[id]
+		facets.Categories = append(facets.Categories, productModel.CategoryFacet{
+			CategoryID: id,
+			ParentID:   category.ParentID,
+			Name:       category.Name,
+			Count:      counts[id],
+		})
+	}
+
+	if len(facets.Shops) > 0 {
+		shopIDs := make([]int, 0, len(facets.Shops))
+		for _, facet := range facets.Shops {
+			shopIDs = append(shopIDs, facet.ShopID)
+		}
+		// Facets are still useful without names, so they do not fail with the user service
+		shops, err := u.userClient.ListShops(ctx, shopIDs)
+		if err != nil {
+			log.Printf("Failed to get shops %v, returning shop facets without names: %v", shopIDs, err)
+		}
+		shopNames := make(map[int]string, len(shops))
+		for _, shop := range shops {
+			shopNames[shop.ID] = shop.Name
+		}
+		for i := range facets.Shops {
+			facets.Shops[i].ShopName = shopNames[facets.Shops[i].ShopID]
+		}
+	}
+
+	return facets, nil
+}
+
+// ordered returns the IDs of every category, depth first from the roots so
+// parents come before their children
+func (t *categoryTree) ordered() []int64 {
+	ids := make([]int64, 0, len(t.byID))
+	var walk func(parentID int64)
+	walk = func(parentID int64) {
+		for _, id := range t.children[parentID] {
+			ids = append(ids, id)
+			walk(id)
+		}
+	}
+	walk(0)
+	return ids
+}
@@ -0,0 +1,238 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	productModel "github.com/Christyan39/test-eDot/internal/models/product"
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// authorizeShopMember checks that the user belongs to the shop owning a product
+func (u *productUsecase) authorizeShopMember(ctx context.Context, userID, shopID int) error {
+	_, err := u.userClient.GetShopMember(ctx, shopID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not a member") {
+			return fmt.Errorf("forbidden: %w", err)
+		}
+		return fmt.Errorf("failed to verify shop membership: %w", err)
+	}
+	return nil
+}
+
+// sellerShopID resolves the shop a seller creates products in. Sellers of a
+// single shop need not name it; sellers of several shops must pick one.
+func (u *productUsecase) sellerShopID(ctx context.Context, userID, requestedShopID int) (int, error) {
+	if requestedShopID < 0 {
+		return 0, fmt.Errorf("invalid shop ID")
+	}
+
+	members, err := u.userClient.ListUserShops(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to verify shop membership: %w", err)
+	}
+
+	if requestedShopID != 0 {
+		for _, member := range members {
+			if member.ShopID == requestedShopID {
+				return requestedShopID, nil
+			}
+		}
+		return 0, fmt.Errorf("forbidden: user %d is not a member of shop %d", userID, requestedShopID)
+	}
+
+	switch len(members) {
+	case 0:
+		return 0, fmt.Errorf("forbidden: user %d is not a member of any shop", userID)
+	case 1:
+		return members[0].ShopID, nil
+	}
+	return 0, fmt.Errorf("shop_id is required: user %d is a member of %d shops", userID, len(members))
+}
+
+// ownedProduct retrieves a product for a member of the shop owning it
+func (u *productUsecase) ownedProduct(ctx context.Context, userID int, id int64) (*productModel.Product, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+
+	product, err := u.productRepo.GetByID(int(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.authorizeShopMember(ctx, userID, product.ShopID); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// GetProduct retrieves a product for a member of the shop owning it
+func (u *productUsecase) GetProduct(ctx context.Context, userID int, id int64) (*productModel.Product, error) {
+	product, err := u.ownedProduct(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.hydrateShop(ctx, product); err != nil {
+		return nil, err
+	}
+	if err := u.attachProductVariants(product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// UpdateProduct applies a partial update to a product on behalf of a member of
+// the shop owning it. Stock on hold is managed by orders and products cannot
+// move to another shop.
+func (u *productUsecase) UpdateProduct(ctx context.Context, userID int, req *productModel.UpdateProductRequest) (*productModel.Product, error) {
+	product, err := u.ownedProduct(ctx, userID, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateProductUpdate(product, req); err != nil {
+		return nil, err
+	}
+	if err := u.validateCategoryID(req.CategoryID); err != nil {
+		return nil, err
+	}
+
+	product, err = u.updateProduct(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Product ID %d updated by user %d of shop %d", product.ID, userID, product.ShopID)
+
+	if err := u.hydrateShop(ctx, product); err != nil {
+		return nil, err
+	}
+	if err := u.attachProductVariants(product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// updateProduct writes a validated partial update and returns the updated product
+func (u *productUsecase) updateProduct(ctx context.Context, req *productModel.UpdateProductRequest) (*productModel.Product, error) {
+	tx, err := u.productRepo.TxBegin(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// Lock the product so the stock written back is not stale
+	product, err := u.productRepo.GetByIDForUpdateTx(tx, int(req.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	// UpdateTx always writes the on-hold stock, which is managed by orders
+	req.OnHoldStock = product.OnHoldStock
+
+	err = u.productRepo.UpdateTx(tx, int(req.ID), req)
+	if err != nil {
+		log.Printf("Failed to update product ID %d: %v", req.ID, err)
+		return nil, fmt.Errorf("failed to update product: %w", err)
+	}
+
+	product, err = u.productRepo.GetByIDForUpdateTx(tx, int(req.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return product, nil
+}
+
+// DeleteProduct soft-deletes a product by discontinuing it, on behalf of a
+// member of the shop owning it. Deleting a discontinued product is a no-op.
+func (u *productUsecase) DeleteProduct(ctx context.Context, userID int, id int64) error {
+	product, err := u.ownedProduct(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if product.Status == productModel.ProductStatusDiscontinued {
+		return nil
+	}
+
+	_, err = u.updateProduct(ctx, &productModel.UpdateProductRequest{
+		ID:     id,
+		Status: productModel.ProductStatusDiscontinued,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Product ID %d discontinued by user %d of shop %d", id, userID, product.ShopID)
+	return nil
+}
+
+// validateProductUpdate checks the fields of a partial update, mirroring the
+// validation tags of UpdateProductRequest
+func validateProductUpdate(product *productModel.Product, req *productModel.UpdateProductRequest) error {
+	if req.ShopID != 0 && req.ShopID != product.ShopID {
+		return fmt.Errorf("invalid shop ID: products cannot be moved to another shop")
+	}
+	if req.OnHoldStock != 0 {
+		return fmt.Errorf("invalid update: on_hold_stock is managed by orders")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name != "" && (len(req.Name) < 2 || len(req.Name) > 100) {
+		return fmt.Errorf("invalid name: must be between 2 and 100 characters")
+	}
+	if req.Description != "" && (len(req.Description) < 10 || len(req.Description) > 1000) {
+		return fmt.Errorf("invalid description: must be between 10 and 1000 characters")
+	}
+
+	if len(req.RawPrice) > 0 && string(req.RawPrice) != "null" {
+		// Prices without an explicit currency keep the product's currency
+		price, err := money.ParseJSON(req.RawPrice, product.Price.Currency)
+		if err != nil {
+			return fmt.Errorf("invalid price: %w", err)
+		}
+		req.Price = price
+		if !money.IsValidCurrency(req.Price.Currency) {
+			return fmt.Errorf("invalid currency %q", req.Price.Currency)
+		}
+		if !req.Price.IsPositive() {
+			return fmt.Errorf("invalid price: must be greater than 0")
+		}
+	}
+
+	req.TaxCategory = strings.ToLower(strings.TrimSpace(req.TaxCategory))
+	if len(req.TaxCategory) > 50 {
+		return fmt.Errorf("invalid tax category: must be at most 50 characters")
+	}
+	if req.WeightGrams < 0 {
+		return fmt.Errorf("invalid weight: weight_grams must be 0 or more")
+	}
+	if req.Stock != nil && *req.Stock < 0 {
+		return fmt.Errorf("invalid stock: must be 0 or more")
+	}
+
+	switch req.Status {
+	case "", productModel.ProductStatusActive, productModel.ProductStatusInactive, productModel.ProductStatusDiscontinued:
+	default:
+		return fmt.Errorf("invalid status %q, must be one of active, inactive, discontinued", req.Status)
+	}
+
+	return nil
+}
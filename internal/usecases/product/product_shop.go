@@ -0,0 +1,85 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	productModel "github.com/Christyan39/test-eDot/internal/models/product"
+	userModel "github.com/Christyan39/test-eDot/internal/models/user"
+)
+
+// shopMetadata builds the shop information shown with a product
+func shopMetadata(shop *userModel.Shop) productModel.ShopMetadata {
+	return productModel.ShopMetadata{
+		ShopName: shop.Name,
+		ShopID:   int64(shop.ID),
+		Status:   shop.Status,
+		Region:   shop.Region,
+	}
+}
+
+// getShop retrieves a shop from the user service
+func (u *productUsecase) getShop(ctx context.Context, shopID int) (*userModel.Shop, error) {
+	shops, err := u.userClient.ListShops(ctx, []int{shopID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shop %d: %w", shopID, err)
+	}
+	for i := range shops {
+		if shops[i].ID == shopID {
+			return &shops[i], nil
+		}
+	}
+	return nil, fmt.Errorf("invalid shop ID: shop %d not found", shopID)
+}
+
+// hydrateShops replaces the shop snapshot stored with each product by the
+// current details of its shop. Products keep their snapshot when the user
+// service does not know their shop or cannot be reached.
+func (u *productUsecase) hydrateShops(ctx context.Context, products []productModel.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	shopIDs := []int{}
+	seen := make(map[int]bool)
+	for _, product := range products {
+		if !seen[product.ShopID] {
+			seen[product.ShopID] = true
+			shopIDs = append(shopIDs, product.ShopID)
+		}
+	}
+
+	shops, err := u.userClient.ListShops(ctx, shopIDs)
+	if err != nil {
+		log.Printf("Failed to get shops %v, keeping the stored shop metadata: %v", shopIDs, err)
+		return nil
+	}
+
+	shopMap := make(map[int]*userModel.Shop, len(shops))
+	for i := range shops {
+		shopMap[shops[i].ID] = &shops[i]
+	}
+
+	for i := range products {
+		shop, exists := shopMap[products[i].ShopID]
+		if !exists {
+			log.Printf("Shop %d of product ID %d not found, keeping its stored shop metadata", products[i].ShopID, products[i].ID)
+			continue
+		}
+		products[i].ShopMetadata = shopMetadata(shop)
+	}
+
+	return nil
+}
+
+// hydrateShop replaces the shop snapshot stored with a product by the current
+// details of its shop
+func (u *productUsecase) hydrateShop(ctx context.Context, product *productModel.Product) error {
+	products := []productModel.Product{*product}
+	if err := u.hydrateShops(ctx, products); err != nil {
+		return err
+	}
+	product.ShopMetadata = products[0].ShopMetadata
+	return nil
+}
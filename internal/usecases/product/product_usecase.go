@@ -2,53 +2,104 @@ package product
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
+	"github.com/Christyan39/test-eDot/internal/clients"
 	productModel "github.com/Christyan39/test-eDot/internal/models/product"
+	userModel "github.com/Christyan39/test-eDot/internal/models/user"
 	productRepo "github.com/Christyan39/test-eDot/internal/repositories/product"
+	"github.com/Christyan39/test-eDot/pkg/config"
+	"github.com/Christyan39/test-eDot/pkg/money"
 )
 
 // ProductUsecase defines the product business logic interface
 type ProductUsecase interface {
-	CreateProduct(ctx context.Context, req *productModel.CreateProductRequest) error
+	CreateProduct(ctx context.Context, userID int, req *productModel.CreateProductRequest) error
 	ListProducts(ctx context.Context, req *productModel.ProductListRequest) (*productModel.ProductListResponse, error)
 	UpdateOnHoldStock(ctx context.Context, id, newOnHoldStock int) error
 	HoldStockInBulk(ctx context.Context, req *productModel.HoldStockRequest) error
+	HoldStockForOrders(ctx context.Context, req *productModel.HoldStockBatchRequest) error
 	ReleaseHeldStock(ctx context.Context, req *productModel.ReleaseHeldStockRequest) error
+	CommitHeldStock(ctx context.Context, req *productModel.CommitHeldStockRequest) error
+	Restock(ctx context.Context, req *productModel.RestockRequest) error
+	GetProduct(ctx context.Context, userID int, id int64) (*productModel.Product, error)
+	UpdateProduct(ctx context.Context, userID int, req *productModel.UpdateProductRequest) (*productModel.Product, error)
+	DeleteProduct(ctx context.Context, userID int, id int64) error
+	CreateVariant(ctx context.Context, userID int, req *productModel.CreateVariantRequest) (*productModel.ProductVariant, error)
+	UpdateVariant(ctx context.Context, userID int, req *productModel.UpdateVariantRequest) (*productModel.ProductVariant, error)
+	ListCategories(ctx context.Context) ([]productModel.Category, error)
+	CreateCategory(ctx context.Context, req *productModel.CreateCategoryRequest) (*productModel.Category, error)
+	UpdateCategory(ctx context.Context, req *productModel.UpdateCategoryRequest) (*productModel.Category, error)
 }
 
 // productUsecase implements ProductUsecase
 type productUsecase struct {
 	productRepo productRepo.ProductRepository
+	userClient  clients.UserServiceClientInterface
 }
 
 // NewProductUsecase creates a new product usecase
 func NewProductUsecase(productRepo productRepo.ProductRepository) ProductUsecase {
+	userServiceURL := config.GetEnv("USER_SERVICE_URL", "http://localhost:8080")
+	userAPIKey := config.GetEnv("USER_SERVICE_API_KEY", "")
+
 	return &productUsecase{
 		productRepo: productRepo,
+		userClient:  clients.NewUserServiceClient(userServiceURL, userAPIKey),
 	}
 }
 
-// CreateProduct creates a new product
-func (u *productUsecase) CreateProduct(ctx context.Context, req *productModel.CreateProductRequest) error {
-	// Validate shop metadata
-	if req.ShopMetadata.ShopID <= 0 {
-		return fmt.Errorf("invalid shop ID in metadata")
+// CreateProduct creates a new product in a shop the user belongs to
+func (u *productUsecase) CreateProduct(ctx context.Context, userID int, req *productModel.CreateProductRequest) error {
+	shopID, err := u.sellerShopID(ctx, userID, req.ShopID)
+	if err != nil {
+		return err
+	}
+
+	shop, err := u.getShop(ctx, shopID)
+	if err != nil {
+		return err
+	}
+	if shop.Status != userModel.ShopStatusActive {
+		return fmt.Errorf("invalid shop: shop %d is %s", shopID, shop.Status)
+	}
+	req.ShopID = shopID
+	req.ShopMetadata = shopMetadata(shop)
+
+	// Prices without an explicit currency are in the default currency
+	req.Price = money.New(req.Price.Amount, req.Price.Currency)
+	if !money.IsValidCurrency(req.Price.Currency) {
+		return fmt.Errorf("invalid currency %q", req.Price.Currency)
 	}
-	if req.ShopMetadata.ShopName == "" {
-		return fmt.Errorf("shop name is required in metadata")
+	if !req.Price.IsPositive() {
+		return fmt.Errorf("invalid price: must be greater than 0")
+	}
+
+	req.TaxCategory = strings.ToLower(strings.TrimSpace(req.TaxCategory))
+	if req.TaxCategory == "" {
+		req.TaxCategory = productModel.DefaultTaxCategory
+	}
+
+	if req.WeightGrams < 0 {
+		return fmt.Errorf("invalid weight: weight_grams must be 0 or more")
+	}
+
+	if err := u.validateCategoryID(req.CategoryID); err != nil {
+		return err
 	}
 
 	// Create the product
-	err := u.productRepo.Create(req)
+	err = u.productRepo.Create(req)
 	if err != nil {
 		log.Printf("Failed to create product: %v", err)
 		return fmt.Errorf("failed to create product: %w", err)
 	}
 
-	log.Printf("Product created successfully for shop: %s", req.ShopMetadata.ShopName)
+	log.Printf("Product created successfully by user %d for shop: %s", userID, req.ShopMetadata.ShopName)
 	return nil
 }
 
@@ -65,17 +116,61 @@ func (u *productUsecase) ListProducts(ctx context.Context, req *productModel.Pro
 		req.Limit = 100
 	}
 
+	if req.Currency != "" {
+		req.Currency = strings.ToUpper(req.Currency)
+		if !money.IsValidCurrency(req.Currency) {
+			return nil, fmt.Errorf("invalid currency %q", req.Currency)
+		}
+	}
+
 	// Validate price range
-	if req.MinPrice > 0 && req.MaxPrice > 0 && req.MinPrice > req.MaxPrice {
+	if req.MinPrice.IsPositive() && req.MaxPrice.IsPositive() && req.MinPrice.Amount > req.MaxPrice.Amount {
 		return nil, fmt.Errorf("minimum price cannot be greater than maximum price")
 	}
 
+	if req.CategoryID < 0 {
+		return nil, fmt.Errorf("invalid category ID")
+	}
+
+	// A category filter matches products in any of its subcategories
+	var tree *categoryTree
+	if req.CategoryID > 0 || req.Facets {
+		var err error
+		tree, err = u.loadCategoryTree()
+		if err != nil {
+			return nil, err
+		}
+	}
+	req.CategoryIDs = nil
+	if req.CategoryID > 0 {
+		if _, exists := tree.byID[req.CategoryID]; !exists {
+			return nil, fmt.Errorf("invalid category ID: category %d not found", req.CategoryID)
+		}
+		req.CategoryIDs = tree.subtree(req.CategoryID)
+	}
+
 	response, err := u.productRepo.List(req)
 	if err != nil {
 		log.Printf("Failed to list products: %v", err)
 		return nil, fmt.Errorf("failed to list products: %w", err)
 	}
 
+	if req.Facets {
+		response.Facets, err = u.productFacets(ctx, req, tree)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.hydrateShops(ctx, response.Products); err != nil {
+		log.Printf("Failed to hydrate shops of listed products: %v", err)
+		return nil, err
+	}
+	if err := u.attachVariants(response.Products); err != nil {
+		log.Printf("Failed to attach variants of listed products: %v", err)
+		return nil, err
+	}
+
 	log.Printf("Listed %d products (page %d, limit %d)", len(response.Products), req.Page, req.Limit)
 	return response, nil
 }
@@ -116,9 +211,10 @@ func (u *productUsecase) UpdateOnHoldStock(ctx context.Context, id, newOnHoldSto
 	}
 
 	// Update on-hold stock within transaction
+	stock := product.Stock - newOnHoldStock
 	err = u.productRepo.UpdateTx(tx, id, &productModel.UpdateProductRequest{
 		OnHoldStock: product.OnHoldStock + newOnHoldStock,
-		Stock:       product.Stock - newOnHoldStock,
+		Stock:       &stock,
 	})
 	if err != nil {
 		log.Printf("Failed to update on-hold stock for product ID %d: %v", id, err)
@@ -137,6 +233,17 @@ func (u *productUsecase) UpdateOnHoldStock(ctx context.Context, id, newOnHoldSto
 }
 
 func (u *productUsecase) HoldStockInBulk(ctx context.Context, req *productModel.HoldStockRequest) error {
+	return u.HoldStockForOrders(ctx, &productModel.HoldStockBatchRequest{
+		Holds: []productModel.HoldStockRequest{*req},
+	})
+}
+
+// HoldStockForOrders holds stock for several orders in a single transaction,
+// so either every order gets its stock or none does
+func (u *productUsecase) HoldStockForOrders(ctx context.Context, req *productModel.HoldStockBatchRequest) error {
+	if len(req.Holds) == 0 {
+		return fmt.Errorf("invalid request: at least one hold is required")
+	}
 
 	tx, err := u.productRepo.TxBegin(ctx)
 	if err != nil {
@@ -151,29 +258,100 @@ func (u *productUsecase) HoldStockInBulk(ctx context.Context, req *productModel.
 		}
 	}()
 
+	for i := range req.Holds {
+		err = u.holdStockTx(tx, &req.Holds[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// holdStockTx moves stock to on-hold for one order within a transaction and audits it.
+// Products are locked before their variants, as in every other stock change.
+// Orders whose hold was already released are refused.
+func (u *productUsecase) holdStockTx(tx *sql.Tx, req *productModel.HoldStockRequest) error {
+	if len(req.Products) == 0 && len(req.Variants) == 0 {
+		return fmt.Errorf("invalid hold for order ID %d: no products or variants", req.OrderID)
+	}
+
+	cancelled, err := u.productRepo.IsHoldCancelledTx(tx, req.OrderID)
+	if err != nil {
+		log.Printf("Failed to check hold cancellation for order ID %d: %v", req.OrderID, err)
+		return fmt.Errorf("failed to check hold cancellation: %w", err)
+	}
+	if cancelled {
+		return fmt.Errorf("invalid hold: order ID %d was already released", req.OrderID)
+	}
+
 	productIDs := []int64{}
+	lockIDs := []int64{}
 	updateRequestMap := make(map[int64]productModel.Product)
 	holdAudit := []productModel.HoldStockAudit{}
 	for i, item := range req.Products {
+		if _, exists := updateRequestMap[item.ID]; exists {
+			return fmt.Errorf("invalid hold: product ID %d is listed more than once", item.ID)
+		}
 		productIDs = append(productIDs, item.ID)
+		lockIDs = appendUniqueID(lockIDs, item.ID)
 		updateRequestMap[item.ID] = req.Products[i]
 		holdAudit = append(holdAudit, productModel.HoldStockAudit{
 			ProductID: item.ID,
 			Quantity:  req.Products[i].OnHoldStock,
-			Status:    "held",
+			Status:    productModel.HoldStatusHeld,
+			OrderID:   req.OrderID,
+			CreatedAt: time.Now(),
+		})
+	}
+
+	variantIDs := []int64{}
+	variantRequestMap := make(map[int64]productModel.ProductVariant)
+	for i, item := range req.Variants {
+		if _, exists := variantRequestMap[item.ID]; exists {
+			return fmt.Errorf("invalid hold: variant ID %d is listed more than once", item.ID)
+		}
+		variantIDs = append(variantIDs, item.ID)
+		lockIDs = appendUniqueID(lockIDs, item.ProductID)
+		variantRequestMap[item.ID] = req.Variants[i]
+		holdAudit = append(holdAudit, productModel.HoldStockAudit{
+			ProductID: item.ProductID,
+			VariantID: item.ID,
+			Quantity:  item.OnHoldStock,
+			Status:    productModel.HoldStatusHeld,
 			OrderID:   req.OrderID,
 			CreatedAt: time.Now(),
 		})
 	}
 
-	products, err := u.productRepo.GetByIDsForUpdateTx(tx, productIDs)
+	products, err := u.productRepo.GetByIDsForUpdateTx(tx, lockIDs)
 	if err != nil {
 		log.Printf("Failed to get products for update: %v", err)
 		return fmt.Errorf("failed to get products for update: %w", err)
 	}
 
+	withVariants, err := u.productRepo.GetProductIDsWithVariantsTx(tx, productIDs)
+	if err != nil {
+		log.Printf("Failed to get products with variants: %v", err)
+		return fmt.Errorf("failed to get products with variants: %w", err)
+	}
+
+	productStatus := make(map[int64]string, len(products))
 	for _, product := range products {
+		productStatus[product.ID] = product.Status
 		if updateReq, exists := updateRequestMap[product.ID]; exists {
+			if product.Status == productModel.ProductStatusDiscontinued {
+				return fmt.Errorf("product ID %d is discontinued", product.ID)
+			}
+			if withVariants[product.ID] {
+				return fmt.Errorf("invalid hold: product ID %d has variants, hold one of them instead", product.ID)
+			}
 			if updateReq.OnHoldStock < 0 {
 				return fmt.Errorf("on-hold stock cannot be negative for product ID %d", product.ID)
 			}
@@ -182,9 +360,10 @@ func (u *productUsecase) HoldStockInBulk(ctx context.Context, req *productModel.
 					updateReq.OnHoldStock, product.Stock, product.ID)
 			}
 
+			stock := product.Stock - updateReq.OnHoldStock
 			err = u.productRepo.UpdateTx(tx, int(product.ID), &productModel.UpdateProductRequest{
 				OnHoldStock: product.OnHoldStock + updateReq.OnHoldStock,
-				Stock:       product.Stock - updateReq.OnHoldStock,
+				Stock:       &stock,
 			})
 			if err != nil {
 				log.Printf("Failed to update on-hold stock for product ID %d: %v", product.ID, err)
@@ -193,21 +372,42 @@ func (u *productUsecase) HoldStockInBulk(ctx context.Context, req *productModel.
 		}
 	}
 
-	err = u.productRepo.InsertHoldStockAuditsTx(tx, holdAudit)
+	err = u.adjustVariantsTx(tx, variantIDs, func(variant *productModel.ProductVariant) error {
+		holdReq := variantRequestMap[variant.ID]
+		if holdReq.ProductID != variant.ProductID {
+			return fmt.Errorf("invalid hold: variant ID %d does not belong to product ID %d", variant.ID, holdReq.ProductID)
+		}
+		if variant.Status == productModel.ProductStatusDiscontinued || productStatus[variant.ProductID] == productModel.ProductStatusDiscontinued {
+			return fmt.Errorf("variant ID %d is discontinued", variant.ID)
+		}
+		if holdReq.OnHoldStock < 0 {
+			return fmt.Errorf("on-hold stock cannot be negative for variant ID %d", variant.ID)
+		}
+		if holdReq.OnHoldStock > variant.Stock {
+			return fmt.Errorf("on-hold stock (%d) cannot exceed available stock (%d) for variant ID %d",
+				holdReq.OnHoldStock, variant.Stock, variant.ID)
+		}
+
+		variant.OnHoldStock += holdReq.OnHoldStock
+		variant.Stock -= holdReq.OnHoldStock
+		return nil
+	})
 	if err != nil {
-		log.Printf("Failed to insert hold stock audits: %v", err)
-		return fmt.Errorf("failed to insert hold stock audits: %w", err)
+		return err
 	}
 
-	err = tx.Commit()
+	err = u.productRepo.InsertHoldStockAuditsTx(tx, holdAudit)
 	if err != nil {
-		log.Printf("Failed to commit transaction: %v", err)
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		log.Printf("Failed to insert hold stock audits: %v", err)
+		return fmt.Errorf("failed to insert hold stock audits: %w", err)
 	}
 
 	return nil
 }
 
+// ReleaseHeldStock returns the stock held for an order to available stock and
+// refuses any later hold for the order, so a hold that was still in flight when
+// the order was given up cannot leave stock held
 func (u *productUsecase) ReleaseHeldStock(ctx context.Context, req *productModel.ReleaseHeldStockRequest) error {
 
 	tx, err := u.productRepo.TxBegin(ctx)
@@ -223,25 +423,44 @@ func (u *productUsecase) ReleaseHeldStock(ctx context.Context, req *productModel
 		}
 	}()
 
+	// Cancelled before the audits are read: a concurrent hold either committed
+	// first and is released below, or waits on the marker and is refused
+	err = u.productRepo.CancelHoldTx(tx, req.OrderID)
+	if err != nil {
+		log.Printf("Failed to cancel hold for order ID %d: %v", req.OrderID, err)
+		return fmt.Errorf("failed to cancel hold: %w", err)
+	}
+
 	productHoldAudits, err := u.productRepo.GetHoldStockAuditsByOrderIDTx(tx, req.OrderID)
 	if err != nil {
 		log.Printf("Failed to get hold stock audits for order ID %d: %v", req.OrderID, err)
 		return fmt.Errorf("failed to get hold stock audits: %w", err)
 	}
 
-	updateRequestMap := make(map[int64]productModel.HoldStockAudit)
+	heldQuantities := make(map[int64]int)
+	variantQuantities := make(map[int64]int)
 	itemIDs := []int64{}
-	for i, item := range productHoldAudits {
-		if item.Status != "held" {
+	variantIDs := []int64{}
+	held := 0
+	for _, item := range productHoldAudits {
+		if item.Status != productModel.HoldStatusHeld {
 			continue
 		}
-		updateRequestMap[item.ProductID] = productHoldAudits[i]
-		itemIDs = append(itemIDs, item.ProductID)
+		held++
+		itemIDs = appendUniqueID(itemIDs, item.ProductID)
+		if item.VariantID != 0 {
+			variantIDs = appendUniqueID(variantIDs, item.VariantID)
+			variantQuantities[item.VariantID] += item.Quantity
+			continue
+		}
+		heldQuantities[item.ProductID] += item.Quantity
 	}
 
 	if len(itemIDs) == 0 {
+		// Nothing to release, but the cancellation must still be kept
 		log.Printf("No held stock found for order ID %d", req.OrderID)
-		return nil
+		err = tx.Commit()
+		return err
 	}
 
 	products, err := u.productRepo.GetByIDsForUpdateTx(tx, itemIDs)
@@ -251,10 +470,11 @@ func (u *productUsecase) ReleaseHeldStock(ctx context.Context, req *productModel
 	}
 
 	for _, product := range products {
-		if holdAudit, exists := updateRequestMap[product.ID]; exists {
+		if quantity, exists := heldQuantities[product.ID]; exists {
+			stock := product.Stock + quantity
 			err = u.productRepo.UpdateTx(tx, int(product.ID), &productModel.UpdateProductRequest{
-				OnHoldStock: product.OnHoldStock - holdAudit.Quantity,
-				Stock:       product.Stock + holdAudit.Quantity,
+				OnHoldStock: product.OnHoldStock - quantity,
+				Stock:       &stock,
 			})
 			if err != nil {
 				log.Printf("Failed to update stock for product ID %d: %v", product.ID, err)
@@ -263,11 +483,292 @@ func (u *productUsecase) ReleaseHeldStock(ctx context.Context, req *productModel
 		}
 	}
 
-	err = u.productRepo.UpdateHoldStockAuditsStatusTx(tx, req.OrderID, "cancelled")
+	err = u.adjustVariantsTx(tx, variantIDs, func(variant *productModel.ProductVariant) error {
+		quantity := variantQuantities[variant.ID]
+		variant.OnHoldStock -= quantity
+		variant.Stock += quantity
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = u.finishHoldStockAuditsTx(tx, req.OrderID, productModel.HoldStatusCancelled, held)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CommitHeldStock converts the stock held for an order into a sale, permanently
+// removing it from on-hold stock and marking the hold audits as successful
+func (u *productUsecase) CommitHeldStock(ctx context.Context, req *productModel.CommitHeldStockRequest) error {
+	if req.OrderID <= 0 {
+		return fmt.Errorf("invalid order ID")
+	}
+
+	tx, err := u.productRepo.TxBegin(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	productHoldAudits, err := u.productRepo.GetHoldStockAuditsByOrderIDTx(tx, req.OrderID)
+	if err != nil {
+		log.Printf("Failed to get hold stock audits for order ID %d: %v", req.OrderID, err)
+		return fmt.Errorf("failed to get hold stock audits: %w", err)
+	}
+
+	heldQuantities := make(map[int64]int)
+	variantQuantities := make(map[int64]int)
+	itemIDs := []int64{}
+	variantIDs := []int64{}
+	held := 0
+	committed := false
+	for _, item := range productHoldAudits {
+		switch item.Status {
+		case productModel.HoldStatusHeld:
+			held++
+			itemIDs = appendUniqueID(itemIDs, item.ProductID)
+			if item.VariantID != 0 {
+				variantIDs = appendUniqueID(variantIDs, item.VariantID)
+				variantQuantities[item.VariantID] += item.Quantity
+				continue
+			}
+			heldQuantities[item.ProductID] += item.Quantity
+		case productModel.HoldStatusSuccess:
+			committed = true
+		}
+	}
+
+	if len(itemIDs) == 0 {
+		if committed {
+			// Already committed by a previous call, nothing left to do
+			log.Printf("Held stock for order ID %d already committed", req.OrderID)
+			err = tx.Commit()
+			return err
+		}
+		err = fmt.Errorf("no held stock found for order ID %d", req.OrderID)
+		return err
+	}
+
+	products, err := u.productRepo.GetByIDsForUpdateTx(tx, itemIDs)
+	if err != nil {
+		log.Printf("Failed to get products for update: %v", err)
+		return fmt.Errorf("failed to get products for update: %w", err)
+	}
+
+	for _, product := range products {
+		quantity, exists := heldQuantities[product.ID]
+		if !exists {
+			continue // only its variants were held
+		}
+		if quantity > product.OnHoldStock {
+			err = fmt.Errorf("held quantity (%d) exceeds on-hold stock (%d) for product ID %d",
+				quantity, product.OnHoldStock, product.ID)
+			return err
+		}
+
+		err = u.productRepo.UpdateTx(tx, int(product.ID), &productModel.UpdateProductRequest{
+			OnHoldStock: product.OnHoldStock - quantity,
+		})
+		if err != nil {
+			log.Printf("Failed to commit held stock for product ID %d: %v", product.ID, err)
+			return fmt.Errorf("failed to commit held stock for product ID %d: %w", product.ID, err)
+		}
+	}
+
+	err = u.adjustVariantsTx(tx, variantIDs, func(variant *productModel.ProductVariant) error {
+		quantity := variantQuantities[variant.ID]
+		if quantity > variant.OnHoldStock {
+			return fmt.Errorf("held quantity (%d) exceeds on-hold stock (%d) for variant ID %d",
+				quantity, variant.OnHoldStock, variant.ID)
+		}
+		variant.OnHoldStock -= quantity
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = u.finishHoldStockAuditsTx(tx, req.OrderID, productModel.HoldStatusSuccess, held)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Committed held stock for order ID %d", req.OrderID)
+	return nil
+}
+
+// finishHoldStockAuditsTx moves the held audits of an order to a final status. The
+// audits were locked when read, so anything but the held count means the hold
+// changed underneath this call and its stock changes must not be committed.
+func (u *productUsecase) finishHoldStockAuditsTx(tx *sql.Tx, orderID int64, status string, held int) error {
+	updated, err := u.productRepo.UpdateHoldStockAuditsStatusTx(tx, orderID, status)
 	if err != nil {
 		log.Printf("Failed to update hold stock audits status: %v", err)
 		return fmt.Errorf("failed to update hold stock audits status: %w", err)
 	}
+	if updated != int64(held) {
+		return fmt.Errorf("held stock for order ID %d changed concurrently: %d of %d hold audits updated", orderID, updated, held)
+	}
+	return nil
+}
+
+// stockKey identifies a product, or one of its variants, whose stock changes
+type stockKey struct {
+	ProductID int64
+	VariantID int64 // 0 for the product itself
+}
+
+// Restock adds stock back to products and variants and audits each increment
+// under the request's reference. A reference that was already restocked is a no-op.
+func (u *productUsecase) Restock(ctx context.Context, req *productModel.RestockRequest) error {
+	req.Reference = strings.TrimSpace(req.Reference)
+	if req.Reference == "" {
+		return fmt.Errorf("invalid reference")
+	}
+	if len(req.Items) == 0 {
+		return fmt.Errorf("invalid restock: no items")
+	}
+
+	quantities := make(map[stockKey]int)
+	keys := []stockKey{}
+	itemIDs := []int64{}
+	variantIDs := []int64{}
+	for _, item := range req.Items {
+		if item.ProductID <= 0 {
+			return fmt.Errorf("invalid product ID: %d", item.ProductID)
+		}
+		if item.VariantID < 0 {
+			return fmt.Errorf("invalid variant ID: %d", item.VariantID)
+		}
+		if item.Quantity <= 0 {
+			return fmt.Errorf("invalid quantity for product ID %d: must be greater than 0", item.ProductID)
+		}
+		key := stockKey{ProductID: item.ProductID, VariantID: item.VariantID}
+		if _, exists := quantities[key]; !exists {
+			keys = append(keys, key)
+		}
+		quantities[key] += item.Quantity
+		itemIDs = appendUniqueID(itemIDs, item.ProductID)
+		if item.VariantID != 0 {
+			variantIDs = appendUniqueID(variantIDs, item.VariantID)
+		}
+	}
+
+	tx, err := u.productRepo.TxBegin(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	restocked, err := u.productRepo.CountStockAuditsByReferenceTx(tx, req.Reference)
+	if err != nil {
+		log.Printf("Failed to get stock audits for reference %s: %v", req.Reference, err)
+		return fmt.Errorf("failed to get stock audits: %w", err)
+	}
+	if restocked > 0 {
+		// Already restocked by a previous call, nothing left to do
+		log.Printf("Stock for reference %s already restocked", req.Reference)
+		err = tx.Commit()
+		return err
+	}
+
+	products, err := u.productRepo.GetByIDsForUpdateTx(tx, itemIDs)
+	if err != nil {
+		log.Printf("Failed to get products for update: %v", err)
+		return fmt.Errorf("failed to get products for update: %w", err)
+	}
+	if len(products) != len(itemIDs) {
+		err = fmt.Errorf("product not found")
+		return err
+	}
+
+	for _, product := range products {
+		quantity, exists := quantities[stockKey{ProductID: product.ID}]
+		if !exists {
+			continue // only its variants are restocked
+		}
+		stock := product.Stock + quantity
+		err = u.productRepo.UpdateTx(tx, int(product.ID), &productModel.UpdateProductRequest{
+			OnHoldStock: product.OnHoldStock,
+			Stock:       &stock,
+		})
+		if err != nil {
+			log.Printf("Failed to restock product ID %d: %v", product.ID, err)
+			return fmt.Errorf("failed to restock product ID %d: %w", product.ID, err)
+		}
+	}
+
+	variantQuantities := make(map[int64]int)
+	variantProducts := make(map[int64]int64)
+	for _, key := range keys {
+		if key.VariantID != 0 {
+			variantQuantities[key.VariantID] += quantities[key]
+			if productID, exists := variantProducts[key.VariantID]; exists && productID != key.ProductID {
+				err = fmt.Errorf("invalid restock: variant ID %d is listed under several products", key.VariantID)
+				return err
+			}
+			variantProducts[key.VariantID] = key.ProductID
+		}
+	}
+	err = u.adjustVariantsTx(tx, variantIDs, func(variant *productModel.ProductVariant) error {
+		if variantProducts[variant.ID] != variant.ProductID {
+			return fmt.Errorf("invalid restock: variant ID %d does not belong to product ID %d", variant.ID, variantProducts[variant.ID])
+		}
+		variant.Stock += variantQuantities[variant.ID]
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	audits := make([]productModel.StockAudit, 0, len(keys))
+	for _, key := range keys {
+		audits = append(audits, productModel.StockAudit{
+			ProductID: key.ProductID,
+			VariantID: key.VariantID,
+			Quantity:  quantities[key],
+			Reference: req.Reference,
+			Reason:    req.Reason,
+			CreatedAt: now,
+		})
+	}
+
+	err = u.productRepo.InsertStockAuditsTx(tx, audits)
+	if err != nil {
+		log.Printf("Failed to insert stock audits: %v", err)
+		return fmt.Errorf("failed to insert stock audits: %w", err)
+	}
 
 	err = tx.Commit()
 	if err != nil {
@@ -275,5 +776,6 @@ func (u *productUsecase) ReleaseHeldStock(ctx context.Context, req *productModel
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	log.Printf("Restocked %d products and variants for reference %s", len(keys), req.Reference)
 	return nil
 }
@@ -0,0 +1,268 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	productModel "github.com/Christyan39/test-eDot/internal/models/product"
+	"github.com/Christyan39/test-eDot/pkg/money"
+)
+
+// appendUniqueID appends an ID unless the slice already holds it
+func appendUniqueID(ids []int64, id int64) []int64 {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
+
+// adjustVariantsTx locks the given variants, applies a stock change to each and
+// writes them back. Callers lock the variants' products first.
+func (u *productUsecase) adjustVariantsTx(tx *sql.Tx, variantIDs []int64, adjust func(variant *productModel.ProductVariant) error) error {
+	if len(variantIDs) == 0 {
+		return nil
+	}
+
+	variants, err := u.productRepo.GetVariantsByIDsForUpdateTx(tx, variantIDs)
+	if err != nil {
+		log.Printf("Failed to get variants for update: %v", err)
+		return fmt.Errorf("failed to get variants for update: %w", err)
+	}
+	if len(variants) != len(variantIDs) {
+		return fmt.Errorf("variant not found")
+	}
+
+	for i := range variants {
+		if err := adjust(&variants[i]); err != nil {
+			return err
+		}
+		if err := u.productRepo.UpdateVariantTx(tx, &variants[i]); err != nil {
+			log.Printf("Failed to update stock for variant ID %d: %v", variants[i].ID, err)
+			return fmt.Errorf("failed to update stock for variant ID %d: %w", variants[i].ID, err)
+		}
+	}
+
+	return nil
+}
+
+// attachVariants loads the variants of each product
+func (u *productUsecase) attachVariants(products []productModel.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]int64, 0, len(products))
+	for _, product := range products {
+		productIDs = append(productIDs, product.ID)
+	}
+
+	variants, err := u.productRepo.GetVariantsByProductIDs(productIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get product variants: %w", err)
+	}
+
+	variantMap := make(map[int64][]productModel.ProductVariant)
+	for _, variant := range variants {
+		variantMap[variant.ProductID] = append(variantMap[variant.ProductID], variant)
+	}
+	for i := range products {
+		products[i].Variants = variantMap[products[i].ID]
+	}
+
+	return nil
+}
+
+// attachProductVariants loads the variants of a product
+func (u *productUsecase) attachProductVariants(product *productModel.Product) error {
+	products := []productModel.Product{*product}
+	if err := u.attachVariants(products); err != nil {
+		return err
+	}
+	product.Variants = products[0].Variants
+	return nil
+}
+
+// CreateVariant adds a variant to a product on behalf of a member of the shop
+// owning it
+func (u *productUsecase) CreateVariant(ctx context.Context, userID int, req *productModel.CreateVariantRequest) (*productModel.ProductVariant, error) {
+	product, err := u.ownedProduct(ctx, userID, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Status == productModel.ProductStatusDiscontinued {
+		return nil, fmt.Errorf("invalid product: product ID %d is discontinued", product.ID)
+	}
+
+	req.SKU = strings.TrimSpace(req.SKU)
+	if req.SKU == "" {
+		return nil, fmt.Errorf("sku is required")
+	}
+	if len(req.SKU) > 64 {
+		return nil, fmt.Errorf("invalid SKU: must be at most 64 characters")
+	}
+	req.Options, err = normalizeVariantOptions(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Options) == 0 {
+		return nil, fmt.Errorf("options are required")
+	}
+	if req.Price != nil {
+		if err := validateVariantPrice(product, req.Price); err != nil {
+			return nil, err
+		}
+	}
+	if req.Stock < 0 {
+		return nil, fmt.Errorf("invalid stock: must be 0 or more")
+	}
+
+	variantID, err := u.productRepo.CreateVariant(req)
+	if err != nil {
+		log.Printf("Failed to create variant for product ID %d: %v", product.ID, err)
+		return nil, fmt.Errorf("failed to create variant: %w", err)
+	}
+
+	log.Printf("Variant ID %d (%s) added to product ID %d by user %d", variantID, req.SKU, product.ID, userID)
+	return u.productVariant(product.ID, variantID)
+}
+
+// UpdateVariant applies a partial update to a variant on behalf of a member of
+// the shop owning its product. Stock on hold is managed by orders.
+func (u *productUsecase) UpdateVariant(ctx context.Context, userID int, req *productModel.UpdateVariantRequest) (*productModel.ProductVariant, error) {
+	product, err := u.ownedProduct(ctx, userID, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if req.ID <= 0 {
+		return nil, fmt.Errorf("invalid variant ID")
+	}
+
+	req.SKU = strings.TrimSpace(req.SKU)
+	if len(req.SKU) > 64 {
+		return nil, fmt.Errorf("invalid SKU: must be at most 64 characters")
+	}
+	if req.Options != nil {
+		req.Options, err = normalizeVariantOptions(req.Options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if req.Price != nil {
+		if err := validateVariantPrice(product, req.Price); err != nil {
+			return nil, err
+		}
+	}
+	if req.Stock != nil && *req.Stock < 0 {
+		return nil, fmt.Errorf("invalid stock: must be 0 or more")
+	}
+	switch req.Status {
+	case "", productModel.ProductStatusActive, productModel.ProductStatusInactive, productModel.ProductStatusDiscontinued:
+	default:
+		return nil, fmt.Errorf("invalid status %q, must be one of active, inactive, discontinued", req.Status)
+	}
+
+	tx, err := u.productRepo.TxBegin(ctx)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// Lock the product before the variant so the stock written back is not stale
+	_, err = u.productRepo.GetByIDForUpdateTx(tx, int(product.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.adjustVariantsTx(tx, []int64{req.ID}, func(variant *productModel.ProductVariant) error {
+		if variant.ProductID != product.ID {
+			return fmt.Errorf("variant not found")
+		}
+		if req.SKU != "" {
+			variant.SKU = req.SKU
+		}
+		if len(req.Options) > 0 {
+			variant.Options = req.Options
+		}
+		if req.Price != nil {
+			variant.Price = req.Price
+		}
+		if req.Stock != nil {
+			variant.Stock = *req.Stock
+		}
+		if req.Status != "" {
+			variant.Status = req.Status
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Variant ID %d of product ID %d updated by user %d", req.ID, product.ID, userID)
+	return u.productVariant(product.ID, req.ID)
+}
+
+// productVariant retrieves one variant of a product
+func (u *productUsecase) productVariant(productID, variantID int64) (*productModel.ProductVariant, error) {
+	variants, err := u.productRepo.GetVariantsByProductIDs([]int64{productID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product variants: %w", err)
+	}
+	for i := range variants {
+		if variants[i].ID == variantID {
+			return &variants[i], nil
+		}
+	}
+	return nil, fmt.Errorf("variant not found")
+}
+
+// normalizeVariantOptions trims option names and values and lower-cases the
+// names, so {"Size": "M"} and {"size": "M"} are the same option
+func normalizeVariantOptions(options map[string]string) (map[string]string, error) {
+	normalized := make(map[string]string, len(options))
+	for name, value := range options {
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if name == "" || value == "" {
+			return nil, fmt.Errorf("invalid options: names and values must not be empty")
+		}
+		if len(name) > 50 || len(value) > 100 {
+			return nil, fmt.Errorf("invalid options: names must be at most 50 and values at most 100 characters")
+		}
+		if _, exists := normalized[name]; exists {
+			return nil, fmt.Errorf("invalid options: %s is given more than once", name)
+		}
+		normalized[name] = value
+	}
+	return normalized, nil
+}
+
+// validateVariantPrice checks that a price override is positive and in the
+// product's currency
+func validateVariantPrice(product *productModel.Product, price *money.Money) error {
+	if price.Currency != product.Price.Currency {
+		return fmt.Errorf("invalid price: must be in the product's currency %s", product.Price.Currency)
+	}
+	if !price.IsPositive() {
+		return fmt.Errorf("invalid price: must be greater than 0")
+	}
+	return nil
+}
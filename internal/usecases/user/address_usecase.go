@@ -0,0 +1,119 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	models "github.com/Christyan39/test-eDot/internal/models/user"
+)
+
+var countryCodeRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// validateAddress normalizes and validates an address before it is stored
+func (u *UserUsecase) validateAddress(req *models.CreateAddressRequest) error {
+	req.Label = strings.TrimSpace(req.Label)
+	req.RecipientName = strings.TrimSpace(req.RecipientName)
+	req.Phone = strings.TrimSpace(req.Phone)
+	req.Line1 = strings.TrimSpace(req.Line1)
+	req.Line2 = strings.TrimSpace(req.Line2)
+	req.City = strings.TrimSpace(req.City)
+	req.Province = strings.TrimSpace(req.Province)
+	req.PostalCode = strings.TrimSpace(req.PostalCode)
+	req.Country = strings.ToUpper(strings.TrimSpace(req.Country))
+
+	switch {
+	case req.Label == "":
+		return fmt.Errorf("label is required")
+	case len(req.Label) > 50:
+		return fmt.Errorf("invalid label: must be at most 50 characters")
+	case req.RecipientName == "":
+		return fmt.Errorf("recipient_name is required")
+	case req.Phone == "":
+		return fmt.Errorf("phone is required")
+	case req.Line1 == "":
+		return fmt.Errorf("line1 is required")
+	case req.City == "":
+		return fmt.Errorf("city is required")
+	case req.Province == "":
+		return fmt.Errorf("province is required")
+	case req.PostalCode == "":
+		return fmt.Errorf("postal_code is required")
+	case !countryCodeRegex.MatchString(req.Country):
+		return fmt.Errorf("invalid country: use a 2-letter ISO country code, e.g. ID")
+	}
+
+	// Only Indonesian phone numbers have a known format
+	if req.Country == "ID" {
+		if err := u.validatePhone(req.Phone); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateAddress adds an address to the user's address book
+func (u *UserUsecase) CreateAddress(ctx context.Context, userID int, req *models.CreateAddressRequest) (*models.Address, error) {
+	if err := u.validateAddress(req); err != nil {
+		return nil, err
+	}
+
+	addressID, err := u.userRepo.CreateAddress(ctx, userID, req)
+	if err != nil {
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+
+	return u.GetAddress(ctx, userID, addressID)
+}
+
+// ListAddresses retrieves the user's address book
+func (u *UserUsecase) ListAddresses(ctx context.Context, userID int) ([]models.Address, error) {
+	addresses, err := u.userRepo.ListAddresses(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+	return addresses, nil
+}
+
+// GetAddress retrieves one of the user's addresses
+func (u *UserUsecase) GetAddress(ctx context.Context, userID int, addressID int64) (*models.Address, error) {
+	address, err := u.userRepo.GetAddress(ctx, userID, addressID)
+	if err != nil {
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+	if address == nil {
+		return nil, fmt.Errorf("address not found")
+	}
+	return address, nil
+}
+
+// UpdateAddress replaces one of the user's addresses
+func (u *UserUsecase) UpdateAddress(ctx context.Context, userID int, addressID int64, req *models.UpdateAddressRequest) (*models.Address, error) {
+	createReq := models.CreateAddressRequest(*req)
+	if err := u.validateAddress(&createReq); err != nil {
+		return nil, err
+	}
+	*req = models.UpdateAddressRequest(createReq)
+
+	if err := u.userRepo.UpdateAddress(ctx, userID, addressID, req); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+
+	return u.GetAddress(ctx, userID, addressID)
+}
+
+// DeleteAddress removes one of the user's addresses
+func (u *UserUsecase) DeleteAddress(ctx context.Context, userID int, addressID int64) error {
+	if err := u.userRepo.DeleteAddress(ctx, userID, addressID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return err
+		}
+		return fmt.Errorf("usecase error: %v", err)
+	}
+	return nil
+}
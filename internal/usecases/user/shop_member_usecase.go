@@ -0,0 +1,61 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	models "github.com/Christyan39/test-eDot/internal/models/user"
+)
+
+// AddShopMember gives a user access to manage a shop
+func (u *UserUsecase) AddShopMember(ctx context.Context, shopID int, req *models.AddShopMemberRequest) (*models.ShopMember, error) {
+	if shopID <= 0 {
+		return nil, fmt.Errorf("invalid shop ID")
+	}
+	if req.UserID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+
+	req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+	if req.Role == "" {
+		req.Role = models.ShopRoleStaff
+	}
+	if req.Role != models.ShopRoleOwner && req.Role != models.ShopRoleStaff {
+		return nil, fmt.Errorf("invalid role: must be owner or staff")
+	}
+
+	if err := u.userRepo.SaveShopMember(ctx, shopID, req); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+
+	return u.GetShopMember(ctx, shopID, req.UserID)
+}
+
+// GetShopMember retrieves a user's membership of a shop
+func (u *UserUsecase) GetShopMember(ctx context.Context, shopID, userID int) (*models.ShopMember, error) {
+	member, err := u.userRepo.GetShopMember(ctx, shopID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+	if member == nil {
+		return nil, fmt.Errorf("shop member not found")
+	}
+	return member, nil
+}
+
+// ListUserShops retrieves the shops a user is a member of
+func (u *UserUsecase) ListUserShops(ctx context.Context, userID int) ([]models.ShopMember, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+
+	members, err := u.userRepo.ListShopMembersByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+	return members, nil
+}
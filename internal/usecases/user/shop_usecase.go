@@ -0,0 +1,205 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	models "github.com/Christyan39/test-eDot/internal/models/user"
+)
+
+// validateShop normalizes and validates a shop's details before they are stored
+func validateShop(name, description, region *string) error {
+	*name = strings.TrimSpace(*name)
+	*description = strings.TrimSpace(*description)
+	*region = strings.TrimSpace(*region)
+
+	switch {
+	case *name == "":
+		return fmt.Errorf("name is required")
+	case len(*name) < 2 || len(*name) > 100:
+		return fmt.Errorf("invalid name: must be between 2 and 100 characters")
+	case len(*description) > 1000:
+		return fmt.Errorf("invalid description: must be at most 1000 characters")
+	case len(*region) > 50:
+		return fmt.Errorf("invalid region: must be at most 50 characters")
+	}
+	return nil
+}
+
+// CreateShop opens a shop owned by the user
+func (u *UserUsecase) CreateShop(ctx context.Context, userID int, req *models.CreateShopRequest) (*models.Shop, error) {
+	if err := validateShop(&req.Name, &req.Description, &req.Region); err != nil {
+		return nil, err
+	}
+
+	shopID, err := u.userRepo.CreateShop(ctx, userID, req)
+	if err != nil {
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+
+	return u.GetShop(ctx, shopID)
+}
+
+// GetShop retrieves a shop
+func (u *UserUsecase) GetShop(ctx context.Context, shopID int) (*models.Shop, error) {
+	if shopID <= 0 {
+		return nil, fmt.Errorf("invalid shop ID")
+	}
+
+	shop, err := u.userRepo.GetShop(ctx, shopID)
+	if err != nil {
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+	if shop == nil {
+		return nil, fmt.Errorf("shop not found")
+	}
+	return shop, nil
+}
+
+// ListShops retrieves the shops with the given IDs, skipping unknown ones
+func (u *UserUsecase) ListShops(ctx context.Context, shopIDs []int) ([]models.Shop, error) {
+	for _, id := range shopIDs {
+		if id <= 0 {
+			return nil, fmt.Errorf("invalid shop ID %d", id)
+		}
+	}
+	if len(shopIDs) > 100 {
+		return nil, fmt.Errorf("invalid request: at most 100 shop IDs")
+	}
+
+	shops, err := u.userRepo.ListShopsByIDs(ctx, shopIDs)
+	if err != nil {
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+	return shops, nil
+}
+
+// UpdateShop replaces a shop's details on behalf of one of its owners
+func (u *UserUsecase) UpdateShop(ctx context.Context, userID, shopID int, req *models.UpdateShopRequest) (*models.Shop, error) {
+	if err := u.authorizeShopOwner(ctx, shopID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := validateShop(&req.Name, &req.Description, &req.Region); err != nil {
+		return nil, err
+	}
+	req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+	if req.Status == "" {
+		req.Status = models.ShopStatusActive
+	}
+	if req.Status != models.ShopStatusActive && req.Status != models.ShopStatusInactive {
+		return nil, fmt.Errorf("invalid status: must be active or inactive")
+	}
+
+	if err := u.userRepo.UpdateShop(ctx, shopID, req); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+
+	return u.GetShop(ctx, shopID)
+}
+
+// ListShopMembers retrieves the members of a shop for one of its members
+func (u *UserUsecase) ListShopMembers(ctx context.Context, userID, shopID int) ([]models.ShopMember, error) {
+	if _, err := u.authorizeShopMember(ctx, shopID, userID); err != nil {
+		return nil, err
+	}
+
+	members, err := u.userRepo.ListShopMembers(ctx, shopID)
+	if err != nil {
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+	return members, nil
+}
+
+// SetShopMember gives a user a role in a shop on behalf of one of its owners
+func (u *UserUsecase) SetShopMember(ctx context.Context, userID, shopID int, req *models.AddShopMemberRequest) (*models.ShopMember, error) {
+	if err := u.authorizeShopOwner(ctx, shopID, userID); err != nil {
+		return nil, err
+	}
+
+	if req.UserID == userID && strings.ToLower(strings.TrimSpace(req.Role)) != models.ShopRoleOwner {
+		if err := u.ensureAnotherOwner(ctx, shopID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.AddShopMember(ctx, shopID, req)
+}
+
+// RemoveShopMember removes a user's access to a shop on behalf of one of its
+// owners. A shop always keeps at least one owner.
+func (u *UserUsecase) RemoveShopMember(ctx context.Context, userID, shopID, memberID int) error {
+	if err := u.authorizeShopOwner(ctx, shopID, userID); err != nil {
+		return err
+	}
+	if memberID <= 0 {
+		return fmt.Errorf("invalid user ID")
+	}
+
+	member, err := u.userRepo.GetShopMember(ctx, shopID, memberID)
+	if err != nil {
+		return fmt.Errorf("usecase error: %v", err)
+	}
+	if member == nil {
+		return fmt.Errorf("shop member not found")
+	}
+	if member.Role == models.ShopRoleOwner {
+		if err := u.ensureAnotherOwner(ctx, shopID, memberID); err != nil {
+			return err
+		}
+	}
+
+	if err := u.userRepo.DeleteShopMember(ctx, shopID, memberID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return err
+		}
+		return fmt.Errorf("usecase error: %v", err)
+	}
+	return nil
+}
+
+// authorizeShopMember checks that the user belongs to the shop
+func (u *UserUsecase) authorizeShopMember(ctx context.Context, shopID, userID int) (*models.ShopMember, error) {
+	if _, err := u.GetShop(ctx, shopID); err != nil {
+		return nil, err
+	}
+
+	member, err := u.userRepo.GetShopMember(ctx, shopID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("usecase error: %v", err)
+	}
+	if member == nil {
+		return nil, fmt.Errorf("forbidden: user %d is not a member of shop %d", userID, shopID)
+	}
+	return member, nil
+}
+
+// authorizeShopOwner checks that the user owns the shop
+func (u *UserUsecase) authorizeShopOwner(ctx context.Context, shopID, userID int) error {
+	member, err := u.authorizeShopMember(ctx, shopID, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role != models.ShopRoleOwner {
+		return fmt.Errorf("forbidden: user %d is not an owner of shop %d", userID, shopID)
+	}
+	return nil
+}
+
+// ensureAnotherOwner checks that the shop has an owner other than the user
+func (u *UserUsecase) ensureAnotherOwner(ctx context.Context, shopID, userID int) error {
+	members, err := u.userRepo.ListShopMembers(ctx, shopID)
+	if err != nil {
+		return fmt.Errorf("usecase error: %v", err)
+	}
+	for _, member := range members {
+		if member.Role == models.ShopRoleOwner && member.UserID != userID {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid request: a shop must keep at least one owner")
+}
@@ -14,6 +14,21 @@ import (
 type UserUsecaseInterface interface {
 	CreateUser(ctx context.Context, req *models.CreateUserRequest) error
 	Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error)
+	CreateAddress(ctx context.Context, userID int, req *models.CreateAddressRequest) (*models.Address, error)
+	ListAddresses(ctx context.Context, userID int) ([]models.Address, error)
+	GetAddress(ctx context.Context, userID int, addressID int64) (*models.Address, error)
+	UpdateAddress(ctx context.Context, userID int, addressID int64, req *models.UpdateAddressRequest) (*models.Address, error)
+	DeleteAddress(ctx context.Context, userID int, addressID int64) error
+	AddShopMember(ctx context.Context, shopID int, req *models.AddShopMemberRequest) (*models.ShopMember, error)
+	GetShopMember(ctx context.Context, shopID, userID int) (*models.ShopMember, error)
+	ListUserShops(ctx context.Context, userID int) ([]models.ShopMember, error)
+	ListShopMembers(ctx context.Context, userID, shopID int) ([]models.ShopMember, error)
+	SetShopMember(ctx context.Context, userID, shopID int, req *models.AddShopMemberRequest) (*models.ShopMember, error)
+	RemoveShopMember(ctx context.Context, userID, shopID, memberID int) error
+	CreateShop(ctx context.Context, userID int, req *models.CreateShopRequest) (*models.Shop, error)
+	GetShop(ctx context.Context, shopID int) (*models.Shop, error)
+	ListShops(ctx context.Context, shopIDs []int) ([]models.Shop, error)
+	UpdateShop(ctx context.Context, userID, shopID int, req *models.UpdateShopRequest) (*models.Shop, error)
 }
 
 // UserUsecase implements UserUsecaseInterface
@@ -0,0 +1,18 @@
+USE edot_order;
+
+-- Idempotency keys for POST /orders so client retries replay the original response
+CREATE TABLE IF NOT EXISTS order_idempotency_keys (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    order_id INT NULL,
+    response_status INT NULL,
+    response_body JSON NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- A key is unique per user
+    UNIQUE KEY uq_user_idempotency_key (user_id, idempotency_key),
+    INDEX idx_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,20 @@
+USE edot_order;
+
+-- Transactional outbox: events are written with the order and relayed to NSQ afterwards
+CREATE TABLE IF NOT EXISTS order_outbox (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    topic VARCHAR(100) NOT NULL,
+    payload JSON NOT NULL,
+    deliver_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    status ENUM('pending', 'sent', 'failed') NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error VARCHAR(500) NULL,
+    available_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP NULL,
+
+    -- Indexes for better query performance
+    INDEX idx_status_available_at (status, available_at),
+    INDEX idx_order_id (order_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
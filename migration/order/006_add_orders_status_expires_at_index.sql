@@ -0,0 +1,4 @@
+USE edot_order;
+
+-- Supports the expiry sweeper lookup of pending orders past their expiry
+ALTER TABLE orders ADD INDEX idx_status_expires_at (status, expires_at);
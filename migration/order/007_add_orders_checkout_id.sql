@@ -0,0 +1,5 @@
+USE edot_order;
+
+-- Groups the per-shop orders created by one multi-shop checkout
+ALTER TABLE orders ADD COLUMN checkout_id CHAR(32) NULL AFTER shop_id;
+ALTER TABLE orders ADD INDEX idx_checkout_id (checkout_id);
@@ -0,0 +1,12 @@
+USE edot_order;
+
+-- Orders record the buyer's currency, the shop's currency and the exchange rate
+-- between them used at checkout. Item prices and totals are in the buyer's currency.
+ALTER TABLE orders
+    MODIFY COLUMN total_price DECIMAL(19,4) NOT NULL,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER total_price,
+    ADD COLUMN shop_currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER currency,
+    ADD COLUMN exchange_rate DECIMAL(24,12) NOT NULL DEFAULT 1 AFTER shop_currency;
+
+ALTER TABLE order_items
+    MODIFY COLUMN item_price DECIMAL(19,4) NOT NULL;
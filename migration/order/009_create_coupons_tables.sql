@@ -0,0 +1,64 @@
+USE edot_order;
+
+-- Promotion codes. Amounts are in the coupon's currency and converted to the
+-- buyer's currency when applied.
+CREATE TABLE IF NOT EXISTS coupons (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(64) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    discount_type ENUM('percentage', 'fixed') NOT NULL,
+    percent_off DECIMAL(5,2) NOT NULL DEFAULT 0,
+    amount_off DECIMAL(19,4) NOT NULL DEFAULT 0,
+    min_spend DECIMAL(19,4) NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    scope ENUM('all', 'shop', 'product') NOT NULL DEFAULT 'all',
+    shop_id INT NULL,
+    product_id INT NULL,
+    usage_limit INT NOT NULL DEFAULT 0,
+    per_user_limit INT NOT NULL DEFAULT 0,
+    used_count INT NOT NULL DEFAULT 0,
+    status ENUM('active', 'inactive') NOT NULL DEFAULT 'active',
+    starts_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ends_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_coupons_code (code),
+    CONSTRAINT chk_coupons_percent_off CHECK (percent_off >= 0 AND percent_off <= 100)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- One row per order that used a coupon, removed again when the order is
+-- cancelled or expires so the usage is given back
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    coupon_id INT NOT NULL,
+    user_id INT NOT NULL,
+    order_id INT NOT NULL,
+    amount DECIMAL(19,4) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_coupon_redemptions_order (coupon_id, order_id),
+    INDEX idx_coupon_redemptions_user (coupon_id, user_id),
+    INDEX idx_coupon_redemptions_order_id (order_id),
+    FOREIGN KEY (coupon_id) REFERENCES coupons(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Discount lines applied to an order, in the order's currency
+CREATE TABLE IF NOT EXISTS order_discounts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    coupon_id INT NOT NULL,
+    code VARCHAR(64) NOT NULL,
+    product_id INT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    amount DECIMAL(19,4) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_order_discounts_order_id (order_id),
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE orders
+    ADD COLUMN coupon_code VARCHAR(64) NULL AFTER exchange_rate,
+    ADD COLUMN discount_total DECIMAL(19,4) NOT NULL DEFAULT 0 AFTER coupon_code;
@@ -0,0 +1,11 @@
+USE edot_order;
+
+-- Tax computed per item from the tax rules, and its sum on the order. Inclusive
+-- tax is part of the item price, exclusive tax was added to the order total.
+ALTER TABLE order_items
+    ADD COLUMN tax_rate DECIMAL(7,4) NOT NULL DEFAULT 0 AFTER item_price,
+    ADD COLUMN tax_amount DECIMAL(19,4) NOT NULL DEFAULT 0 AFTER tax_rate,
+    ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE AFTER tax_amount;
+
+ALTER TABLE orders
+    ADD COLUMN tax_total DECIMAL(19,4) NOT NULL DEFAULT 0 AFTER discount_total;
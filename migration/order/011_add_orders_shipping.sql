@@ -0,0 +1,8 @@
+USE edot_order;
+
+-- Address book entry an order ships to, a copy of the address at the time of
+-- the order, and the shipping fee included in total_price
+ALTER TABLE orders
+    ADD COLUMN address_id INT NULL AFTER tax_total,
+    ADD COLUMN shipping_address JSON NULL AFTER address_id,
+    ADD COLUMN shipping_fee DECIMAL(19,4) NOT NULL DEFAULT 0 AFTER shipping_address;
@@ -0,0 +1,37 @@
+USE edot_order;
+
+-- Orders with only some of their items shipped
+ALTER TABLE orders MODIFY COLUMN status ENUM('pending', 'confirmed', 'partially_shipped', 'shipped', 'delivered', 'cancelled', 'expired') NOT NULL DEFAULT 'pending';
+
+-- Parcels of an order; an order may ship in several
+CREATE TABLE IF NOT EXISTS shipments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    carrier VARCHAR(50) NOT NULL,
+    tracking_number VARCHAR(100) NOT NULL,
+    status ENUM('in_transit', 'delivered') NOT NULL DEFAULT 'in_transit',
+    shipped_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_shipments_order_id (order_id),
+    INDEX idx_shipments_tracking_number (tracking_number),
+
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Quantity of each order item carried by a shipment
+CREATE TABLE IF NOT EXISTS shipment_items (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    shipment_id INT NOT NULL,
+    order_item_id INT NOT NULL,
+    quantity INT NOT NULL,
+
+    INDEX idx_shipment_items_shipment_id (shipment_id),
+    INDEX idx_shipment_items_order_item_id (order_item_id),
+
+    CONSTRAINT chk_shipment_items_quantity_positive CHECK (quantity > 0),
+    FOREIGN KEY (shipment_id) REFERENCES shipments(id) ON DELETE CASCADE,
+    FOREIGN KEY (order_item_id) REFERENCES order_items(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
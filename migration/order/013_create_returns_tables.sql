@@ -0,0 +1,52 @@
+USE edot_order;
+
+-- Customer requests to return items of a delivered order
+CREATE TABLE IF NOT EXISTS order_returns (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    user_id INT NOT NULL,
+    status ENUM('requested', 'approved', 'rejected') NOT NULL DEFAULT 'requested',
+    reason VARCHAR(500) NOT NULL,
+    resolution_note VARCHAR(500) NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_order_returns_order_id (order_id),
+    INDEX idx_order_returns_user_id (user_id),
+    INDEX idx_order_returns_status (status),
+
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Quantity of each order item being returned
+CREATE TABLE IF NOT EXISTS order_return_items (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    return_id INT NOT NULL,
+    order_item_id INT NOT NULL,
+    quantity INT NOT NULL,
+
+    INDEX idx_order_return_items_return_id (return_id),
+    INDEX idx_order_return_items_order_item_id (order_item_id),
+
+    CONSTRAINT chk_order_return_items_quantity_positive CHECK (quantity > 0),
+    FOREIGN KEY (return_id) REFERENCES order_returns(id) ON DELETE CASCADE,
+    FOREIGN KEY (order_item_id) REFERENCES order_items(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Money owed back for approved returns, in the order's currency
+CREATE TABLE IF NOT EXISTS refunds (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    return_id INT NOT NULL,
+    order_id INT NOT NULL,
+    amount DECIMAL(19,4) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    status ENUM('pending') NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_refunds_return_id (return_id),
+    INDEX idx_refunds_order_id (order_id),
+
+    FOREIGN KEY (return_id) REFERENCES order_returns(id) ON DELETE CASCADE,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
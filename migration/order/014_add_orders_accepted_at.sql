@@ -0,0 +1,7 @@
+USE edot_order;
+
+-- When the shop accepted the order
+ALTER TABLE orders ADD COLUMN accepted_at TIMESTAMP NULL AFTER expires_at;
+
+-- Orders rejected by the shop after payment are refunded without a return
+ALTER TABLE refunds MODIFY COLUMN return_id INT NULL;
@@ -0,0 +1,4 @@
+USE edot_order;
+
+-- The product variant an item was ordered as; 0 for products without variants
+ALTER TABLE order_items ADD COLUMN variant_id INT NOT NULL DEFAULT 0 AFTER product_id;
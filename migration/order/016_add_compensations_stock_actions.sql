@@ -0,0 +1,6 @@
+USE edot_order;
+
+-- Stock actions of paid, cancelled and expired orders are queued with the
+-- order change and applied once it is committed
+ALTER TABLE order_compensations
+    MODIFY COLUMN action ENUM('release_held_stock', 'commit_held_stock') NOT NULL;
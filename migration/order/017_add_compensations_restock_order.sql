@@ -0,0 +1,5 @@
+USE edot_order;
+
+-- Paid orders cancelled before shipping are restocked once the cancellation is committed
+ALTER TABLE order_compensations
+    MODIFY COLUMN action ENUM('release_held_stock', 'commit_held_stock', 'restock_order') NOT NULL;
@@ -0,0 +1,7 @@
+USE edot_order;
+
+-- Approved returns are restocked once the approval is committed. Restocks of a
+-- return name it; 0 for every other action.
+ALTER TABLE order_compensations
+    MODIFY COLUMN action ENUM('release_held_stock', 'commit_held_stock', 'restock_order', 'restock_return') NOT NULL,
+    ADD COLUMN return_id INT NOT NULL DEFAULT 0 AFTER action;
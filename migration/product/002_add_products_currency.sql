@@ -0,0 +1,7 @@
+USE edot_product;
+
+-- Products carry the currency their price is in. Prices are widened so that
+-- large-denomination currencies and currencies with 3-decimal minor units fit.
+ALTER TABLE products
+    MODIFY COLUMN price DECIMAL(19,4) NOT NULL DEFAULT 0,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER price;
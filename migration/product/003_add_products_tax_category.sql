@@ -0,0 +1,4 @@
+USE edot_product;
+
+-- Tax category of the product, matched against the order service's tax rules
+ALTER TABLE products ADD COLUMN tax_category VARCHAR(50) NOT NULL DEFAULT 'standard' AFTER currency;
@@ -0,0 +1,5 @@
+USE edot_product;
+
+-- Shipping weight of one unit, used by the order service to compute shipping fees
+ALTER TABLE products
+    ADD COLUMN weight_grams INT NOT NULL DEFAULT 0 AFTER tax_category;
@@ -0,0 +1,17 @@
+USE edot_product;
+
+-- Stock added back outside of the hold flow (e.g. restocked returns). The
+-- reference names the cause, and is unique per product so retries are no-ops.
+CREATE TABLE IF NOT EXISTS product_stock_audit (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL,
+    reference VARCHAR(100) NOT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    -- Indexes
+    UNIQUE KEY uk_product_reference (product_id, reference),
+    INDEX idx_reference (reference),
+    INDEX idx_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,29 @@
+USE edot_product;
+
+-- Orderable versions of a product (sizes, colors, ...), each with its own stock.
+-- A NULL price means the variant sells at the product's price.
+CREATE TABLE IF NOT EXISTS product_variants (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    product_id INT NOT NULL,
+    sku VARCHAR(64) NOT NULL,
+    options JSON NOT NULL,
+    price DECIMAL(19,4) NULL,
+    stock INT NOT NULL DEFAULT 0,
+    on_hold_stock INT NOT NULL DEFAULT 0,
+    status ENUM('active', 'inactive', 'discontinued') NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- Indexes
+    UNIQUE KEY uk_product_variants_sku (product_id, sku),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Holds and restocks of a variant name it; 0 is the product itself
+ALTER TABLE product_hold_audit
+    ADD COLUMN variant_id INT NOT NULL DEFAULT 0 AFTER product_id;
+
+ALTER TABLE product_stock_audit
+    ADD COLUMN variant_id INT NOT NULL DEFAULT 0 AFTER product_id,
+    DROP INDEX uk_product_reference,
+    ADD UNIQUE KEY uk_product_variant_reference (product_id, variant_id, reference);
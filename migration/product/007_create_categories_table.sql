@@ -0,0 +1,36 @@
+USE edot_product;
+
+-- Category taxonomy. Categories form a tree; root categories have no parent.
+CREATE TABLE IF NOT EXISTS categories (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    parent_id INT NULL,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- Indexes
+    UNIQUE KEY uk_categories_parent_name (parent_id, name),
+    CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Each product belongs to at most one category, at any level of the tree
+ALTER TABLE products
+    ADD COLUMN category_id INT NULL AFTER shop_id,
+    ADD INDEX idx_products_category_id (category_id),
+    ADD CONSTRAINT fk_products_category FOREIGN KEY (category_id) REFERENCES categories(id);
+
+-- Sample taxonomy for the sample products
+INSERT INTO categories (id, parent_id, name) VALUES
+(1, NULL, 'Electronics'),
+(2, 1, 'Phones'),
+(3, 1, 'Laptops'),
+(4, NULL, 'Fashion'),
+(5, 4, 'Men'),
+(6, 4, 'Women'),
+(7, NULL, 'Books');
+
+UPDATE products SET category_id = 2 WHERE name IN ('iPhone 15 Pro', 'Samsung Galaxy S24');
+UPDATE products SET category_id = 3 WHERE name IN ('MacBook Pro 16"', 'Dell XPS 13');
+UPDATE products SET category_id = 5 WHERE name = 'Men''s T-Shirt';
+UPDATE products SET category_id = 6 WHERE name = 'Women''s Dress';
+UPDATE products SET category_id = 7 WHERE name IN ('The Great Gatsby', 'Clean Code');
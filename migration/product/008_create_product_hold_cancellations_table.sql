@@ -0,0 +1,9 @@
+USE edot_product;
+
+-- Orders whose held stock was released. A hold for one of them that arrives
+-- late (e.g. after the order service timed out and compensated) is refused,
+-- so it cannot leave stock held for an order that no longer exists.
+CREATE TABLE IF NOT EXISTS product_hold_cancellations (
+    order_id BIGINT PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
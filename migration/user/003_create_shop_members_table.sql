@@ -0,0 +1,15 @@
+USE edot_user;
+
+-- Users allowed to manage a shop's orders and products
+CREATE TABLE IF NOT EXISTS shop_members (
+    shop_id INT NOT NULL,
+    user_id INT NOT NULL,
+    role ENUM('owner', 'staff') NOT NULL DEFAULT 'staff',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (shop_id, user_id),
+    INDEX idx_shop_members_user_id (user_id),
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,23 @@
+USE edot_user;
+
+-- Shops; products and orders reference them by ID instead of copying their details
+CREATE TABLE IF NOT EXISTS shops (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    region VARCHAR(50) NOT NULL DEFAULT '',
+    status ENUM('active', 'inactive') NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_shops_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Shops that only existed through their members. They are named after the shop
+-- snapshots stored with their products by cmd/backfill/shops, which also creates
+-- shops that only existed through their products; sellers rename them afterwards.
+INSERT IGNORE INTO shops (id, name)
+SELECT DISTINCT shop_id, CONCAT('Shop ', shop_id) FROM shop_members;
+
+ALTER TABLE shop_members
+    ADD CONSTRAINT fk_shop_members_shop_id FOREIGN KEY (shop_id) REFERENCES shops(id) ON DELETE CASCADE;
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	userModels "github.com/Christyan39/test-eDot/internal/models/user"
 	"github.com/Christyan39/test-eDot/pkg/config"
@@ -59,6 +61,34 @@ func ServiceAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+// AdminAuthMiddleware only lets through users listed in ADMIN_USER_IDS
+// (comma-separated user IDs). Must run after JWTAuthMiddleware.
+func AdminAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user, err := GetUserFromContext(c.Request().Context())
+		if err != nil {
+			return echo.NewHTTPError(http.StatusUnauthorized, "missing authenticated user")
+		}
+
+		if !isAdmin(user.ID) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "Admin access required",
+			})
+		}
+
+		return next(c)
+	}
+}
+
+func isAdmin(userID int) bool {
+	for _, id := range strings.Split(config.GetEnv("ADMIN_USER_IDS", ""), ",") {
+		if strings.TrimSpace(id) == strconv.Itoa(userID) {
+			return true
+		}
+	}
+	return false
+}
+
 func GetUserFromContext(ctx context.Context) (*userModels.AuthUser, error) {
 	user, ok := ctx.Value("user").(*userModels.AuthUser)
 	if !ok || user == nil {
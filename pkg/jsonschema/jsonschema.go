@@ -0,0 +1,321 @@
+// Package jsonschema validates JSON documents against the subset of JSON Schema
+// the services use for client-supplied metadata: type, properties, required,
+// additionalProperties (as a boolean), items, enum, minimum, maximum, minLength,
+// maxLength and maxProperties. The annotations $schema, $id, title and
+// description are accepted and ignored; any other keyword fails to compile
+// rather than being silently skipped.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *json.Number       `json:"minimum,omitempty"`
+	Maximum              *json.Number       `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+}
+
+// keywords are the schema keywords Compile accepts
+var keywords = map[string]bool{
+	"$schema":              true,
+	"$id":                  true,
+	"title":                true,
+	"description":          true,
+	"type":                 true,
+	"properties":           true,
+	"required":             true,
+	"additionalProperties": true,
+	"items":                true,
+	"enum":                 true,
+	"minimum":              true,
+	"maximum":              true,
+	"minLength":            true,
+	"maxLength":            true,
+	"maxProperties":        true,
+}
+
+// types are the values of the type keyword Compile accepts
+var types = map[string]bool{
+	"object":  true,
+	"array":   true,
+	"string":  true,
+	"boolean": true,
+	"null":    true,
+	"number":  true,
+	"integer": true,
+}
+
+// Compile parses a JSON Schema document. Keywords and types outside the
+// supported subset are rejected.
+func Compile(data []byte) (*Schema, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	if err := checkKeywords("", raw); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var schema Schema
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	if err := schema.checkTypes(""); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return &schema, nil
+}
+
+// MustCompile is like Compile but panics on error. For schemas built into the binary.
+func MustCompile(data []byte) *Schema {
+	schema, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// Validate checks a JSON document against the schema. The error names the
+// first offending field.
+func (s *Schema) Validate(document []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("malformed JSON: %w", err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("malformed JSON: unexpected data after the document")
+	}
+
+	return s.validate("", value)
+}
+
+func (s *Schema) validate(path string, value interface{}) error {
+	if s.Type != "" && !hasType(value, s.Type) {
+		return fieldError(path, "must be of type %s", s.Type)
+	}
+
+	if len(s.Enum) > 0 && !inEnum(value, s.Enum) {
+		allowed := make([]string, len(s.Enum))
+		for i, option := range s.Enum {
+			allowed[i] = fmt.Sprint(option)
+		}
+		return fieldError(path, "must be one of %s", strings.Join(allowed, ", "))
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			return fieldError(path, "must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return fieldError(path, "must be at most %d characters", *s.MaxLength)
+		}
+	case json.Number:
+		if s.Minimum != nil && compareNumbers(v, *s.Minimum) < 0 {
+			return fieldError(path, "must be at least %s", s.Minimum.String())
+		}
+		if s.Maximum != nil && compareNumbers(v, *s.Maximum) > 0 {
+			return fieldError(path, "must be at most %s", s.Maximum.String())
+		}
+	case map[string]interface{}:
+		return s.validateObject(path, v)
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) validateObject(path string, object map[string]interface{}) error {
+	if s.MaxProperties != nil && len(object) > *s.MaxProperties {
+		return fieldError(path, "must have at most %d properties", *s.MaxProperties)
+	}
+
+	for _, name := range s.Required {
+		if _, exists := object[name]; !exists {
+			return fieldError(join(path, name), "is required")
+		}
+	}
+
+	// Walk properties in a stable order so the reported field is deterministic
+	for _, name := range sortedKeys(object) {
+		property, known := s.Properties[name]
+		if !known {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				return fieldError(join(path, name), "is not allowed")
+			}
+			continue
+		}
+		if err := property.validate(join(path, name), object[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkKeywords rejects keywords outside the supported subset in a raw schema
+// and the schemas nested in its properties and items
+func checkKeywords(path string, raw interface{}) error {
+	object, ok := raw.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s must be an object", schemaPath(path))
+	}
+
+	for _, name := range sortedKeys(object) {
+		value := object[name]
+		if !keywords[name] {
+			return fmt.Errorf("unsupported keyword %q in %s", name, schemaPath(path))
+		}
+
+		switch name {
+		case "properties":
+			properties, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("properties in %s must be an object", schemaPath(path))
+			}
+			for _, property := range sortedKeys(properties) {
+				if err := checkKeywords(join(path, property), properties[property]); err != nil {
+					return err
+				}
+			}
+		case "items":
+			if err := checkKeywords(path+"[]", value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) checkTypes(path string) error {
+	if s.Type != "" && !types[s.Type] {
+		return fmt.Errorf("unsupported type %q in %s", s.Type, schemaPath(path))
+	}
+	for name, property := range s.Properties {
+		if err := property.checkTypes(join(path, name)); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.checkTypes(path + "[]")
+	}
+	return nil
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func schemaPath(path string) string {
+	if path == "" {
+		return "the root schema"
+	}
+	return "the schema of " + path
+}
+
+func hasType(value interface{}, schemaType string) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		r, ok := new(big.Rat).SetString(number.String())
+		return ok && r.IsInt()
+	}
+	return false
+}
+
+// inEnum reports whether a scalar value is one of the options. Objects and
+// arrays never match.
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, option := range enum {
+		switch v := value.(type) {
+		case json.Number:
+			if o, ok := option.(json.Number); ok && compareNumbers(v, o) == 0 {
+				return true
+			}
+		case string, bool, nil:
+			if value == option {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func compareNumbers(a, b json.Number) int {
+	x, okX := new(big.Rat).SetString(a.String())
+	y, okY := new(big.Rat).SetString(b.String())
+	if !okX || !okY {
+		return strings.Compare(a.String(), b.String())
+	}
+	return x.Cmp(y)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func fieldError(path, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+	if path == "" {
+		return fmt.Errorf("%s", message)
+	}
+	return fmt.Errorf("%s %s", path, message)
+}
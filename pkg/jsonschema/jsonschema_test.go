@@ -0,0 +1,146 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "Test document",
+	"type": "object",
+	"required": ["name", "address"],
+	"additionalProperties": false,
+	"maxProperties": 6,
+	"properties": {
+		"name": {"type": "string", "minLength": 2, "maxLength": 5},
+		"age": {"type": "integer", "minimum": 0, "maximum": 150},
+		"score": {"type": "number", "minimum": 0.5},
+		"active": {"type": "boolean"},
+		"channel": {"type": "string", "enum": ["web", "ios"]},
+		"address": {
+			"type": "object",
+			"required": ["city"],
+			"properties": {
+				"city": {"type": "string"},
+				"zip": {"type": "string", "maxLength": 5}
+			}
+		},
+		"tags": {
+			"type": "array",
+			"items": {"type": "string", "enum": ["new", "sale"]}
+		},
+		"lines": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"required": ["qty"],
+				"properties": {"qty": {"type": "integer", "minimum": 1}}
+			}
+		}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	schema := MustCompile([]byte(testSchema))
+
+	tests := []struct {
+		name     string
+		document string
+		wantErr  string
+	}{
+		{name: "valid", document: `{"name": "Ann", "address": {"city": "Jakarta"}}`},
+		{name: "valid with optional fields", document: `{"name": "Ann", "age": 30, "score": 0.5, "active": true, "channel": "ios", "address": {"city": "Jakarta", "zip": "12345"}}`},
+		{name: "valid arrays", document: `{"name": "Ann", "address": {"city": "Jakarta"}, "tags": ["new", "sale"], "lines": [{"qty": 1}, {"qty": 2}]}`},
+		{name: "empty arrays", document: `{"name": "Ann", "address": {"city": "Jakarta"}, "tags": [], "lines": []}`},
+
+		{name: "missing required", document: `{"address": {"city": "Jakarta"}}`, wantErr: "name is required"},
+		{name: "missing nested required", document: `{"name": "Ann", "address": {}}`, wantErr: "address.city is required"},
+		{name: "missing required in array item", document: `{"name": "Ann", "address": {"city": "Jakarta"}, "lines": [{"qty": 1}, {}]}`, wantErr: "lines[1].qty is required"},
+
+		{name: "root type", document: `[]`, wantErr: "must be of type object"},
+		{name: "string type", document: `{"name": 5, "address": {"city": "Jakarta"}}`, wantErr: "name must be of type string"},
+		{name: "integer type", document: `{"name": "Ann", "age": 30.5, "address": {"city": "Jakarta"}}`, wantErr: "age must be of type integer"},
+		{name: "integer written as decimal", document: `{"name": "Ann", "age": 30.0, "address": {"city": "Jakarta"}}`},
+		{name: "number type", document: `{"name": "Ann", "score": "high", "address": {"city": "Jakarta"}}`, wantErr: "score must be of type number"},
+		{name: "boolean type", document: `{"name": "Ann", "active": "yes", "address": {"city": "Jakarta"}}`, wantErr: "active must be of type boolean"},
+		{name: "null is not a string", document: `{"name": null, "address": {"city": "Jakarta"}}`, wantErr: "name must be of type string"},
+		{name: "object type", document: `{"name": "Ann", "address": "Jakarta"}`, wantErr: "address must be of type object"},
+		{name: "array type", document: `{"name": "Ann", "address": {"city": "Jakarta"}, "tags": "new"}`, wantErr: "tags must be of type array"},
+		{name: "array item type", document: `{"name": "Ann", "address": {"city": "Jakarta"}, "tags": ["new", 5]}`, wantErr: "tags[1] must be of type string"},
+		{name: "nested array item type", document: `{"name": "Ann", "address": {"city": "Jakarta"}, "lines": [{"qty": "one"}]}`, wantErr: "lines[0].qty must be of type integer"},
+
+		{name: "enum", document: `{"name": "Ann", "channel": "fax", "address": {"city": "Jakarta"}}`, wantErr: "channel must be one of web, ios"},
+		{name: "enum is case sensitive", document: `{"name": "Ann", "channel": "WEB", "address": {"city": "Jakarta"}}`, wantErr: "channel must be one of web, ios"},
+		{name: "enum in array item", document: `{"name": "Ann", "address": {"city": "Jakarta"}, "tags": ["old"]}`, wantErr: "tags[0] must be one of new, sale"},
+
+		{name: "min length", document: `{"name": "A", "address": {"city": "Jakarta"}}`, wantErr: "name must be at least 2 characters"},
+		{name: "max length", document: `{"name": "Annabel", "address": {"city": "Jakarta"}}`, wantErr: "name must be at most 5 characters"},
+		{name: "length counts characters", document: `{"name": "ÅÅÅÅÅ", "address": {"city": "Jakarta"}}`},
+		{name: "minimum", document: `{"name": "Ann", "age": -1, "address": {"city": "Jakarta"}}`, wantErr: "age must be at least 0"},
+		{name: "maximum", document: `{"name": "Ann", "age": 151, "address": {"city": "Jakarta"}}`, wantErr: "age must be at most 150"},
+		{name: "decimal minimum", document: `{"name": "Ann", "score": 0.49, "address": {"city": "Jakarta"}}`, wantErr: "score must be at least 0.5"},
+		{name: "nested array item minimum", document: `{"name": "Ann", "address": {"city": "Jakarta"}, "lines": [{"qty": 0}]}`, wantErr: "lines[0].qty must be at least 1"},
+		{name: "nested max length", document: `{"name": "Ann", "address": {"city": "Jakarta", "zip": "123456"}}`, wantErr: "address.zip must be at most 5 characters"},
+
+		{name: "additional property", document: `{"name": "Ann", "address": {"city": "Jakarta"}, "extra": 1}`, wantErr: "extra is not allowed"},
+		{name: "nested additional property allowed", document: `{"name": "Ann", "address": {"city": "Jakarta", "extra": 1}}`},
+		{name: "max properties", document: `{"name": "Ann", "age": 1, "score": 1, "active": true, "channel": "web", "tags": [], "address": {"city": "Jakarta"}}`, wantErr: "must have at most 6 properties"},
+
+		{name: "malformed", document: `{"name": `, wantErr: "malformed JSON: unexpected EOF"},
+		{name: "trailing data", document: `{"name": "Ann", "address": {"city": "Jakarta"}} {}`, wantErr: "malformed JSON: unexpected data after the document"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate([]byte(tt.document))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{name: "supported keywords", schema: testSchema},
+		{name: "empty schema", schema: `{}`},
+		{name: "unsupported root keyword", schema: `{"type": "object", "patternProperties": {}}`, wantErr: `unsupported keyword "patternProperties" in the root schema`},
+		{name: "unsupported property keyword", schema: `{"properties": {"name": {"type": "string", "pattern": "^a"}}}`, wantErr: `unsupported keyword "pattern" in the schema of name`},
+		{name: "unsupported nested keyword", schema: `{"properties": {"address": {"properties": {"city": {"format": "city"}}}}}`, wantErr: `unsupported keyword "format" in the schema of address.city`},
+		{name: "unsupported items keyword", schema: `{"properties": {"tags": {"type": "array", "items": {"const": "new"}}}}`, wantErr: `unsupported keyword "const" in the schema of tags[]`},
+		{name: "unsupported array keyword", schema: `{"properties": {"tags": {"type": "array", "minItems": 1}}}`, wantErr: `unsupported keyword "minItems" in the schema of tags`},
+		{name: "composition keyword", schema: `{"oneOf": [{"type": "string"}, {"type": "integer"}]}`, wantErr: `unsupported keyword "oneOf" in the root schema`},
+		{name: "unsupported type", schema: `{"properties": {"age": {"type": "int"}}}`, wantErr: `unsupported type "int" in the schema of age`},
+		{name: "type list", schema: `{"type": ["string", "null"]}`, wantErr: "failed to parse schema"},
+		{name: "additional properties schema", schema: `{"additionalProperties": {"type": "string"}}`, wantErr: "failed to parse schema"},
+		{name: "schema is not an object", schema: `[]`, wantErr: "the root schema must be an object"},
+		{name: "property schema is not an object", schema: `{"properties": {"name": true}}`, wantErr: "the schema of name must be an object"},
+		{name: "malformed", schema: `{"type": `, wantErr: "failed to parse schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Compile() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Compile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/Christyan39/test-eDot/pkg/config"
+)
+
+// minorUnitExponents lists currencies whose minor unit is not 1/100 (ISO 4217)
+var minorUnitExponents = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"BHD": 3,
+	"JOD": 3,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+}
+
+// DefaultCurrency returns the currency assumed for amounts that do not state one
+func DefaultCurrency() string {
+	return strings.ToUpper(config.GetEnv("DEFAULT_CURRENCY", "USD"))
+}
+
+// Exponent returns the number of decimal places of the currency's minor unit
+func Exponent(currency string) int {
+	if exp, ok := minorUnitExponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+// Money is a fixed-point amount in the minor units of a currency, e.g. cents.
+//
+// Amounts are never stored as floats. Whenever a value has more precision than
+// the currency's minor unit (parsing, multiplying by a rate) it is rounded half
+// away from zero, so 0.125 USD becomes 0.13 USD and -0.125 USD becomes -0.13 USD.
+//
+// In JSON it is written as {"amount": "29.99", "currency": "USD"}. A plain
+// number or string such as 29.99 is also accepted and read in the default currency.
+type Money struct {
+	Amount   int64  // minor units
+	Currency string // ISO 4217 code
+}
+
+// New returns an amount of minor units in the currency
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: normalizeCurrency(currency)}
+}
+
+// Zero returns a zero amount in the currency
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// Parse reads a decimal amount such as "29.99" in the currency, rounding
+// half away from zero to the currency's minor unit
+func Parse(s, currency string) (Money, error) {
+	s = strings.TrimSpace(s)
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || s == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	currency = normalizeCurrency(currency)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(Exponent(currency))), nil)
+	r.Mul(r, new(big.Rat).SetInt(scale))
+
+	minor := roundHalfAwayFromZero(r)
+	if !minor.IsInt64() {
+		return Money{}, fmt.Errorf("amount %q is out of range", s)
+	}
+
+	return Money{Amount: minor.Int64(), Currency: currency}, nil
+}
+
+// MustParse is like Parse but panics on invalid input. It is meant for constants.
+func MustParse(s, currency string) Money {
+	m, err := Parse(s, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Add returns m + o. Both amounts must be in the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, fmt.Errorf("currency mismatch: %s and %s", m.currency(), o.currency())
+	}
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, fmt.Errorf("amount %s plus %s is out of range", m, o)
+	}
+	return Money{Amount: sum, Currency: m.currency()}, nil
+}
+
+// Sub returns m - o. Both amounts must be in the same currency.
+func (m Money) Sub(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, fmt.Errorf("currency mismatch: %s and %s", m.currency(), o.currency())
+	}
+	diff := m.Amount - o.Amount
+	if (o.Amount > 0 && diff > m.Amount) || (o.Amount < 0 && diff < m.Amount) {
+		return Money{}, fmt.Errorf("amount %s minus %s is out of range", m, o)
+	}
+	return Money{Amount: diff, Currency: m.currency()}, nil
+}
+
+// Mul returns m multiplied by a whole quantity
+func (m Money) Mul(quantity int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(quantity))
+	if !product.IsInt64() {
+		return Money{}, fmt.Errorf("amount %s multiplied by %d is out of range", m, quantity)
+	}
+	return Money{Amount: product.Int64(), Currency: m.currency()}, nil
+}
+
+// MulRat returns m multiplied by a rational factor, such as a percentage or a
+// tax rate, rounded half away from zero to the minor unit
+func (m Money) MulRat(factor *big.Rat) (Money, error) {
+	r := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), factor)
+	minor := roundHalfAwayFromZero(r)
+	if !minor.IsInt64() {
+		return Money{}, fmt.Errorf("amount %s multiplied by %s is out of range", m, factor.RatString())
+	}
+	return Money{Amount: minor.Int64(), Currency: m.currency()}, nil
+}
+
+// Cmp compares two amounts in the same currency, returning -1, 0 or +1
+func (m Money) Cmp(o Money) (int, error) {
+	if !m.SameCurrency(o) {
+		return 0, fmt.Errorf("currency mismatch: %s and %s", m.currency(), o.currency())
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Equal reports whether both amounts and currencies are equal
+func (m Money) Equal(o Money) bool {
+	return m.Amount == o.Amount && m.SameCurrency(o)
+}
+
+// SameCurrency reports whether both amounts are in the same currency
+func (m Money) SameCurrency(o Money) bool {
+	return m.currency() == o.currency()
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// IsNegative reports whether the amount is less than zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Decimal formats the amount in major units, e.g. "29.99"
+func (m Money) Decimal() string {
+	exp := Exponent(m.currency())
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// String formats the amount with its currency, e.g. "29.99 USD"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.currency()
+}
+
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// MarshalJSON writes the amount as {"amount": "29.99", "currency": "USD"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{
+		Amount:   m.Decimal(),
+		Currency: m.currency(),
+	})
+}
+
+// UnmarshalJSON reads either the object form or a plain decimal number or string
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '{' {
+		var raw moneyJSON
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("invalid money: %w", err)
+		}
+		currency := raw.Currency
+		if currency == "" {
+			currency = m.Currency
+		}
+		return m.parseJSONAmount(raw.Amount, currency)
+	}
+
+	return m.parseJSONAmount(data, m.Currency)
+}
+
+// ParseJSON reads an amount in either JSON form. An amount that does not state
+// its currency is read in the given currency, with that currency's precision.
+func ParseJSON(data []byte, currency string) (Money, error) {
+	m := Money{Currency: currency}
+	if err := m.UnmarshalJSON(data); err != nil {
+		return Money{}, err
+	}
+	return New(m.Amount, m.Currency), nil
+}
+
+func (m *Money) parseJSONAmount(data []byte, currency string) error {
+	amount := string(bytes.TrimSpace(data))
+	if unquoted, err := strconv.Unquote(amount); err == nil {
+		amount = unquoted
+	}
+
+	parsed, err := Parse(amount, currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// UnmarshalParam reads a decimal amount from a query or path parameter
+func (m *Money) UnmarshalParam(param string) error {
+	parsed, err := Parse(param, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount in a DECIMAL column
+func (m Money) Value() (driver.Value, error) {
+	return m.Decimal(), nil
+}
+
+// Scan reads the amount from a DECIMAL column. The currency is kept if already
+// set on the destination, otherwise the default currency is assumed.
+func (m *Money) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*m = Zero(m.Currency)
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("cannot scan %T into money", src)
+	}
+
+	parsed, err := Parse(s, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) currency() string {
+	return normalizeCurrency(m.Currency)
+}
+
+func normalizeCurrency(currency string) string {
+	if currency == "" {
+		return DefaultCurrency()
+	}
+	return strings.ToUpper(currency)
+}
+
+// roundHalfAwayFromZero rounds a rational number to the nearest integer,
+// rounding halves away from zero
+func roundHalfAwayFromZero(r *big.Rat) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	return quo
+}
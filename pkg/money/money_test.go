@@ -0,0 +1,287 @@
+package money
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		currency string
+		want     Money
+		wantErr  string
+	}{
+		{name: "two decimals", input: "29.99", currency: "USD", want: Money{Amount: 2999, Currency: "USD"}},
+		{name: "whole amount", input: "5", currency: "USD", want: Money{Amount: 500, Currency: "USD"}},
+		{name: "surrounding spaces", input: " 1.5 ", currency: "USD", want: Money{Amount: 150, Currency: "USD"}},
+		{name: "lowercase currency", input: "1", currency: "eur", want: Money{Amount: 100, Currency: "EUR"}},
+		{name: "negative", input: "-12.34", currency: "USD", want: Money{Amount: -1234, Currency: "USD"}},
+		{name: "rounds half up", input: "0.125", currency: "USD", want: Money{Amount: 13, Currency: "USD"}},
+		{name: "rounds negative half down", input: "-0.125", currency: "USD", want: Money{Amount: -13, Currency: "USD"}},
+		{name: "rounds below half", input: "0.124", currency: "USD", want: Money{Amount: 12, Currency: "USD"}},
+		{name: "zero exponent", input: "1500", currency: "JPY", want: Money{Amount: 1500, Currency: "JPY"}},
+		{name: "zero exponent rounds", input: "1500.5", currency: "JPY", want: Money{Amount: 1501, Currency: "JPY"}},
+		{name: "three exponent", input: "1.2345", currency: "BHD", want: Money{Amount: 1235, Currency: "BHD"}},
+		{name: "empty", input: "", currency: "USD", wantErr: "invalid amount"},
+		{name: "not a number", input: "abc", currency: "USD", wantErr: "invalid amount"},
+		{name: "out of range", input: "100000000000000000000", currency: "USD", wantErr: "out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input, tt.currency)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse(%q) error = %v, want %q", tt.input, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{name: "two decimals", money: New(2999, "USD"), want: "29.99 USD"},
+		{name: "zero", money: New(0, "USD"), want: "0.00 USD"},
+		{name: "below one", money: New(5, "USD"), want: "0.05 USD"},
+		{name: "negative", money: New(-1234, "USD"), want: "-12.34 USD"},
+		{name: "negative below one", money: New(-5, "USD"), want: "-0.05 USD"},
+		{name: "zero exponent", money: New(1500, "JPY"), want: "1500 JPY"},
+		{name: "three exponent", money: New(1235, "BHD"), want: "1.235 BHD"},
+		{name: "three exponent below one", money: New(7, "KWD"), want: "0.007 KWD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		input    string
+		currency string
+	}{
+		{input: "29.99", currency: "USD"},
+		{input: "-0.01", currency: "USD"},
+		{input: "1500", currency: "JPY"},
+		{input: "1.235", currency: "BHD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input+" "+tt.currency, func(t *testing.T) {
+			m, err := Parse(tt.input, tt.currency)
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.input, err)
+			}
+			if got := m.Decimal(); got != tt.input {
+				t.Errorf("Decimal() = %q, want %q", got, tt.input)
+			}
+		})
+	}
+}
+
+func TestMulRat(t *testing.T) {
+	tests := []struct {
+		name    string
+		money   Money
+		factor  string
+		want    int64
+		wantErr string
+	}{
+		{name: "exact", money: New(1000, "USD"), factor: "1/2", want: 500},
+		{name: "rounds half up", money: New(5, "USD"), factor: "1/2", want: 3},
+		{name: "rounds negative half down", money: New(-5, "USD"), factor: "1/2", want: -3},
+		{name: "rounds below half", money: New(1000, "USD"), factor: "1/3", want: 333},
+		{name: "rounds above half", money: New(1000, "USD"), factor: "2/3", want: 667},
+		{name: "percentage", money: New(1999, "USD"), factor: "15/100", want: 300},
+		{name: "zero factor", money: New(1999, "USD"), factor: "0", want: 0},
+		{name: "out of range", money: New(math.MaxInt64, "USD"), factor: "2", wantErr: "out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factor, ok := new(big.Rat).SetString(tt.factor)
+			if !ok {
+				t.Fatalf("invalid factor %q", tt.factor)
+			}
+
+			got, err := tt.money.MulRat(factor)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("MulRat(%s) error = %v, want %q", tt.factor, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MulRat(%s) unexpected error: %v", tt.factor, err)
+			}
+			if got.Amount != tt.want || got.Currency != tt.money.Currency {
+				t.Errorf("MulRat(%s) = %+v, want %d %s", tt.factor, got, tt.want, tt.money.Currency)
+			}
+		})
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		name     string
+		money    Money
+		quantity int64
+		want     int64
+		wantErr  string
+	}{
+		{name: "quantity", money: New(2999, "USD"), quantity: 3, want: 8997},
+		{name: "zero quantity", money: New(2999, "USD"), quantity: 0, want: 0},
+		{name: "negative quantity", money: New(2999, "USD"), quantity: -2, want: -5998},
+		{name: "largest amount", money: New(math.MaxInt64, "USD"), quantity: 1, want: math.MaxInt64},
+		{name: "overflow", money: New(math.MaxInt64/2+1, "USD"), quantity: 2, wantErr: "out of range"},
+		{name: "negative overflow", money: New(math.MinInt64, "USD"), quantity: -1, wantErr: "out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.money.Mul(tt.quantity)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Mul(%d) error = %v, want %q", tt.quantity, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Mul(%d) unexpected error: %v", tt.quantity, err)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("Mul(%d) = %d, want %d", tt.quantity, got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		want    int64
+		wantErr string
+	}{
+		{name: "sum", a: New(1050, "USD"), b: New(250, "USD"), want: 1300},
+		{name: "negative operand", a: New(1050, "USD"), b: New(-2000, "USD"), want: -950},
+		{name: "largest sum", a: New(math.MaxInt64-1, "USD"), b: New(1, "USD"), want: math.MaxInt64},
+		{name: "overflow", a: New(math.MaxInt64, "USD"), b: New(1, "USD"), wantErr: "out of range"},
+		{name: "negative overflow", a: New(math.MinInt64, "USD"), b: New(-1, "USD"), wantErr: "out of range"},
+		{name: "currency mismatch", a: New(100, "USD"), b: New(100, "EUR"), wantErr: "currency mismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Add(tt.b)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Add() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Add() unexpected error: %v", err)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("Add() = %d, want %d", got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestSub(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		want    int64
+		wantErr string
+	}{
+		{name: "difference", a: New(1050, "USD"), b: New(250, "USD"), want: 800},
+		{name: "below zero", a: New(250, "USD"), b: New(1050, "USD"), want: -800},
+		{name: "overflow", a: New(math.MaxInt64, "USD"), b: New(-1, "USD"), wantErr: "out of range"},
+		{name: "negative overflow", a: New(math.MinInt64, "USD"), b: New(1, "USD"), wantErr: "out of range"},
+		{name: "currency mismatch", a: New(100, "USD"), b: New(100, "JPY"), wantErr: "currency mismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Sub(tt.b)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Sub() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Sub() unexpected error: %v", err)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("Sub() = %d, want %d", got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		money   Money
+		to      string
+		rate    string
+		want    Money
+		wantErr string
+	}{
+		{name: "same exponent", money: New(1000, "USD"), to: "EUR", rate: "0.9", want: Money{Amount: 900, Currency: "EUR"}},
+		{name: "to zero exponent", money: New(1000, "USD"), to: "JPY", rate: "150.25", want: Money{Amount: 1503, Currency: "JPY"}},
+		{name: "from zero exponent", money: New(1500, "JPY"), to: "USD", rate: "0.0066", want: Money{Amount: 990, Currency: "USD"}},
+		{name: "from zero exponent rounds", money: New(1, "JPY"), to: "USD", rate: "0.0065", want: Money{Amount: 1, Currency: "USD"}},
+		{name: "to three exponent", money: New(1000, "USD"), to: "BHD", rate: "0.376", want: Money{Amount: 3760, Currency: "BHD"}},
+		{name: "from three exponent", money: New(1235, "BHD"), to: "USD", rate: "2.65", want: Money{Amount: 327, Currency: "USD"}},
+		{name: "negative rounds away from zero", money: New(-1, "JPY"), to: "USD", rate: "0.0065", want: Money{Amount: -1, Currency: "USD"}},
+		{name: "out of range", money: New(math.MaxInt64, "USD"), to: "JPY", rate: "150", wantErr: "cannot convert"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, ok := new(big.Rat).SetString(tt.rate)
+			if !ok {
+				t.Fatalf("invalid rate %q", tt.rate)
+			}
+
+			got, err := Convert(tt.money, tt.to, rate)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Convert() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Convert() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Convert(%s, %s, %s) = %+v, want %+v", tt.money, tt.to, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
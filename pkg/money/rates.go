@@ -0,0 +1,186 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateScale is the number of decimal places exchange rates are rounded to.
+// Matches the precision of the exchange_rate column orders record.
+const rateScale = 12
+
+// ExchangeRates is the serialised form of a rate table: how many units of each
+// currency one unit of the base currency buys, e.g. {"base": "USD", "rates": {"EUR": "0.92"}}
+type ExchangeRates struct {
+	Base      string                 `json:"base"`
+	Rates     map[string]json.Number `json:"rates"`
+	UpdatedAt time.Time              `json:"updated_at,omitempty"`
+}
+
+// RateTable holds the current exchange rates. It is safe for concurrent use
+// and can be replaced at runtime.
+type RateTable struct {
+	mu        sync.RWMutex
+	base      string
+	rates     map[string]*big.Rat
+	updatedAt time.Time
+}
+
+// NewRateTable returns an empty rate table. Until rates are loaded only
+// conversions within the same currency are possible.
+func NewRateTable(base string) *RateTable {
+	base = normalizeCurrency(base)
+	return &RateTable{
+		base:  base,
+		rates: map[string]*big.Rat{base: big.NewRat(1, 1)},
+	}
+}
+
+// LoadFile replaces the rates with the contents of a JSON file in the ExchangeRates format
+func (t *RateTable) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read exchange rates file: %w", err)
+	}
+
+	var rates ExchangeRates
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return fmt.Errorf("invalid exchange rates file: %w", err)
+	}
+
+	return t.Replace(&rates)
+}
+
+// Replace validates and atomically swaps in a new set of rates
+func (t *RateTable) Replace(rates *ExchangeRates) error {
+	base := strings.ToUpper(strings.TrimSpace(rates.Base))
+	if !IsValidCurrency(base) {
+		return fmt.Errorf("invalid base currency %q", rates.Base)
+	}
+
+	parsed := map[string]*big.Rat{base: big.NewRat(1, 1)}
+	for currency, value := range rates.Rates {
+		code := strings.ToUpper(strings.TrimSpace(currency))
+		if !IsValidCurrency(code) {
+			return fmt.Errorf("invalid currency %q", currency)
+		}
+		rate, ok := new(big.Rat).SetString(value.String())
+		if !ok || rate.Sign() <= 0 {
+			return fmt.Errorf("invalid rate %q for %s: must be a positive number", value, code)
+		}
+		if code == base && rate.Cmp(big.NewRat(1, 1)) != 0 {
+			return fmt.Errorf("invalid rate %q for base currency %s: must be 1", value, code)
+		}
+		parsed[code] = rate
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.base = base
+	t.rates = parsed
+	t.updatedAt = time.Now()
+	return nil
+}
+
+// Snapshot returns a copy of the current rates
+func (t *RateTable) Snapshot() *ExchangeRates {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	currencies := make([]string, 0, len(t.rates))
+	for currency := range t.rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	rates := make(map[string]json.Number, len(currencies))
+	for _, currency := range currencies {
+		rates[currency] = json.Number(FormatRate(t.rates[currency]))
+	}
+
+	return &ExchangeRates{
+		Base:      t.base,
+		Rates:     rates,
+		UpdatedAt: t.updatedAt,
+	}
+}
+
+// Rate returns how many units of `to` one unit of `from` buys, rounded to
+// rateScale decimal places. Cross rates are derived through the base currency.
+func (t *RateTable) Rate(from, to string) (*big.Rat, error) {
+	from = normalizeCurrency(from)
+	to = normalizeCurrency(to)
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	fromRate, ok := t.rates[from]
+	if !ok {
+		return nil, fmt.Errorf("no exchange rate for %s", from)
+	}
+	toRate, ok := t.rates[to]
+	if !ok {
+		return nil, fmt.Errorf("no exchange rate for %s", to)
+	}
+
+	rate, _ := new(big.Rat).SetString(FormatRate(new(big.Rat).Quo(toRate, fromRate)))
+	return rate, nil
+}
+
+// Convert converts m into another currency at the given rate, rounding half
+// away from zero to the target currency's minor unit
+func Convert(m Money, to string, rate *big.Rat) (Money, error) {
+	to = normalizeCurrency(to)
+	shift := Exponent(to) - Exponent(m.currency())
+
+	factor := new(big.Rat).Set(rate)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil))
+	if shift > 0 {
+		factor.Mul(factor, scale)
+	} else if shift < 0 {
+		factor.Quo(factor, scale)
+	}
+
+	converted, err := m.MulRat(factor)
+	if err != nil {
+		return Money{}, fmt.Errorf("cannot convert %s to %s: %w", m, to, err)
+	}
+	converted.Currency = to
+	return converted, nil
+}
+
+// IsValidCurrency reports whether code looks like an ISO 4217 currency code
+func IsValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatRate formats an exchange rate as a decimal string without trailing zeros
+func FormatRate(rate *big.Rat) string {
+	s := rate.FloatString(rateScale)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}